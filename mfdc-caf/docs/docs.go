@@ -97,6 +97,91 @@ const docTemplate = `{
                 }
             }
         },
+        "/blacklist/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Export blacklist to CSV. The file can be loaded back by /blacklist/import",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Blacklist"
+                ],
+                "summary": "Export blacklist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "caf_team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "global or team",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reason category",
+                        "name": "reason_category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/blacklist/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Import blacklist from CSV with header: number (required), caf_team_id, scope, reason_category, expires_at, description. Numbers that are already blocked with the same scope are skipped",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blacklist"
+                ],
+                "summary": "Import blacklist",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerBlackListImport"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/blacklist/view": {
             "post": {
                 "security": [
@@ -240,6 +325,96 @@ const docTemplate = `{
                 }
             }
         },
+        "/notify/outbox": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Digests delivered to 'local' notify channels of this node, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "Local notify outbox",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "caf_team_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerNotifyOutbox"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Drop digests delivered to 'local' notify channels of this node",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notify"
+                ],
+                "summary": "Clear local notify outbox",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/numbers/{number}/explain": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Timeline of CAF decisions for number: loads, calls, filter verdicts, block/unblock with strategy thresholds, blacklist and sync",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Explain number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerNumberExplain"
+                        }
+                    }
+                }
+            }
+        },
         "/runmethod/stat": {
             "get": {
                 "security": [
@@ -271,6 +446,133 @@ const docTemplate = `{
                 }
             }
         },
+        "/sync/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List blacklist sync jobs for Webitel and BP, by default failed (dead) ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Sync jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "dead",
+                        "description": "Job state: pending, done, dead, superseded",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target: webitel, bp",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Number",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of jobs per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerSyncJobs"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/jobs/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return all failed (dead) sync jobs to the queue, optionally only for target. Dead jobs with newer job for the same target and number are marked superseded instead",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Retry dead sync jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Target: webitel, bp",
+                        "name": "target",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return failed or pending sync job to the queue with reset attempts. Job with newer job for the same target and number is marked superseded and not retried",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Retry sync job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/teams/add": {
             "post": {
                 "security": [
@@ -433,31 +735,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/webhooks/recheck/{number}": {
-            "get": {
+        "/teams/notify/test/{id}": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Webhook recheck number",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Send digest of the last team logs to all team notify channels. Logs are not marked as sent",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Webhooks"
+                    "Notify"
                 ],
-                "summary": "Webhook recheck number",
+                "summary": "Test team notification",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Number",
-                        "name": "number",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerNotifyTest"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/recheck/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Webhook recheck number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Webhook recheck number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -505,6 +841,24 @@ const docTemplate = `{
                         "name": "number",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Talk time in seconds (for success)",
+                        "name": "talk_sec",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who hung up the call (for success)",
+                        "name": "hangup_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "SIP code (for success)",
+                        "name": "sip_code",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -559,6 +913,71 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/{id}/members/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check members by team filter rules in batches and send accepted members to Webitel. Members are sent as JSON array, CSV body (text/csv) or CSV file. CSV header must contain number or destination column",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Members"
+                ],
+                "summary": "Bulk add members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Queue ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Communication type ID for CSV without type_id column (default 1)",
+                        "name": "type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "description": "Members",
+                        "name": "members",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Members"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerMemberBulk"
+                            }
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -605,6 +1024,9 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "expires_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -616,6 +1038,12 @@ const docTemplate = `{
                 },
                 "number": {
                     "type": "string"
+                },
+                "reason_category": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
                 }
             }
         },
@@ -628,83 +1056,183 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "expires_at": {
+                    "description": "Без него блокировка бессрочная",
+                    "type": "string",
+                    "example": "2025-01-31 00:00"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "number": {
                     "type": "string"
+                },
+                "reason_category": {
+                    "description": "complaint, fraud, do_not_call, invalid, strategy, other",
+                    "type": "string",
+                    "example": "complaint"
+                },
+                "scope": {
+                    "description": "global - для всех команд, team - только для caf_team_id",
+                    "type": "string",
+                    "example": "global"
                 }
             }
         },
-        "model.LogJsonResponse": {
+        "model.BlackListImport": {
             "type": "object",
             "properties": {
-                "count": {
+                "added": {
                     "type": "integer"
                 },
-                "data": {},
-                "status": {
-                    "type": "string"
+                "exists": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BlackListImportRow"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "model.LogRequest": {
+        "model.BlackListImportRow": {
             "type": "object",
             "properties": {
-                "caf_team_id": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "from_date": {
-                    "type": "string"
+                "line": {
+                    "description": "Номер строки в файле",
+                    "type": "integer"
                 },
                 "number": {
-                    "description": "Номер телефона",
                     "type": "string"
                 },
-                "to_date": {
-                    "type": "string"
+                "status": {
+                    "description": "added, exists, error",
+                    "type": "string",
+                    "example": "added"
                 }
             }
         },
-        "model.Reload": {
+        "model.Communication": {
             "type": "object",
             "properties": {
-                "reload": {
+                "destination": {
                     "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/model.CommunicationType"
                 }
             }
         },
-        "model.SwaggerDefaultResponse": {
+        "model.CommunicationType": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "id": {
+                    "$ref": "#/definitions/model.IntString"
                 },
-                "status": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "model.SwaggerTeamsList": {
+        "model.ExplainEvent": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Team"
-                    }
+                "action": {
+                    "description": "reject, log, delay, block, unblock",
+                    "type": "string"
                 },
-                "status": {
+                "at": {
+                    "type": "string"
+                },
+                "caf_team_id": {
+                    "type": "integer"
+                },
+                "description": {
                     "type": "string"
+                },
+                "details": {
+                    "$ref": "#/definitions/model.LogDetails"
+                },
+                "rule": {
+                    "description": "Код правила или стратегии",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "first_load, last_load, call, filter, block, unblock, log, blacklist, sync",
+                    "type": "string",
+                    "example": "filter"
                 }
             }
         },
-        "model.Team": {
+        "model.ExplainNumber": {
             "type": "object",
             "properties": {
-                "active": {
+                "attempts_counter": {
+                    "type": "integer"
+                },
+                "block_rechecked": {
+                    "type": "boolean"
+                },
+                "blocked": {
+                    "type": "boolean"
+                },
+                "blocked_at": {
+                    "type": "string"
+                },
+                "caf_team_id": {
+                    "type": "integer"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "first_load_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_load_at": {
+                    "type": "string"
+                },
+                "load_counter": {
+                    "type": "integer"
+                },
+                "queue_id": {
+                    "type": "integer"
+                },
+                "repeated_check": {
+                    "type": "boolean"
+                },
+                "stat_waiting": {
                     "type": "boolean"
                 },
+                "stop_expirid": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "today_success_call": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.ExplainTeam": {
+            "type": "object",
+            "properties": {
                 "analize_attempt_count": {
                     "type": "integer"
                 },
@@ -717,26 +1245,673 @@ const docTemplate = `{
                 "caf_team_id": {
                     "type": "integer"
                 },
-                "email": {
-                    "type": "string"
-                },
                 "filtration": {
                     "type": "boolean"
                 },
                 "name": {
                     "type": "string"
                 },
+                "rules": {
+                    "description": "Действующие правила, с учётом стандартного набора и filtration",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FilterRule"
+                    }
+                },
                 "stop_days": {
                     "type": "integer"
                 },
                 "strategy": {
                     "type": "string"
+                }
+            }
+        },
+        "model.FilterRule": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "reject, log, delay",
+                    "type": "string",
+                    "example": "reject"
                 },
-                "webitel_queues_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "code": {
+                    "description": "blacklist, client_id, today_success, success_window, duplicate",
+                    "type": "string",
+                    "example": "blacklist"
+                },
+                "params": {
+                    "$ref": "#/definitions/model.FilterRuleParams"
+                }
+            }
+        },
+        "model.FilterRuleParams": {
+            "type": "object",
+            "properties": {
+                "delay_minutes": {
+                    "description": "Отсрочка звонка для действия delay",
+                    "type": "integer"
+                },
+                "max_successes": {
+                    "description": "Сколько успешных звонков за окно допускается",
+                    "type": "integer"
+                },
+                "min_talk_sec": {
+                    "description": "Звонок считается успешным от этой длительности разговора",
+                    "type": "integer"
+                },
+                "window_days": {
+                    "description": "Окно в днях, для success_window без него - текущая неделя",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.IntString": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LogDetails": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "model.LogJsonResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {},
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LogRequest": {
+            "type": "object",
+            "properties": {
+                "caf_team_id": {
+                    "type": "integer"
+                },
+                "from_date": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "Номер телефона",
+                    "type": "string"
+                },
+                "to_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Logs": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "reject, log, delay, block, unblock",
+                    "type": "string"
+                },
+                "caf_team_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Параметры решения: стратегия, пороги, SIP код",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.LogDetails"
+                        }
+                    ]
+                },
+                "filtered": {
+                    "type": "boolean"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Код сработавшего правила",
+                    "type": "string"
+                },
+                "sent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.MemberBulkResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "filtered": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MemberBulkResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MemberBulkResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Действие правила",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Порядковый номер мембера в запросе",
+                    "type": "integer"
+                },
+                "member_id": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Сработавшее правило фильтрации",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "accepted, filtered, error",
+                    "type": "string",
+                    "example": "accepted"
+                }
+            }
+        },
+        "model.Members": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "communications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Communication"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "min_offering_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "$ref": "#/definitions/model.Queue"
+                },
+                "timezone": {
+                    "$ref": "#/definitions/model.Timezone"
+                },
+                "variables": {
+                    "description": "динамические переменные",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.NotifyChannel": {
+            "type": "object",
+            "properties": {
+                "bot_token": {
+                    "description": "telegram: токен бота, в ответах скрыт маской. Маска в запросе сохраняет прежнее значение",
+                    "type": "string"
+                },
+                "chat_id": {
+                    "description": "telegram: чат для сообщений",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Без поля канал включён",
+                    "type": "boolean"
+                },
+                "headers": {
+                    "description": "webhook: дополнительные заголовки, в ответах значения скрыты маской",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Необязательное имя канала для логов",
+                    "type": "string",
+                    "example": "qa"
+                },
+                "to": {
+                    "description": "smtp: адреса, без них - email команды",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "smtp, webhook, telegram, local",
+                    "type": "string",
+                    "example": "telegram"
+                },
+                "url": {
+                    "description": "webhook: адрес для JSON POST",
+                    "type": "string"
+                }
+            }
+        },
+        "model.NotifyMessage": {
+            "type": "object",
+            "properties": {
+                "caf_team_id": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "description": "Имя или тип канала, заполняется для local",
+                    "type": "string"
+                },
+                "from_date": {
+                    "type": "string"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Logs"
+                    }
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "to_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NotifyResult": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "success, failed",
+                    "type": "string"
+                }
+            }
+        },
+        "model.NotifyTemplates": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "description": "Письмо smtp",
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "Тема письма",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "Сообщение telegram, webhook и local",
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumberExplain": {
+            "type": "object",
+            "properties": {
+                "blacklist": {
+                    "description": "Записи номера в ЧС",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BlackList"
+                    }
+                },
+                "number": {
+                    "type": "string"
+                },
+                "numbers": {
+                    "description": "Записи номера в caf.numbers",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExplainNumber"
+                    }
+                },
+                "reasons": {
+                    "description": "SIP статистика, ещё не обработанная стратегией cause",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReasonsStat"
+                    }
+                },
+                "teams": {
+                    "description": "Команды, которые встречаются в истории",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExplainTeam"
+                    }
+                },
+                "timeline": {
+                    "description": "События по времени",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExplainEvent"
+                    }
+                }
+            }
+        },
+        "model.Queue": {
+            "type": "object"
+        },
+        "model.ReasonsStat": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "num_id": {
+                    "type": "integer"
+                },
+                "sip_code": {
+                    "type": "string"
+                },
+                "sip_reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Reload": {
+            "type": "object",
+            "properties": {
+                "reload": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerBlackListImport": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.BlackListImport"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerDefaultResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerMemberBulk": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.MemberBulkResponse"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerNotifyOutbox": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NotifyMessage"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerNotifyTest": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NotifyResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerNumberExplain": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.NumberExplain"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerSyncJobs": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SyncJob"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerTeamsList": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Team"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SyncJob": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "add, remove",
+                    "type": "string",
+                    "example": "add"
+                },
+                "state": {
+                    "description": "pending, done, dead, superseded",
+                    "type": "string",
+                    "example": "pending"
+                },
+                "target": {
+                    "description": "webitel, bp",
+                    "type": "string",
+                    "example": "webitel"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Team": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "analize_attempt_count": {
+                    "type": "integer"
+                },
+                "bad_sip_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "caf_team_id": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "filtration": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notify_channels": {
+                    "description": "Каналы уведомлений, без них - письмо на email",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NotifyChannel"
+                    }
+                },
+                "notify_interval_min": {
+                    "description": "Период сводки в минутах, по умолчанию 60",
+                    "type": "integer"
+                },
+                "notify_templates": {
+                    "description": "Шаблоны сводки",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.NotifyTemplates"
+                        }
+                    ]
+                },
+                "rules": {
+                    "description": "Правила фильтрации по порядку, без них - стандартный набор. При filtration=false reject пишется в лог",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FilterRule"
+                    }
+                },
+                "stop_days": {
+                    "type": "integer"
+                },
+                "strategy": {
+                    "type": "string"
+                },
+                "success_hangup_by": {
+                    "description": "Кто может завершить успешный звонок",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "success_min_talk_sec": {
+                    "description": "Успешный звонок от этой длительности, по умолчанию 7 сек",
+                    "type": "integer"
+                },
+                "success_sip_codes": {
+                    "description": "SIP коды успешного звонка",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "webitel_queues_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "model.Timezone": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "$ref": "#/definitions/model.IntString"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         }
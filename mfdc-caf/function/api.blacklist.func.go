@@ -3,9 +3,12 @@ package function
 import (
 	"caf/model"
 	"database/sql"
+	"encoding/csv"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -91,6 +94,18 @@ func GetBlacklist(db *sqlx.DB, c *gin.Context) {
 		paramIndex++
 	}
 
+	if blockedRequest.Scope != nil && *blockedRequest.Scope != "" {
+		addCondition(&query, "scope", paramIndex, &args, *blockedRequest.Scope)
+		addCondition(&query_count, "scope", paramIndex, &args_count, *blockedRequest.Scope)
+		paramIndex++
+	}
+
+	if blockedRequest.Category != nil && *blockedRequest.Category != "" {
+		addCondition(&query, "reason_category", paramIndex, &args, *blockedRequest.Category)
+		addCondition(&query_count, "reason_category", paramIndex, &args_count, *blockedRequest.Category)
+		paramIndex++
+	}
+
 	// Добавляем лимит и смещение
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", paramIndex, paramIndex+1)
 	args = append(args, limit, offset)
@@ -142,18 +157,23 @@ func BLDelete(db *sqlx.DB, c *gin.Context) {
 	id := c.Param("id")
 	CheckIDAsInt(id, c)
 
-	var rowNumber string
-	err := db.QueryRow("SELECT number FROM caf.blacklist WHERE id = $1", id).Scan(&rowNumber)
+	// Удаляем только указанную запись, остальные записи номера остаются
+	var row struct {
+		Number string `db:"number"`
+		TeamID *int   `db:"team_id"`
+	}
+	err := db.Get(&row, "DELETE FROM caf.blacklist WHERE id = $1 RETURNING number, team_id", id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Row not found for deletion from blacklist"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to check row on exists", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to delete row from blacklist", "error": err.Error()})
 		return
 	}
-	// Разблокируем номер
-	err = BlockNumberActions(db, false, nil, nil, rowNumber, nil, nil, nil)
+	// Разблокируем номер, если других действующих записей нет
+	verdict := blockVerdict(ruleManual, false, "Удалён из ЧС", model.LogDetails{"blacklist_id": id})
+	_, err = releaseBlacklistNumber(db, row.Number, nil, row.TeamID, verdict)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to unblock number actions", "error": err.Error()})
 		return
@@ -189,6 +209,14 @@ func AddBL(db *sqlx.DB, c *gin.Context) {
 		return
 	}
 
+	scope, category, err := checkBlacklistParams(request.Scope, request.TeamID, request.Category)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid blacklist params", "error": err.Error()})
+		return
+	}
+	request.Scope = &scope
+	request.Category = &category
+
 	// Загружаем временную зону из конфигурации
 	location, err := time.LoadLocation(config.API.TimeZone)
 	if err != nil {
@@ -197,6 +225,49 @@ func AddBL(db *sqlx.DB, c *gin.Context) {
 	}
 	currentDate := time.Now().In(location)
 
+	var expiresAt *time.Time
+	if request.ExpiresAt != nil {
+		expiresAt, err = parseBlacklistExpiry(*request.ExpiresAt, location)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid expires_at", "error": err.Error()})
+			return
+		}
+	}
+
+	description := blacklistDescription(c, request.Description)
+
+	var blockedID *int64
+	addQuery := `INSERT INTO caf.blacklist (created_at, number, team_id, description, expires_at, scope, reason_category) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	err = db.QueryRow(addQuery, currentDate, request.Number, request.TeamID, description, expiresAt, scope, category).Scan(&blockedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to insert new row to blacklist", "error": err.Error()})
+		return
+	}
+
+	if request.Number != nil {
+		// В ЧС Webitel номер отправит очередь синхронизации. ЧС Webitel общий для всех команд,
+		// поэтому туда уходят только глобальные записи, командные действуют только в фильтре CAF
+		if scope == blacklistGlobal {
+			err = enqueueSync(db, syncWebitel, syncAdd, *request.Number, nil, &description)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed add to Webitel blacklist", "error": err.Error()})
+				return
+			}
+		}
+
+		request.ID = blockedID
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Number must be not empty"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Number successfully added to blacklist", "data": request})
+}
+
+// Описание записи ЧС с логином того, кто её добавил
+func blacklistDescription(c *gin.Context, description *string) string {
 	// Получение текущего логина из JWT
 	currentLogin, _ := c.Get("email")
 	var changeLogin string
@@ -206,35 +277,245 @@ func AddBL(db *sqlx.DB, c *gin.Context) {
 		changeLogin = "User"
 	}
 
-	var description string
-	if request.Description != nil {
-		description = "(" + changeLogin + ")" + " " + *request.Description
-	} else {
-		description = "(" + changeLogin + ")"
+	if description != nil && *description != "" {
+		return "(" + changeLogin + ")" + " " + *description
 	}
+	return "(" + changeLogin + ")"
+}
 
-	var blockedID *int64
-	addQuery := `INSERT INTO caf.blacklist (created_at, number, team_id, description) 
-				VALUES ($1, $2, $3, $4) RETURNING id`
+// Export blacklist godoc
+// @Summary      Export blacklist
+// @Description  Export blacklist to CSV. The file can be loaded back by /blacklist/import
+// @Tags         Blacklist
+// @Produce      text/csv
+// @Param caf_team_id query int false "Team ID"
+// @Param scope query string false "global or team"
+// @Param reason_category query string false "Reason category"
+// @Success      200  {file}   file
+// @Router       /blacklist/export [get]
+// @Security ApiKeyAuth
+func BLExport(db *sqlx.DB, c *gin.Context) {
+	query := `SELECT id, created_at, number, team_id, description, expires_at, scope, reason_category FROM caf.blacklist WHERE 1=1`
+	var args []interface{}
+	paramIndex := 1
+
+	if teamIDS := c.Query("caf_team_id"); teamIDS != "" {
+		teamID, err := strconv.Atoi(teamIDS)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+			return
+		}
+		addCondition(&query, "team_id", paramIndex, &args, teamID)
+		paramIndex++
+	}
+	if scope := c.Query("scope"); scope != "" {
+		addCondition(&query, "scope", paramIndex, &args, scope)
+		paramIndex++
+	}
+	if category := c.Query("reason_category"); category != "" {
+		addCondition(&query, "reason_category", paramIndex, &args, category)
+	}
+	query += " ORDER BY created_at"
 
-	err = db.QueryRow(addQuery, currentDate, request.Number, request.TeamID, description).Scan(&blockedID)
+	var blackList []model.BlackList
+	err := db.Select(&blackList, query, args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to insert new row to blacklist", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get blacklist", "error": err.Error()})
 		return
 	}
 
-	if request.Number != nil {
-		err = SendNumberToWebitel(*request.Number, description)
+	location, err := time.LoadLocation(config.API.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to load timezone", "error": err.Error()})
+		return
+	}
+
+	// Форматирование необязательных полей для CSV
+	formatString := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	formatTime := func(value *time.Time) string {
+		if value == nil {
+			return ""
+		}
+		return value.In(location).Format("2006-01-02 15:04:05")
+	}
+
+	filename := fmt.Sprintf("blacklist-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"number", "caf_team_id", "scope", "reason_category", "expires_at", "description", "created_at"})
+	for _, row := range blackList {
+		teamID := ""
+		if row.TeamID != nil {
+			teamID = strconv.Itoa(*row.TeamID)
+		}
+		writer.Write([]string{formatString(row.Number), teamID, formatString(row.Scope), formatString(row.Category),
+			formatTime(row.ExpiresAt), formatString(row.Description), formatTime(row.CreatedAt)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		ErrLog.Printf("Failed to write blacklist export: %s", err)
+	}
+}
+
+// Import blacklist godoc
+// @Summary      Import blacklist
+// @Description  Import blacklist from CSV with header: number (required), caf_team_id, scope, reason_category, expires_at, description. Numbers that are already blocked with the same scope are skipped
+// @Tags         Blacklist
+// @Accept       multipart/form-data
+// @Accept       text/csv
+// @Produce      json
+// @Param file formData file false "CSV file"
+// @Success      200  {array}   model.SwaggerBlackListImport
+// @Router       /blacklist/import [post]
+// @Security ApiKeyAuth
+func BLImport(db *sqlx.DB, c *gin.Context) {
+	var src io.Reader
+	if file, err := c.FormFile("file"); err == nil {
+		fileSrc, err := file.Open()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed add to Webitel blacklist", "error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Unable to open file", "error": err.Error()})
 			return
 		}
-
-		request.ID = blockedID
+		defer fileSrc.Close()
+		src = fileSrc
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Number must be not empty"})
+		src = c.Request.Body
+	}
+
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1 // Количество колонок в строках может отличаться
+	records, err := reader.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to read CSV", "error": err.Error()})
+		return
+	}
+	if len(records) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "CSV must contain header and at least one row"})
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Number successfully added to blacklist", "data": request})
+	columns := map[string]int{}
+	for idx, column := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(column))] = idx
+	}
+	if _, exists := columns["number"]; !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "CSV header must contain 'number' column"})
+		return
+	}
+
+	// Значение колонки строки, пустая строка если колонки нет
+	value := func(record []string, column string) string {
+		idx, exists := columns[column]
+		if !exists || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	location, err := time.LoadLocation(config.API.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to load timezone", "error": err.Error()})
+		return
+	}
+	currentDate := time.Now().In(location)
+
+	result := model.BlackListImport{
+		Total: len(records) - 1,
+		Rows:  make([]model.BlackListImportRow, len(records)-1),
+	}
+	for idx, record := range records[1:] {
+		row := &result.Rows[idx]
+		row.Line = idx + 2
+		row.Number = value(record, "number")
+
+		rowErr := func(err error) {
+			message := err.Error()
+			row.Status = "error"
+			row.Error = &message
+		}
+
+		if row.Number == "" {
+			rowErr(fmt.Errorf("number is empty"))
+			continue
+		}
+
+		var teamID *int
+		if teamValue := value(record, "caf_team_id"); teamValue != "" {
+			id, err := strconv.Atoi(teamValue)
+			if err != nil {
+				rowErr(fmt.Errorf("invalid caf_team_id '%s'", teamValue))
+				continue
+			}
+			teamID = &id
+		}
+
+		scopeValue := value(record, "scope")
+		categoryValue := value(record, "reason_category")
+		scope, category, err := checkBlacklistParams(&scopeValue, teamID, &categoryValue)
+		if err != nil {
+			rowErr(err)
+			continue
+		}
+
+		expiresAt, err := parseBlacklistExpiry(value(record, "expires_at"), location)
+		if err != nil {
+			rowErr(err)
+			continue
+		}
+
+		// Пропускаем номер, если он уже заблокирован с той же областью действия
+		var exists bool
+		err = db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM caf.blacklist WHERE number = $1 AND scope = $2
+			AND ($2 = $3 OR team_id = $4) AND (expires_at IS NULL OR expires_at > now()))`, row.Number, scope, blacklistGlobal, teamID)
+		if err != nil {
+			rowErr(fmt.Errorf("failed to check number in blacklist: %w", err))
+			continue
+		}
+		if exists {
+			row.Status = "exists"
+			result.Exists++
+			continue
+		}
+
+		descriptionValue := value(record, "description")
+		description := blacklistDescription(c, &descriptionValue)
+
+		err = db.QueryRow(`INSERT INTO caf.blacklist (created_at, number, team_id, description, expires_at, scope, reason_category) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, currentDate, row.Number, teamID, description, expiresAt, scope, category).Scan(&row.ID)
+		if err != nil {
+			rowErr(fmt.Errorf("failed to insert row to blacklist: %w", err))
+			continue
+		}
+
+		row.Status = "added"
+
+		// В общий ЧС Webitel отправляем только глобальные записи, как и в /blacklist/add
+		if scope != blacklistGlobal {
+			continue
+		}
+		err = enqueueSync(db, syncWebitel, syncAdd, row.Number, nil, &description)
+		if err != nil {
+			message := "Failed add to Webitel blacklist: " + err.Error()
+			row.Error = &message
+		}
+	}
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case "added":
+			result.Added++
+		case "error":
+			result.Failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": result})
 }
@@ -0,0 +1,117 @@
+package function
+
+import (
+	"caf/model"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// Notify test godoc
+// @Summary      Test team notification
+// @Description  Send digest of the last team logs to all team notify channels. Logs are not marked as sent
+// @Tags         Notify
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Success      200  {object}   model.SwaggerNotifyTest
+// @Router       /teams/notify/test/{id} [post]
+// @Security ApiKeyAuth
+func NotifyTest(db *sqlx.DB, c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "ID must be a number", "error": err.Error()})
+		return
+	}
+
+	var team model.TeamDB
+	err = db.Get(&team, "SELECT * FROM caf.teams WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Team not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get team", "error": err.Error()})
+		return
+	}
+
+	loc, err := time.LoadLocation(config.API.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to load timezone", "error": err.Error()})
+		return
+	}
+
+	toDate := time.Now().In(loc)
+	fromDate := toDate.Add(-teamNotifyInterval(team))
+
+	// Последние записи команды за период сводки, отправленные или нет
+	logs := []model.Logs{}
+	err = db.Select(&logs, "SELECT * FROM caf.logs WHERE team_id = $1 AND filtered = $2 AND created_at BETWEEN $3 AND $4 ORDER BY created_at DESC LIMIT 10",
+		id, false, fromDate, toDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get logs", "error": err.Error()})
+		return
+	}
+	for i := range logs {
+		if logs[i].CreatedAt != nil {
+			createdAt := logs[i].CreatedAt.In(loc)
+			logs[i].CreatedAt = &createdAt
+		}
+	}
+
+	message, err := teamNotifyMessage(team, logs, fromDate, toDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to build notification", "error": err.Error()})
+		return
+	}
+
+	results := sendTeamNotify(team, message)
+	if results == nil {
+		results = []model.NotifyResult{}
+	}
+
+	c.IndentedJSON(http.StatusOK, model.SwaggerNotifyTest{Status: "success", Data: results})
+}
+
+// Notify outbox godoc
+// @Summary      Local notify outbox
+// @Description  Digests delivered to 'local' notify channels of this node, newest first
+// @Tags         Notify
+// @Produce      json
+// @Param caf_team_id query int false "Team ID"
+// @Success      200  {object}   model.SwaggerNotifyOutbox
+// @Router       /notify/outbox [get]
+// @Security ApiKeyAuth
+func GetNotifyOutbox(c *gin.Context) {
+	teamID, _ := strconv.Atoi(c.Query("caf_team_id"))
+
+	notifyOutboxMu.Lock()
+	messages := []model.NotifyMessage{}
+	for idx := len(notifyOutbox) - 1; idx >= 0; idx-- {
+		if teamID == 0 || notifyOutbox[idx].TeamID == teamID {
+			messages = append(messages, notifyOutbox[idx])
+		}
+	}
+	notifyOutboxMu.Unlock()
+
+	c.IndentedJSON(http.StatusOK, model.SwaggerNotifyOutbox{Status: "success", Data: messages})
+}
+
+// Notify outbox clear godoc
+// @Summary      Clear local notify outbox
+// @Description  Drop digests delivered to 'local' notify channels of this node
+// @Tags         Notify
+// @Produce      json
+// @Success      200  {object}   model.SwaggerDefaultResponse
+// @Router       /notify/outbox [delete]
+// @Security ApiKeyAuth
+func ClearNotifyOutbox(c *gin.Context) {
+	notifyOutboxMu.Lock()
+	notifyOutbox = nil
+	notifyOutboxMu.Unlock()
+
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Notify outbox cleared"})
+}
@@ -0,0 +1,224 @@
+package function
+
+import (
+	"caf/model"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// Тип события по действию из лога
+func explainLogType(action *string) string {
+	if action == nil {
+		return "log"
+	}
+	switch *action {
+	case actionBlock, actionUnblock:
+		return *action
+	case actionReject, actionLog, actionDelay:
+		return "filter"
+	}
+	return "log"
+}
+
+// Сборка истории номера: загрузки, звонки, решения фильтров, блокировки, ЧС и синхронизация
+func explainNumber(db *sqlx.DB, number string, loc *time.Location) (*model.NumberExplain, error) {
+	explain := model.NumberExplain{
+		Number:    number,
+		Numbers:   []model.ExplainNumber{},
+		Teams:     []model.ExplainTeam{},
+		Blacklist: []model.BlackList{},
+		Reasons:   []model.ReasonsStat{},
+		Timeline:  []model.ExplainEvent{},
+	}
+	teamIDs := map[int]bool{}
+
+	addEvent := func(at *time.Time, event model.ExplainEvent) {
+		if at == nil {
+			return
+		}
+		event.At = at.In(loc)
+		if event.TeamID != nil && *event.TeamID != 0 {
+			teamIDs[*event.TeamID] = true
+		}
+		explain.Timeline = append(explain.Timeline, event)
+	}
+
+	// Загрузки номера
+	err := db.Select(&explain.Numbers, `SELECT id, client_id, queue_id, team_id, first_load_at, last_load_at, load_counter, attempts_counter, success,
+			today_success_call, blocked, blocked_at, stop_expirid, stat_waiting, repeated_check, block_rechecked
+		FROM caf.numbers WHERE number = $1 ORDER BY id`, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number: %w", err)
+	}
+	for _, row := range explain.Numbers {
+		addEvent(row.FirstLoadAt, model.ExplainEvent{Type: "first_load", TeamID: row.TeamID, Details: model.LogDetails{
+			"queue_id": row.QueueID,
+		}})
+		if row.LastLoadAt != nil && (row.FirstLoadAt == nil || !row.LastLoadAt.Equal(*row.FirstLoadAt)) {
+			addEvent(row.LastLoadAt, model.ExplainEvent{Type: "last_load", TeamID: row.TeamID, Details: model.LogDetails{
+				"queue_id":         row.QueueID,
+				"load_counter":     row.LoadCounter,
+				"attempts_counter": row.AttemptsCounter,
+			}})
+		}
+	}
+
+	// Звонки из вебхуков
+	var calls []model.Call
+	err = db.Select(&calls, "SELECT * FROM caf.calls WHERE number = $1 ORDER BY created_at", number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calls: %w", err)
+	}
+	for _, call := range calls {
+		addEvent(call.CreatedAt, model.ExplainEvent{Type: "call", TeamID: call.TeamID, Details: model.LogDetails{
+			"success":   call.Success,
+			"talk_sec":  call.TalkSec,
+			"hangup_by": call.HangupBy,
+			"sip_code":  call.SipCode,
+		}})
+	}
+
+	// SIP статистика, которую ещё не обработала стратегия cause
+	err = db.Select(&explain.Reasons, `SELECT r.id, r.num_id, COALESCE(r.count, 0) AS count, COALESCE(r.sip_code, '') AS sip_code, COALESCE(r.sip_reason, '') AS sip_reason
+		FROM caf.num_reasons AS r INNER JOIN caf.numbers AS n ON r.num_id = n.id
+		WHERE n.number = $1 ORDER BY r.id`, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number stat: %w", err)
+	}
+
+	// Решения фильтров и блокировки
+	var logs []model.Logs
+	err = db.Select(&logs, "SELECT * FROM caf.logs WHERE number = $1 ORDER BY created_at", number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+	for _, log := range logs {
+		addEvent(log.CreatedAt, model.ExplainEvent{
+			Type:        explainLogType(log.Action),
+			TeamID:      log.TeamID,
+			Rule:        log.Rule,
+			Action:      log.Action,
+			Description: log.Description,
+			Details:     log.Details,
+		})
+	}
+
+	// Записи ЧС
+	err = db.Select(&explain.Blacklist, "SELECT * FROM caf.blacklist WHERE number = $1 ORDER BY created_at", number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blacklist: %w", err)
+	}
+	for _, entry := range explain.Blacklist {
+		addEvent(entry.CreatedAt, model.ExplainEvent{Type: "blacklist", TeamID: entry.TeamID, Description: entry.Description, Details: model.LogDetails{
+			"blacklist_id":    entry.ID,
+			"scope":           entry.Scope,
+			"reason_category": entry.Category,
+			"expires_at":      entry.ExpiresAt,
+		}})
+	}
+
+	// Синхронизация ЧС с Webitel и БП
+	var jobs []model.SyncJob
+	err = db.Select(&jobs, "SELECT * FROM caf.sync_jobs WHERE number = $1 ORDER BY id", number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync jobs: %w", err)
+	}
+	for _, job := range jobs {
+		operation := job.Operation
+		createdAt := job.CreatedAt
+		addEvent(&createdAt, model.ExplainEvent{Type: "sync", Action: &operation, Details: model.LogDetails{
+			"target":     job.Target,
+			"state":      job.State,
+			"attempts":   job.Attempts,
+			"last_error": job.LastError,
+		}})
+	}
+
+	if len(explain.Timeline) == 0 && len(explain.Numbers) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(explain.Timeline, func(i, j int) bool {
+		return explain.Timeline[i].At.Before(explain.Timeline[j].At)
+	})
+
+	// Текущие настройки команд из истории
+	for _, row := range explain.Numbers {
+		if row.TeamID != nil && *row.TeamID != 0 {
+			teamIDs[*row.TeamID] = true
+		}
+	}
+	ids := make([]int, 0, len(teamIDs))
+	for id := range teamIDs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var teams []model.TeamDB
+	err = db.Select(&teams, "SELECT * FROM caf.teams WHERE id = ANY($1) ORDER BY id", IntArr2PgIntArr(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+	for _, team := range teams {
+		explainTeam := model.ExplainTeam{
+			ID:                  *team.ID,
+			Name:                team.Name,
+			Strategy:            team.Strategy,
+			Filtration:          team.Filtration,
+			StopDays:            team.StopDays,
+			AnalizeAttemptCount: team.AnalizeAttemptCount,
+		}
+		if team.BadSipCodes != nil {
+			explainTeam.BadSipCodes = PgIntArr2IntArr(*team.BadSipCodes)
+		}
+		var rules model.FilterRules
+		if team.Rules != nil {
+			rules = *team.Rules
+		}
+		explainTeam.Rules = effectiveFilterRules(rules, team.Filtration != nil && *team.Filtration)
+		explain.Teams = append(explain.Teams, explainTeam)
+	}
+
+	return &explain, nil
+}
+
+// Number explain godoc
+// @Summary      Explain number
+// @Description  Timeline of CAF decisions for number: loads, calls, filter verdicts, block/unblock with strategy thresholds, blacklist and sync
+// @Tags         Numbers
+// @Produce      json
+// @Param        number   path      string  true  "Number"
+// @Success      200  {object}   model.SwaggerNumberExplain
+// @Router       /numbers/{number}/explain [get]
+// @Security ApiKeyAuth
+func NumberExplain(db *sqlx.DB, c *gin.Context) {
+	number := strings.TrimSpace(c.Param("number"))
+	if number == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Number is required"})
+		return
+	}
+
+	loc, err := time.LoadLocation(config.API.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to load timezone", "error": err.Error()})
+		return
+	}
+
+	explain, err := explainNumber(db, number, loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to explain number", "error": err.Error()})
+		return
+	}
+	if explain == nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Number not found"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, model.SwaggerNumberExplain{Status: "success", Data: *explain})
+}
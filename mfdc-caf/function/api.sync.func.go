@@ -0,0 +1,171 @@
+package function
+
+import (
+	"caf/model"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// List sync jobs godoc
+// @Summary      Sync jobs
+// @Description  List blacklist sync jobs for Webitel and BP, by default failed (dead) ones
+// @Tags         Sync
+// @Produce      json
+// @Param state query string false "Job state: pending, done, dead, superseded" default(dead)
+// @Param target query string false "Target: webitel, bp"
+// @Param number query string false "Number"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of jobs per page" default(100)
+// @Success      200  {object}   model.SwaggerSyncJobs
+// @Router       /sync/jobs [get]
+// @Security ApiKeyAuth
+func GetSyncJobs(db *sqlx.DB, c *gin.Context) {
+
+	// Получаем параметры пагинации из запроса
+	page := 1
+	limit := 100
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	offset := (page - 1) * limit
+
+	state := c.DefaultQuery("state", syncDead)
+	if state != syncPending && state != syncDone && state != syncDead && state != syncSuperseded {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": fmt.Sprintf("Unknown state '%s', available: pending, done, dead, superseded", state)})
+		return
+	}
+
+	query := `SELECT * FROM caf.sync_jobs WHERE state = $1`
+	query_count := `SELECT COUNT(id) FROM caf.sync_jobs WHERE state = $1`
+	args := []interface{}{state}
+	args_count := []interface{}{state}
+	paramIndex := 2 // Индекс для параметров
+
+	if target := c.Query("target"); target != "" {
+		addCondition(&query, "target", paramIndex, &args, target)
+		addCondition(&query_count, "target", paramIndex, &args_count, target)
+		paramIndex++
+	}
+
+	if number := c.Query("number"); number != "" {
+		addCondition(&query, "number", paramIndex, &args, number)
+		addCondition(&query_count, "number", paramIndex, &args_count, number)
+		paramIndex++
+	}
+
+	// Добавляем лимит и смещение
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", paramIndex, paramIndex+1)
+	args = append(args, limit, offset)
+
+	jobs := []model.SyncJob{}
+	err := db.Select(&jobs, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get sync jobs", "error": err.Error()})
+		return
+	}
+
+	var rows_count int
+	err = db.Get(&rows_count, query_count, args_count...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get count sync jobs", "error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, model.SwaggerSyncJobs{Status: "success", Count: rows_count, Data: jobs})
+}
+
+// Retry sync job godoc
+// @Summary      Retry sync job
+// @Description  Return failed or pending sync job to the queue with reset attempts. Job with newer job for the same target and number is marked superseded and not retried
+// @Tags         Sync
+// @Produce      json
+// @Param        id   path      int  true  "Job ID"
+// @Success      200  {object}   model.SwaggerDefaultResponse
+// @Router       /sync/jobs/{id}/retry [post]
+// @Security ApiKeyAuth
+func RetrySyncJob(db *sqlx.DB, c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "ID must be a number", "error": err.Error()})
+		return
+	}
+
+	// Если по номеру уже есть более новое задание, старое не повторяем, иначе оно перезапишет более новое изменение
+	result, err := db.Exec(`UPDATE caf.sync_jobs AS j SET state = $1, updated_at = $2
+		WHERE id = $3 AND state NOT IN ($4, $1) AND `+syncNewerJobSQL, syncSuperseded, time.Now(), id, syncDone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to check newer sync jobs", "error": err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected != 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "failed", "message": "Sync job superseded by newer job for the same number"})
+		return
+	}
+
+	// Выполненные и вытесненные задания не повторяем
+	result, err = db.Exec(`UPDATE caf.sync_jobs SET state = $1, attempts = 0, next_run_at = $2, updated_at = $2
+		WHERE id = $3 AND state NOT IN ($4, $5)`, syncPending, time.Now(), id, syncDone, syncSuperseded)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to retry sync job", "error": err.Error()})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Sync job not found, already done or superseded"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Sync job queued for retry"})
+}
+
+// Retry dead sync jobs godoc
+// @Summary      Retry dead sync jobs
+// @Description  Return all failed (dead) sync jobs to the queue, optionally only for target. Dead jobs with newer job for the same target and number are marked superseded instead
+// @Tags         Sync
+// @Produce      json
+// @Param target query string false "Target: webitel, bp"
+// @Success      200  {object}   model.SwaggerDefaultResponse
+// @Router       /sync/jobs/retry [post]
+// @Security ApiKeyAuth
+func RetryDeadSyncJobs(db *sqlx.DB, c *gin.Context) {
+	target := c.Query("target")
+
+	// Сначала вытесняем задания, по номеру которых есть более новые
+	supersedeQuery := `UPDATE caf.sync_jobs AS j SET state = $1, updated_at = $2 WHERE state = $3 AND ` + syncNewerJobSQL
+	supersedeArgs := []interface{}{syncSuperseded, time.Now(), syncDead}
+	if target != "" {
+		addCondition(&supersedeQuery, "target", 4, &supersedeArgs, target)
+	}
+
+	result, err := db.Exec(supersedeQuery, supersedeArgs...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to supersede sync jobs", "error": err.Error()})
+		return
+	}
+	superseded, _ := result.RowsAffected()
+
+	query := `UPDATE caf.sync_jobs SET state = $1, attempts = 0, next_run_at = $2, updated_at = $2 WHERE state = $3`
+	args := []interface{}{syncPending, time.Now(), syncDead}
+
+	if target != "" {
+		addCondition(&query, "target", 4, &args, target)
+	}
+
+	result, err = db.Exec(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to retry sync jobs", "error": err.Error()})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("Sync jobs queued for retry: %d, superseded: %d", rowsAffected, superseded)})
+}
@@ -50,6 +50,20 @@ func TeamsList(db *sqlx.DB, c *gin.Context) {
 			}
 			*teams[idx].BadSipCodes = PgIntArr2IntArr(*team.BadSipCodes)
 		}
+		teams[idx].Rules = team.Rules
+		teams[idx].SuccessMinTalkSec = team.SuccessMinTalkSec
+		if team.SuccessHangupBy != nil {
+			teams[idx].SuccessHangupBy = new([]string)
+			*teams[idx].SuccessHangupBy = PgTextArr2StrArr(*team.SuccessHangupBy)
+		}
+		if team.SuccessSipCodes != nil {
+			teams[idx].SuccessSipCodes = new([]int)
+			*teams[idx].SuccessSipCodes = PgIntArr2IntArr(*team.SuccessSipCodes)
+		}
+		// Секреты каналов наружу не отдаём
+		teams[idx].NotifyChannels = maskNotifyChannels(team.NotifyChannels)
+		teams[idx].NotifyIntervalMin = team.NotifyIntervalMin
+		teams[idx].NotifyTemplates = team.NotifyTemplates
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "data": teams})
@@ -95,9 +109,32 @@ func AddTeam(db *sqlx.DB, c *gin.Context) {
 		}
 	}
 
+	if request.Rules != nil {
+		if err := validateFilterRules(*request.Rules); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid rules", "error": err.Error()})
+			return
+		}
+	}
+
+	if request.SuccessMinTalkSec != nil && *request.SuccessMinTalkSec < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Field 'success_min_talk_sec' can't be negative"})
+		return
+	}
+
+	// Маска допустима только при изменении команды
+	if err := restoreNotifySecrets(request.NotifyChannels, nil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid notification settings", "error": err.Error()})
+		return
+	}
+	if err := checkTeamNotify(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid notification settings", "error": err.Error()})
+		return
+	}
+
 	var teamID int
-	addQuery := `INSERT INTO caf.teams (name, active, filtration, email, stop_days, analize_attempt_count, strategy, webitel_queues_ids, bad_sip_codes) 
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	addQuery := `INSERT INTO caf.teams (name, active, filtration, email, stop_days, analize_attempt_count, strategy, webitel_queues_ids, bad_sip_codes, rules,
+				success_min_talk_sec, success_hangup_by, success_sip_codes, notify_channels, notify_interval_min, notify_templates) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id`
 
 	var webitelQueuesIds pgtype.Int4Array
 	var badSipCodes pgtype.Int4Array
@@ -111,8 +148,17 @@ func AddTeam(db *sqlx.DB, c *gin.Context) {
 	} else {
 		badSipCodes = pgtype.Int4Array{Status: pgtype.Null}
 	}
+	successHangupBy := pgtype.TextArray{Status: pgtype.Null}
+	if request.SuccessHangupBy != nil {
+		successHangupBy = StrArr2PgTextArr(*request.SuccessHangupBy)
+	}
+	successSipCodes := pgtype.Int4Array{Status: pgtype.Null}
+	if request.SuccessSipCodes != nil {
+		successSipCodes = IntArr2PgIntArr(*request.SuccessSipCodes)
+	}
 
-	err := db.QueryRow(addQuery, request.Name, request.Active, request.Filtration, request.EMail, request.StopDays, request.AnalizeAttemptCount, request.Strategy, webitelQueuesIds, badSipCodes).Scan(&teamID)
+	err := db.QueryRow(addQuery, request.Name, request.Active, request.Filtration, request.EMail, request.StopDays, request.AnalizeAttemptCount, request.Strategy, webitelQueuesIds, badSipCodes, request.Rules, request.SuccessMinTalkSec, successHangupBy, successSipCodes,
+		request.NotifyChannels, request.NotifyIntervalMin, request.NotifyTemplates).Scan(&teamID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to insert new team", "error": err.Error()})
 		return
@@ -177,6 +223,61 @@ func TeamEdit(db *sqlx.DB, c *gin.Context) {
 	if request.Filtration == nil {
 		request.Filtration = teamDB.Filtration
 	}
+	// Пустой список правил возвращает команду к стандартному набору
+	if request.Rules == nil {
+		request.Rules = teamDB.Rules
+	} else if err := validateFilterRules(*request.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid rules", "error": err.Error()})
+		return
+	} else if len(*request.Rules) == 0 {
+		request.Rules = nil
+	}
+	if request.SuccessMinTalkSec == nil {
+		request.SuccessMinTalkSec = teamDB.SuccessMinTalkSec
+	} else if *request.SuccessMinTalkSec < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Field 'success_min_talk_sec' can't be negative"})
+		return
+	}
+
+	// Маска из ответа списка команд означает, что секрет не меняется
+	if err := restoreNotifySecrets(request.NotifyChannels, teamDB.NotifyChannels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid notification settings", "error": err.Error()})
+		return
+	}
+	if err := checkTeamNotify(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid notification settings", "error": err.Error()})
+		return
+	}
+	// Пустой список каналов возвращает команду к письму на email
+	if request.NotifyChannels == nil {
+		request.NotifyChannels = teamDB.NotifyChannels
+	} else if len(*request.NotifyChannels) == 0 {
+		request.NotifyChannels = nil
+	}
+	if request.NotifyIntervalMin == nil {
+		request.NotifyIntervalMin = teamDB.NotifyIntervalMin
+	}
+	if request.NotifyTemplates == nil {
+		request.NotifyTemplates = teamDB.NotifyTemplates
+	}
+
+	// Пустые списки критериев успешного звонка снимают ограничение
+	successHangupBy := pgtype.TextArray{Status: pgtype.Null}
+	if request.SuccessHangupBy == nil {
+		if teamDB.SuccessHangupBy != nil {
+			successHangupBy = *teamDB.SuccessHangupBy
+		}
+	} else {
+		successHangupBy = StrArr2PgTextArr(*request.SuccessHangupBy)
+	}
+	successSipCodes := pgtype.Int4Array{Status: pgtype.Null}
+	if request.SuccessSipCodes == nil {
+		if teamDB.SuccessSipCodes != nil {
+			successSipCodes = *teamDB.SuccessSipCodes
+		}
+	} else {
+		successSipCodes = IntArr2PgIntArr(*request.SuccessSipCodes)
+	}
 
 	var WebitelQueuesIDS pgtype.Int4Array
 	if request.WebitelQueuesIDS == nil {
@@ -209,10 +310,18 @@ func TeamEdit(db *sqlx.DB, c *gin.Context) {
 				strategy = $6,
 				filtration = $7,
 				webitel_queues_ids = $8,
-				bad_sip_codes = $9
+				bad_sip_codes = $9,
+				rules = $11,
+				success_min_talk_sec = $12,
+				success_hangup_by = $13,
+				success_sip_codes = $14,
+				notify_channels = $15,
+				notify_interval_min = $16,
+				notify_templates = $17
 				WHERE id = $10`
 
-	_, err = db.Exec(updateQuery, request.Name, request.Active, request.EMail, request.StopDays, request.AnalizeAttemptCount, request.Strategy, request.Filtration, WebitelQueuesIDS, BadSipCodes, id)
+	_, err = db.Exec(updateQuery, request.Name, request.Active, request.EMail, request.StopDays, request.AnalizeAttemptCount, request.Strategy, request.Filtration, WebitelQueuesIDS, BadSipCodes, id, request.Rules, request.SuccessMinTalkSec, successHangupBy, successSipCodes,
+		request.NotifyChannels, request.NotifyIntervalMin, request.NotifyTemplates)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to update team", "error": err.Error()})
 		return
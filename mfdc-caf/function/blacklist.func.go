@@ -0,0 +1,156 @@
+package function
+
+import (
+	"caf/model"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Область действия записи ЧС
+const (
+	blacklistGlobal = "global" // Для всех команд
+	blacklistTeam   = "team"   // Только для команды team_id
+)
+
+// Категории причин блокировки
+var blacklistCategories = []string{"complaint", "fraud", "do_not_call", "invalid", "strategy", "other"}
+
+const (
+	blacklistCategoryStrategy = "strategy" // Блокировка по стратегии команды
+	blacklistCategoryDefault  = "other"
+)
+
+// Форматы срока блокировки, время без зоны считается во временной зоне сервиса
+var blacklistTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// Разбор срока блокировки
+func parseBlacklistExpiry(value string, location *time.Location) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if expiresAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return &expiresAt, nil
+	}
+	for _, layout := range blacklistTimeLayouts {
+		if expiresAt, err := time.ParseInLocation(layout, value, location); err == nil {
+			return &expiresAt, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid expires_at '%s', expected YYYY-MM-DD [HH:MM[:SS]] or RFC3339", value)
+}
+
+// Проверка области действия и категории, возвращает значения по умолчанию для пустых
+func checkBlacklistParams(scope *string, teamID *int, category *string) (string, string, error) {
+	resultScope := blacklistGlobal
+	if scope != nil && *scope != "" {
+		resultScope = *scope
+	}
+	switch resultScope {
+	case blacklistGlobal:
+	case blacklistTeam:
+		if teamID == nil || *teamID == 0 {
+			return "", "", fmt.Errorf("scope 'team' requires caf_team_id")
+		}
+	default:
+		return "", "", fmt.Errorf("unknown scope '%s', available: global, team", resultScope)
+	}
+
+	resultCategory := blacklistCategoryDefault
+	if category != nil && *category != "" {
+		resultCategory = *category
+	}
+	for _, known := range blacklistCategories {
+		if known == resultCategory {
+			return resultScope, resultCategory, nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown reason_category '%s', available: %s", resultCategory, strings.Join(blacklistCategories, ", "))
+}
+
+// Удаление номера из ЧС Webitel
+func RemoveNumberFromWebitel(number string) error {
+	if number == "" {
+		return fmt.Errorf("number cannot be empty")
+	}
+
+	// Ищем записи номера в списке
+	listURL := fmt.Sprintf("%s/call_center/list/%d/communication", config.API_Webitel.URL, config.API_Webitel.BlacklistID)
+	responseBody, statusCode, err := APIFetch(config.API_Webitel.Header, config.API_Webitel.Key, "GET", listURL+"?size=100&q="+url.QueryEscape(number), nil)
+	if err != nil {
+		return fmt.Errorf("failed to search number in Webitel blacklist: %w", err)
+	}
+	if statusCode > 299 || statusCode < 200 {
+		return fmt.Errorf("failed to search number in Webitel blacklist, status code: %d", statusCode)
+	}
+
+	var response struct {
+		Items []struct {
+			ID     model.IntString `json:"id"`
+			Number string          `json:"number"`
+		} `json:"items"`
+	}
+	err = json.Unmarshal(responseBody, &response)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal Webitel blacklist: %w", err)
+	}
+
+	// Поиск по q частичный, удаляем только точные совпадения
+	for _, item := range response.Items {
+		if item.Number != number {
+			continue
+		}
+		_, statusCode, err := APIFetch(config.API_Webitel.Header, config.API_Webitel.Key, "DELETE", listURL+"/"+item.ID.Value, nil)
+		if err != nil {
+			return fmt.Errorf("failed to delete number from Webitel blacklist: %w", err)
+		}
+		if statusCode > 299 || statusCode < 200 {
+			return fmt.Errorf("failed to delete number from Webitel blacklist, status code: %d", statusCode)
+		}
+	}
+
+	return nil
+}
+
+// Снятие блокировки с истёкших записей ЧС. Удаляются только истёкшие записи, номер разблокируется
+// и уходит из ЧС Webitel и БП, если действующих записей не осталось
+func LiftExpiredBlacklist(db *sqlx.DB) (int, error) {
+	var expired []struct {
+		Number   string  `db:"number"`
+		TeamID   *int    `db:"team_id"`
+		ClientID *string `db:"client_id"`
+	}
+	err := db.Select(&expired, `SELECT b.number, MIN(b.team_id) AS team_id,
+			(SELECT client_id FROM caf.numbers WHERE number = b.number AND client_id IS NOT NULL LIMIT 1) AS client_id
+		FROM caf.blacklist AS b
+		WHERE b.expires_at <= now()
+		GROUP BY b.number`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired blacklist: %w", err)
+	}
+
+	lifted := 0
+	for _, entry := range expired {
+		_, err := db.Exec("DELETE FROM caf.blacklist WHERE number = $1 AND expires_at <= now()", entry.Number)
+		if err != nil {
+			return lifted, fmt.Errorf("failed to delete expired blacklist for number %s: %w", entry.Number, err)
+		}
+
+		verdict := blockVerdict(ruleBlacklistExpired, false, "Истёк срок блокировки в ЧС", nil)
+		released, err := releaseBlacklistNumber(db, entry.Number, entry.ClientID, entry.TeamID, verdict)
+		if err != nil {
+			ErrLog.Printf("Failed to unblock expired number %s: %s", entry.Number, err)
+			continue
+		}
+		if released {
+			lifted++
+		}
+	}
+
+	return lifted, nil
+}
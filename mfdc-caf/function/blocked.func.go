@@ -12,78 +12,134 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-// Блокировка номера внутри сервиса
-func BlockNumberActions(db *sqlx.DB, blocked bool, blockedAt *time.Time, blockToDate *time.Time, number string, clientID *string, teamID *int, description *string) error {
+// Коды решений о блокировке в caf.logs
+const (
+	ruleStrategyUnsuccessful = "strategy_unsuccessful" // Не было успешных звонков за unsuccessfulWindowDays
+	ruleStrategyCause        = "strategy_cause"        // Плохой SIP код без успешного отбоя
+	ruleStopExpired          = "stop_expired"          // Истёк срок блокировки по стратегии cause
+	ruleBlacklistExpired     = "blacklist_expired"     // Истёк срок записи ЧС
+	ruleManual               = "manual"                // Через API ЧС
+
+	actionBlock   = "block"
+	actionUnblock = "unblock"
+)
+
+// Окно стратегии unsuccessful в днях
+const unsuccessfulWindowDays = 30
 
+// Решение о блокировке или разблокировке для лога
+func blockVerdict(rule string, blocked bool, message string, details model.LogDetails) *model.FilterVerdict {
+	action := actionUnblock
 	if blocked {
-		// Отправляем номер в ЧС
-		_, err := db.Exec("INSERT INTO caf.blacklist (number, created_at, team_id, description) VALUES ($1, $2, $3, $4)", number, blockedAt, teamID, description)
-		if err != nil {
-			return fmt.Errorf("failed to insert number: %w", err)
-		}
+		action = actionBlock
+	}
+	return &model.FilterVerdict{Rule: rule, Action: action, Message: message, Details: details}
+}
 
-		_, err = db.Exec("UPDATE caf.numbers SET blocked = $1, blocked_at = $2, stop_expirid = $3, stat_waiting = $4 WHERE number = $5", blocked, blockedAt, blockToDate, true, number)
-		if err != nil {
-			return fmt.Errorf("failed to update stop date for number: %w", err)
+// Блокировка номера внутри сервиса. Без решения действие записывается в лог как ручное
+func BlockNumberActions(db *sqlx.DB, blocked bool, blockedAt *time.Time, blockToDate *time.Time, number string, clientID *string, teamID *int, verdict *model.FilterVerdict) error {
+	if verdict == nil {
+		message := "Удалён из ЧС"
+		if blocked {
+			message = "Добавлен в ЧС"
 		}
+		verdict = blockVerdict(ruleManual, blocked, message, nil)
+	}
 
-		var descriptionLog string
-		if description != nil {
-			descriptionLog = *description
-		} else {
-			descriptionLog = "Добавлен в ЧС"
-		}
+	if blocked {
 		var TeamID int
 		if teamID != nil {
 			TeamID = *teamID
 		} else {
 			TeamID = 0
 		}
-		err = addLog(db, TeamID, number, descriptionLog, true)
+
+		// Отправляем номер в ЧС, запись снимется по сроку блокировки
+		_, err := db.Exec("INSERT INTO caf.blacklist (number, created_at, team_id, description, expires_at, reason_category) VALUES ($1, $2, $3, $4, $5, $6)", number, blockedAt, teamID, verdict.Message, blockToDate, blacklistCategoryStrategy)
+		if err != nil {
+			return fmt.Errorf("failed to insert number: %w", err)
+		}
+
+		_, err = db.Exec("UPDATE caf.numbers SET blocked = $1, blocked_at = $2, stop_expirid = $3, stat_waiting = $4 WHERE number = $5", blocked, blockedAt, blockToDate, true, number)
+		if err != nil {
+			return fmt.Errorf("failed to update stop date for number: %w", err)
+		}
+
+		err = addRuleLog(db, TeamID, number, verdict.Message, true, verdict)
 		if err != nil {
 			ErrLog.Printf("Failed save to log: %s", err)
 		}
 	} else {
-		_, err := db.Exec("DELETE FROM caf.blacklist WHERE number = $1", number)
+		// Снимаем только запись, созданную стратегией команды, ручные и чужие записи остаются
+		_, err := db.Exec("DELETE FROM caf.blacklist WHERE number = $1 AND reason_category = $2 AND team_id IS NOT DISTINCT FROM $3", number, blacklistCategoryStrategy, teamID)
 		if err != nil {
 			return fmt.Errorf("failed to unblock number from blacklist: %w", err)
 		}
-		_, err = db.Exec("UPDATE caf.numbers SET blocked = $1 WHERE number = $2", blocked, number)
+
+		_, err = releaseBlacklistNumber(db, number, clientID, teamID, verdict)
 		if err != nil {
-			return fmt.Errorf("failed to update stop date for number: %w", err)
+			return err
 		}
+	}
+	return nil
+}
 
-		// Удаляем номер из ЧС БП
-		if clientID != nil {
-			err = SendNumberToBP(true, number, clientID, false, false, false)
-			if err != nil {
-				return fmt.Errorf("failed to send number to BP: %s", err)
+// Разблокировка номера после удаления его записей из ЧС. Пока остаются действующие записи, номер остаётся заблокированным,
+// из ЧС Webitel и БП он удаляется, только когда не осталось действующих глобальных записей. Возвращает true, если номер разблокирован
+func releaseBlacklistNumber(db *sqlx.DB, number string, clientID *string, teamID *int, verdict *model.FilterVerdict) (bool, error) {
+	var active struct {
+		Any    bool `db:"any_active"`
+		Global bool `db:"global_active"`
+	}
+	err := db.Get(&active, `SELECT
+			EXISTS (SELECT 1 FROM caf.blacklist WHERE number = $1 AND (expires_at IS NULL OR expires_at > now())) AS any_active,
+			EXISTS (SELECT 1 FROM caf.blacklist WHERE number = $1 AND scope = $2 AND (expires_at IS NULL OR expires_at > now())) AS global_active`,
+		number, blacklistGlobal)
+	if err != nil {
+		return false, fmt.Errorf("failed to check active blacklist for number %s: %w", number, err)
+	}
+
+	// Удаляем номер из ЧС Webitel и БП через очередь синхронизации
+	if !active.Global {
+		if clientID == nil {
+			var numberClientID sql.NullString
+			err = db.Get(&numberClientID, "SELECT client_id FROM caf.numbers WHERE number = $1 AND client_id IS NOT NULL LIMIT 1", number)
+			if err != nil && err != sql.ErrNoRows {
+				return false, fmt.Errorf("failed to get client_id for number %s: %w", number, err)
 			}
-		} else {
-			err = SendNumberToBP(false, number, nil, false, false, false)
-			if err != nil {
-				return fmt.Errorf("failed to send number to BP: %s", err)
+			if numberClientID.Valid {
+				clientID = &numberClientID.String
 			}
 		}
 
-		var descriptionLog string
-		if description != nil {
-			descriptionLog = *description
-		} else {
-			descriptionLog = "Удалён из ЧС"
-		}
-		var TeamID int
-		if teamID != nil {
-			TeamID = *teamID
-		} else {
-			TeamID = 0
+		err = enqueueSync(db, syncWebitel, syncRemove, number, nil, nil)
+		if err != nil {
+			return false, err
 		}
-		err = addLog(db, TeamID, number, descriptionLog, false)
+		err = enqueueSync(db, syncBP, syncRemove, number, clientID, nil)
 		if err != nil {
-			ErrLog.Printf("Failed save to log: %s", err)
+			return false, err
 		}
 	}
-	return nil
+
+	if active.Any {
+		return false, nil
+	}
+
+	_, err = db.Exec("UPDATE caf.numbers SET blocked = $1 WHERE number = $2", false, number)
+	if err != nil {
+		return false, fmt.Errorf("failed to update stop date for number: %w", err)
+	}
+
+	TeamID := 0
+	if teamID != nil {
+		TeamID = *teamID
+	}
+	err = addRuleLog(db, TeamID, number, verdict.Message, false, verdict)
+	if err != nil {
+		ErrLog.Printf("Failed save to log: %s", err)
+	}
+	return true, nil
 }
 
 // Отправка номера в ЧС Webitel
@@ -102,13 +158,13 @@ func SendNumberToWebitel(number string, description string) error {
 	}
 
 	// Читаем тело ответа
-	_, statusCode, err := APIFetch(config.API_Webitel.Header, config.API_Webitel.Key, "POST", url, jsonRequest)
+	responseBody, statusCode, err := APIFetch(config.API_Webitel.Header, config.API_Webitel.Key, "POST", url, jsonRequest)
 	if err != nil {
-		fmt.Errorf("failed to read response body: %w, status code: %d", err, statusCode)
+		return fmt.Errorf("failed to read response body: %w, status code: %d", err, statusCode)
 	}
 
 	if statusCode > 299 || statusCode < 200 {
-		fmt.Errorf("failed add number to Webitel API: %s", err.Error())
+		return fmt.Errorf("failed add number to Webitel API, status code: %d, response: %s", statusCode, responseBody)
 	}
 
 	return nil
@@ -133,9 +189,9 @@ func SendNumberToBP(byClientID bool, number string, clientID *string, marketing
 	JRPCParams := []map[string]interface{}{ // params это массив
 		{
 			"options": map[string]interface{}{
-				"marketing": true,
-				"incoming":  true,
-				"outgoing":  true,
+				"marketing": marketing,
+				"incoming":  incoming,
+				"outgoing":  outgoing,
 			},
 			"phone": byKey,
 		},
@@ -179,7 +235,7 @@ func CheckNumberForBlockByUnsuccessful(db *sqlx.DB) error {
 	now := time.Now().In(location)
 
 	// Получаем 30 дней назад
-	minus30Days := now.AddDate(0, 0, -30)
+	minus30Days := now.AddDate(0, 0, -unsuccessfulWindowDays)
 
 	// Создаем переменную start, устанавливая время на 00:00:00.000 в указанной временной зоне
 	startTime := time.Date(minus30Days.Year(), minus30Days.Month(), minus30Days.Day(), 0, 0, 0, 0, location)
@@ -212,29 +268,23 @@ func CheckNumberForBlockByUnsuccessful(db *sqlx.DB) error {
 			}
 		} else if !number.Success && !number.StatWaiting { // Если у номера за 30 дней не было успешных вызовов, но были неуспешные то отмечаем как заблокированный
 			// Помечаем номер как заблокированный
-			description := "За 30 дней не было успешных"
-			err := BlockNumberActions(db, true, &currentDate, nil, number.Number, number.ClientID, number.TeamID, &description)
+			verdict := blockVerdict(ruleStrategyUnsuccessful, true, "За 30 дней не было успешных", model.LogDetails{
+				"strategy":    "unsuccessful",
+				"window_days": unsuccessfulWindowDays,
+			})
+			err := BlockNumberActions(db, true, &currentDate, nil, number.Number, number.ClientID, number.TeamID, verdict)
 			if err != nil {
 				return fmt.Errorf("failed to block number actions: %w", err)
 			}
 
-			// Отправляем номер в ЧС БП
-			if number.ClientID != nil {
-				err = SendNumberToBP(true, number.Number, number.ClientID, true, true, true)
-				if err != nil {
-					return fmt.Errorf("failed to send number to BP: %s", err)
-				}
-			} else {
-				err = SendNumberToBP(false, number.Number, nil, true, true, true)
-				if err != nil {
-					return fmt.Errorf("failed to send number to BP: %s", err)
-				}
+			// Отправляем номер в ЧС БП и Webitel через очередь синхронизации, блокировка не зависит от их доступности
+			err = enqueueSync(db, syncBP, syncAdd, number.Number, number.ClientID, nil)
+			if err != nil {
+				return err
 			}
-
-			// Отправляем номер в ЧС Webitel
-			err = SendNumberToWebitel(number.Number, description)
+			err = enqueueSync(db, syncWebitel, syncAdd, number.Number, nil, &verdict.Message)
 			if err != nil {
-				return fmt.Errorf("failed to send number to Webitel: %s", err)
+				return err
 			}
 		}
 	}
@@ -343,7 +393,11 @@ func CheckNumberForBlockByCause(db *sqlx.DB) error {
 		currentDate := time.Now().In(location)
 		// Разблокируем номер
 		if number.StopExpirid != nil && currentDate.After(*number.StopExpirid) {
-			err := BlockNumberActions(db, false, nil, nil, number.Number, number.ClientID, number.TeamID, nil)
+			verdict := blockVerdict(ruleStopExpired, false, "Истёк срок блокировки по стратегии cause", model.LogDetails{
+				"strategy":     "cause",
+				"stop_expirid": *number.StopExpirid,
+			})
+			err := BlockNumberActions(db, false, nil, nil, number.Number, number.ClientID, number.TeamID, verdict)
 			if err != nil {
 				return fmt.Errorf("failed to unblock number actions: %w", err)
 			}
@@ -370,6 +424,7 @@ func CheckNumberForBlockByCause(db *sqlx.DB) error {
 
 			existsSuccess := false
 			signBlocked := false
+			var blockedCause model.ReasonsStat
 
 			// Перебираем коды ответа по номеру из статистики
 			for _, statCause := range reasonsStat {
@@ -388,6 +443,7 @@ func CheckNumberForBlockByCause(db *sqlx.DB) error {
 						// Если находим совпадение, помечаем переменную и выходим из цикла
 						if strconv.Itoa(badCause) == statCause.SipCode {
 							signBlocked = true
+							blockedCause = statCause
 							break // Выход из внутреннего цикла
 						}
 					}
@@ -411,8 +467,16 @@ func CheckNumberForBlockByCause(db *sqlx.DB) error {
 				// Блокируем номер
 				if number.StopExpirid == nil && number.StopDays != nil {
 					blockToDate := currentDate.AddDate(0, 0, *number.StopDays)
-					description := "Не было успешного отбоя"
-					err := BlockNumberActions(db, true, &currentDate, &blockToDate, number.Number, number.ClientID, number.TeamID, &description)
+					verdict := blockVerdict(ruleStrategyCause, true, "Не было успешного отбоя", model.LogDetails{
+						"strategy":      "cause",
+						"stop_days":     *number.StopDays,
+						"block_to":      blockToDate,
+						"sip_code":      blockedCause.SipCode,
+						"sip_reason":    blockedCause.SipReason,
+						"sip_count":     blockedCause.Count,
+						"bad_sip_codes": badSIPCauses,
+					})
+					err := BlockNumberActions(db, true, &currentDate, &blockToDate, number.Number, number.ClientID, number.TeamID, verdict)
 					if err != nil {
 						return fmt.Errorf("failed to block number actions: %w", err)
 					}
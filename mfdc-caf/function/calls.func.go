@@ -0,0 +1,87 @@
+package function
+
+import (
+	"caf/model"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Звонок считается успешным от 7 сек, если у команды не задано иное
+const defaultSuccessMinTalkSec = 7
+
+// Критерии успешного звонка команды
+func getSuccessCriteria(db *sqlx.DB, teamID *int) (model.SuccessCriteria, error) {
+	criteria := model.SuccessCriteria{MinTalkSec: defaultSuccessMinTalkSec}
+	if teamID == nil {
+		return criteria, nil
+	}
+
+	var team model.TeamDB
+	err := db.Get(&team, "SELECT success_min_talk_sec, success_hangup_by, success_sip_codes FROM caf.teams WHERE id = $1", *teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return criteria, nil
+		}
+		return criteria, fmt.Errorf("failed to get success criteria for team %d: %w", *teamID, err)
+	}
+
+	if team.SuccessMinTalkSec != nil {
+		criteria.MinTalkSec = *team.SuccessMinTalkSec
+	}
+	if team.SuccessHangupBy != nil {
+		criteria.HangupBy = PgTextArr2StrArr(*team.SuccessHangupBy)
+	}
+	if team.SuccessSipCodes != nil {
+		criteria.SipCodes = PgIntArr2IntArr(*team.SuccessSipCodes)
+	}
+	return criteria, nil
+}
+
+// Проверка звонка по критериям команды, возвращает причину, по которой звонок не успешный
+func checkCallSuccess(criteria model.SuccessCriteria, call model.Call) (bool, string) {
+	if call.TalkSec == nil || *call.TalkSec < criteria.MinTalkSec {
+		return false, fmt.Sprintf("Talk time less than %d sec, it is unsuccessful", criteria.MinTalkSec)
+	}
+	if len(criteria.HangupBy) > 0 {
+		accepted := false
+		for _, hangupBy := range criteria.HangupBy {
+			if call.HangupBy != nil && *call.HangupBy == hangupBy {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return false, "Hangup by is not accepted for the team, it is unsuccessful"
+		}
+	}
+	if len(criteria.SipCodes) > 0 {
+		accepted := false
+		for _, sipCode := range criteria.SipCodes {
+			if call.SipCode != nil && *call.SipCode == sipCode {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return false, "SIP code is not accepted for the team, it is unsuccessful"
+		}
+	}
+	return true, ""
+}
+
+// Запись звонка в историю номера
+func addCallHistory(tx *sqlx.Tx, call *model.Call) error {
+	now := time.Now()
+	call.CreatedAt = &now
+
+	err := tx.QueryRow(`INSERT INTO caf.calls (num_id, "number", team_id, created_at, talk_sec, hangup_by, sip_code, success)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		call.NumID, call.Number, call.TeamID, call.CreatedAt, call.TalkSec, call.HangupBy, call.SipCode, call.Success).Scan(&call.ID)
+	if err != nil {
+		return fmt.Errorf("failed to add call for number %s: %w", call.Number, err)
+	}
+	return nil
+}
@@ -17,6 +17,14 @@ const (
 			email varchar NULL,
 			filtration bool DEFAULT false NULL,
 			bad_sip_codes _int4 NULL,
+			rules jsonb NULL,
+			success_min_talk_sec int4 NULL,
+			success_hangup_by _text NULL,
+			success_sip_codes _int4 NULL,
+			notify_channels jsonb NULL,
+			notify_interval_min int4 NULL,
+			notify_templates jsonb NULL,
+			notified_at timestamptz NULL,
 			CONSTRAINT strategy_check CHECK (((strategy)::text = ANY ((ARRAY['cause'::character varying, 'unsuccessful'::character varying])::text[]))),
 			CONSTRAINT teams_pk PRIMARY KEY (id)
 		);`
@@ -42,6 +50,8 @@ const (
 			member_id varchar NULL,
 			first_success_call_at timestamptz NULL,
 			second_success_call_at timestamptz NULL,
+			first_success_talk_sec int4 NULL,
+			second_success_talk_sec int4 NULL,
 			CONSTRAINT numbers_pk PRIMARY KEY (id)
 		);`
 
@@ -54,6 +64,10 @@ const (
 			team_id int4 NULL,
 			filtered bool DEFAULT false NULL,
 			sent bool DEFAULT false NULL,
+			"rule" varchar NULL,
+			"action" varchar NULL,
+			details jsonb NULL,
+			notified_channels _text NULL,
 			CONSTRAINT logs_pk PRIMARY KEY (id),
 			CONSTRAINT logs_numbers_fk FOREIGN KEY (num_id) REFERENCES caf.numbers(id) ON DELETE CASCADE
 		);`
@@ -68,13 +82,75 @@ const (
 			CONSTRAINT num_reasons_numbers_fk FOREIGN KEY (num_id) REFERENCES caf.numbers(id) ON DELETE CASCADE
 		);`
 
+	createCallsTableSQL = `CREATE TABLE IF NOT EXISTS caf.calls (
+			id bigserial NOT NULL,
+			num_id int8 NOT NULL,
+			"number" varchar NOT NULL,
+			team_id int4 NULL,
+			created_at timestamptz NOT NULL,
+			talk_sec int4 NULL,
+			hangup_by varchar NULL,
+			sip_code int4 NULL,
+			success bool DEFAULT false NOT NULL,
+			CONSTRAINT calls_pk PRIMARY KEY (id),
+			CONSTRAINT calls_numbers_fk FOREIGN KEY (num_id) REFERENCES caf.numbers(id) ON DELETE CASCADE
+		);`
+
+	// Перенос успешных звонков, известных до появления caf.calls
+	migrateSuccessCallsSQL = `INSERT INTO caf.calls (num_id, "number", team_id, created_at, talk_sec, success)
+		SELECT n.id, n.number, n.team_id, s.created_at, s.talk_sec, true
+		FROM caf.numbers AS n
+		CROSS JOIN LATERAL (VALUES (n.first_success_call_at, n.first_success_talk_sec), (n.second_success_call_at, n.second_success_talk_sec)) AS s(created_at, talk_sec)
+		WHERE s.created_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM caf.calls AS c WHERE c.num_id = n.id);`
+
+	// Коды правил для записей лога, сделанных до их появления, по тексту описания. Выполняется один раз, при добавлении колонки details
+	migrateLogRulesSQL = `UPDATE caf.logs AS l SET "rule" = m.rule,
+			"action" = COALESCE(m.action, CASE WHEN l.filtered THEN 'reject' ELSE 'log' END)
+		FROM (VALUES
+			('Номер в чёрном списке', 'blacklist', NULL),
+			('Такой client_id сегодня уже был загружен с другим номером и по нему был успешный дозвон', 'client_id', NULL),
+			('Сегодня уже был успешный вызов на этот номер', 'today_success', NULL),
+			('На этот номер уже было 2 успешных звонка за последние 7 дней', 'success_window', NULL),
+			('Сегодня номер уже был добавлен', 'duplicate', NULL),
+			('За 30 дней не было успешных', 'strategy_unsuccessful', 'block'),
+			('Не было успешного отбоя', 'strategy_cause', 'block'),
+			('Истёк срок блокировки в ЧС', 'blacklist_expired', 'unblock'),
+			('Добавлен в ЧС', 'manual', 'block'),
+			('Удалён из ЧС', 'manual', 'unblock')
+		) AS m(description, "rule", "action")
+		WHERE l."rule" IS NULL AND l.description = m.description;`
+
+	createSyncJobsTableSQL = `CREATE TABLE IF NOT EXISTS caf.sync_jobs (
+			id bigserial NOT NULL,
+			target varchar NOT NULL,
+			operation varchar NOT NULL,
+			"number" varchar NOT NULL,
+			client_id varchar NULL,
+			description varchar NULL,
+			state varchar DEFAULT 'pending' NOT NULL,
+			attempts int4 DEFAULT 0 NOT NULL,
+			next_run_at timestamptz NULL,
+			last_error varchar NULL,
+			created_at timestamptz NOT NULL,
+			updated_at timestamptz NULL,
+			CONSTRAINT sync_jobs_pk PRIMARY KEY (id),
+			CONSTRAINT sync_jobs_target_check CHECK (((target)::text = ANY ((ARRAY['webitel'::character varying, 'bp'::character varying])::text[]))),
+			CONSTRAINT sync_jobs_operation_check CHECK (((operation)::text = ANY ((ARRAY['add'::character varying, 'remove'::character varying])::text[]))),
+			CONSTRAINT sync_jobs_state_check CHECK (((state)::text = ANY ((ARRAY['pending'::character varying, 'done'::character varying, 'dead'::character varying, 'superseded'::character varying])::text[])))
+		);`
+
 	createBlackListTableSQL = `CREATE TABLE IF NOT EXISTS caf.blacklist (
 			id bigserial NOT NULL,
 			"number" varchar NULL,
 			created_at timestamptz NULL,
 			team_id int4 NULL,
 			description varchar NULL,
-			CONSTRAINT blacklist_pk PRIMARY KEY (id)
+			expires_at timestamptz NULL,
+			"scope" varchar DEFAULT 'global' NOT NULL,
+			reason_category varchar NULL,
+			CONSTRAINT blacklist_pk PRIMARY KEY (id),
+			CONSTRAINT blacklist_scope_check CHECK ((("scope")::text = ANY ((ARRAY['global'::character varying, 'team'::character varying])::text[])))
 		);`
 )
 
@@ -106,6 +182,54 @@ func CreateTables(db *sqlx.DB) error {
 		return err
 	}
 
+	_, err = db.Exec(createCallsTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createSyncJobsTableSQL)
+	if err != nil {
+		return err
+	}
+
+	// Записи лога без колонки details сделаны до появления кодов правил, их коды восстановим после добавления колонок
+	var logDetailsExists bool
+	err = db.Get(&logDetailsExists, `SELECT EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_schema = 'caf' AND table_name = 'logs' AND column_name = 'details')`)
+	if err != nil {
+		return err
+	}
+
+	// SQL-запросы для добавления колонок в уже существующие таблицы
+	alterSQLs := []string{
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS rules jsonb NULL;",
+		"ALTER TABLE caf.logs ADD COLUMN IF NOT EXISTS \"rule\" varchar NULL;",
+		"ALTER TABLE caf.logs ADD COLUMN IF NOT EXISTS \"action\" varchar NULL;",
+		"ALTER TABLE caf.numbers ADD COLUMN IF NOT EXISTS first_success_talk_sec int4 NULL;",
+		"ALTER TABLE caf.numbers ADD COLUMN IF NOT EXISTS second_success_talk_sec int4 NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS success_min_talk_sec int4 NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS success_hangup_by _text NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS success_sip_codes _int4 NULL;",
+		"ALTER TABLE caf.blacklist ADD COLUMN IF NOT EXISTS expires_at timestamptz NULL;",
+		"ALTER TABLE caf.blacklist ADD COLUMN IF NOT EXISTS \"scope\" varchar DEFAULT 'global' NOT NULL;",
+		"ALTER TABLE caf.blacklist ADD COLUMN IF NOT EXISTS reason_category varchar NULL;",
+		"ALTER TABLE caf.logs ADD COLUMN IF NOT EXISTS details jsonb NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS notify_channels jsonb NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS notify_interval_min int4 NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS notify_templates jsonb NULL;",
+		"ALTER TABLE caf.teams ADD COLUMN IF NOT EXISTS notified_at timestamptz NULL;",
+		"ALTER TABLE caf.logs ADD COLUMN IF NOT EXISTS notified_channels _text NULL;",
+		"ALTER TABLE caf.sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_state_check;",
+		"ALTER TABLE caf.sync_jobs ADD CONSTRAINT sync_jobs_state_check CHECK (((state)::text = ANY ((ARRAY['pending'::character varying, 'done'::character varying, 'dead'::character varying, 'superseded'::character varying])::text[])));",
+	}
+
+	// Выполнение запросов на добавление колонок
+	for _, alterSQL := range alterSQLs {
+		if _, err := db.Exec(alterSQL); err != nil {
+			return err
+		}
+	}
+
 	// SQL-запросы для создания индексов
 	indexSQLs := []string{
 		"CREATE INDEX IF NOT EXISTS numbers_blocked_idx ON caf.numbers USING btree (blocked);",
@@ -125,6 +249,12 @@ func CreateTables(db *sqlx.DB) error {
 		"CREATE INDEX IF NOT EXISTS blacklist_created_at_idx ON caf.blacklist USING btree (created_at);",
 		"CREATE INDEX IF NOT EXISTS blacklist_number_idx ON caf.blacklist USING btree (number);",
 		"CREATE INDEX IF NOT EXISTS blacklist_team_id_idx ON caf.blacklist USING btree (team_id);",
+		"CREATE INDEX IF NOT EXISTS blacklist_expires_at_idx ON caf.blacklist USING btree (expires_at);",
+		"CREATE INDEX IF NOT EXISTS calls_num_id_created_at_idx ON caf.calls USING btree (num_id, created_at);",
+		"CREATE INDEX IF NOT EXISTS calls_created_at_idx ON caf.calls USING btree (created_at);",
+		"CREATE INDEX IF NOT EXISTS calls_number_idx ON caf.calls USING btree (number);",
+		"CREATE INDEX IF NOT EXISTS sync_jobs_state_next_run_at_idx ON caf.sync_jobs USING btree (state, next_run_at);",
+		"CREATE INDEX IF NOT EXISTS sync_jobs_number_idx ON caf.sync_jobs USING btree (number);",
 	}
 
 	// Выполнение запросов на создание индексов
@@ -133,5 +263,17 @@ func CreateTables(db *sqlx.DB) error {
 			return err
 		}
 	}
+
+	_, err = db.Exec(migrateSuccessCallsSQL)
+	if err != nil {
+		return err
+	}
+
+	if !logDetailsExists {
+		_, err = db.Exec(migrateLogRulesSQL)
+		if err != nil {
+			return err
+		}
+	}
 	return err
 }
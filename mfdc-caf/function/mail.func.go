@@ -1,16 +1,10 @@
 package function
 
 import (
-	"bytes"
-	"caf/model"
 	"crypto/tls"
 	"fmt"
 	"net/smtp"
-	"strings"
-	"text/template"
 	"time"
-
-	"github.com/jmoiron/sqlx"
 )
 
 // SendEmail отправляет электронное письмо через SMTP сервер с TLS авторизацией.
@@ -99,143 +93,3 @@ func SendEmail(to []string, subject string, body string, contentType string) err
 func formatTime(t time.Time) string {
 	return t.Format("02.01.2006 15:04:05")
 }
-
-func FilteredNotify(db *sqlx.DB) error {
-
-	// Получаем срез команд
-	var teamsDB []model.TeamDB
-	err := db.Select(&teamsDB, "SELECT * FROM caf.teams WHERE active = $1 AND filtration = $2", true, false)
-	if err != nil {
-		return fmt.Errorf("failed to get teams: %w", err)
-	}
-
-	// Создаем срез команд
-	teams := make([]model.Team, len(teamsDB))
-	for idx, team := range teamsDB {
-		teams[idx].ID = team.ID
-		teams[idx].Name = team.Name
-		teams[idx].EMail = team.EMail
-	}
-
-	loc, err := time.LoadLocation(config.API.TimeZone)
-	if err != nil {
-		return fmt.Errorf("failed to load timezone: %s", err)
-	}
-
-	currentTime := time.Now().In(loc)
-	fromDate := currentTime.Add(-1 * time.Hour)
-	toDate := currentTime
-
-	dateFormat := "2006-01-02 15:04:05.000 -07"
-	from := fromDate.Format(dateFormat)
-	to := toDate.Format(dateFormat)
-
-	// Перебираем срез команд и ищем соответствие
-	for _, team := range teams {
-
-		var logs []model.Logs
-		err = db.Select(&logs, "SELECT * FROM caf.logs WHERE team_id = $1 AND filtered = $2 AND sent = $3 AND created_at BETWEEN $4 AND $5", team.ID, false, false, from, to)
-		if err != nil {
-			return fmt.Errorf("failed to get logs: %s", err)
-		}
-
-		for i := range logs { // Используем индекс для изменения исходного среза
-			log := &logs[i] // Получаем указатель на текущий лог
-
-			if log.CreatedAt != nil {
-				CreatedAtTz := log.CreatedAt.In(loc) // Применяем временную зону
-				log.CreatedAt = &CreatedAtTz         // Обновляем указатель на новое время
-			}
-
-			_, err := db.Exec("UPDATE caf.logs SET sent = $1 WHERE id = $2", true, log.ID)
-			if err != nil {
-				return fmt.Errorf("failed to update logs for sent status: %w", err)
-			}
-		}
-
-		// Если в логах есть отфильтрованные номера на последный период
-		if len(logs) != 0 {
-			tmpl := `<!DOCTYPE html>
-					<html lang="en">
-					<head>
-						<meta charset="UTF-8">
-						<meta name="viewport" content="width=device-width, initial-scale=1.0">
-						<title>Filtered contacts</title>
-					</head>
-					<body>
-						<h1>Отфильтрованные контакты "{{.TeamName}}" за период<br>
-				{{.FromDate}} - {{.ToDate}}
-				</h1>
-						<table border="1">
-							<tr>
-								<th>Дата</th>
-								<th>Номер</th>
-								<th>Описание</th>
-							</tr>
-							{{range .Logs}}
-							<tr>
-								<td>{{formatTime .CreatedAt}}</td>
-								<td>{{.Number}}</td>
-								<td>{{.Description}}</td>
-							</tr>
-							{{end}}
-						</table>
-					</body>
-					</html>`
-
-			// Создаем новый шаблон и регистрируем функцию форматирования
-			t, err := template.New("email").Funcs(template.FuncMap{
-				"formatTime": formatTime,
-			}).Parse(tmpl)
-			if err != nil {
-				return fmt.Errorf("failed to parse template: %s", err)
-			}
-
-			teamName := "Unknown"
-			if team.Name != nil {
-				teamName = *team.Name
-			}
-
-			// Создаем данные для шаблона
-			emailData := model.EmailData{
-				Logs:     logs,
-				TeamName: teamName,
-				FromDate: formatTime(fromDate),
-				ToDate:   formatTime(toDate),
-			}
-
-			// Создаем буфер для хранения сгенерированного HTML
-			var buf bytes.Buffer
-			if err := t.Execute(&buf, emailData); err != nil {
-				return fmt.Errorf("failed to execute template: %s", err)
-			}
-
-			// Получаем сгенерированный HTML как строку
-			htmlContent := buf.String()
-
-			// Отправляем почту
-			subject := "MFDC filter info"
-
-			// Преобразование списка email в срез строк
-			EMail := config.MAIL.AuthUser
-			if team.EMail != nil {
-				EMail = *team.EMail
-			}
-			toAddr := strings.Split(EMail, ", ")
-
-			// Удаление пробелов вокруг каждого email (если они есть)
-			for i := range toAddr {
-				toAddr[i] = strings.TrimSpace(toAddr[i])
-			}
-
-			OutLog.Println("Sending mail notification to ", toAddr)
-
-			err = SendEmail(toAddr, subject, htmlContent, "html")
-			if err != nil {
-				return fmt.Errorf("failed to send mail: %s", err)
-			}
-		}
-	}
-
-	return nil
-}
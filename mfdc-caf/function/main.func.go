@@ -32,6 +32,8 @@ var (
 	checkCauseMu          sync.Mutex // Для StartCheckNumberForBlockByCause
 	recheckUnsuccessfulMu sync.Mutex // Для StartRecheckNumberForBlockByUnsuccessful
 	filteredNotifyMu      sync.Mutex // Для StartFilteredNotify
+	blacklistExpiryMu     sync.Mutex // Для StartBlacklistExpiry
+	syncWorkerMu          sync.Mutex // Для StartSyncWorker
 )
 
 // Самая первая автоматически-загружаемая функция
@@ -202,6 +204,31 @@ func PgIntArr2IntArr(pg_arr pgtype.Int4Array) []int {
 	return []int{}
 }
 
+// Функция конвертации из []string в pgtype.TextArray
+func StrArr2PgTextArr(str_arr []string) (pg_arr pgtype.TextArray) {
+	if len(str_arr) > 0 {
+		pg_arr.Set(str_arr)
+	} else {
+		pg_arr.Set([]string{})
+	}
+
+	return pg_arr
+}
+
+// Функция конвертации из pgtype.TextArray в []string
+func PgTextArr2StrArr(pg_arr pgtype.TextArray) []string {
+	if pg_arr.Status == pgtype.Present {
+		values := make([]string, 0, len(pg_arr.Elements))
+		for _, elem := range pg_arr.Elements {
+			if elem.Status == pgtype.Present {
+				values = append(values, elem.String)
+			}
+		}
+		return values
+	}
+	return []string{}
+}
+
 // Функция для добавления условий в запрос
 func addCondition(query *string, condition string, paramIndex int, args *[]interface{}, value interface{}) {
 	*query += fmt.Sprintf(" AND %s = $%d", condition, paramIndex)
@@ -0,0 +1,649 @@
+package function
+
+import (
+	"caf/model"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgtype"
+	"github.com/jmoiron/sqlx"
+)
+
+// Статусы мемберов в /{id}/members/bulk
+const (
+	bulkAccepted = "accepted" // Отправлен в Webitel
+	bulkFiltered = "filtered" // Отклонён правилом
+	bulkError    = "error"    // Ошибка данных или Webitel
+)
+
+// Значения по умолчанию для загрузки мемберов пачками
+const (
+	defaultBulkBatchSize   = 100
+	defaultBulkConcurrency = 4
+)
+
+// Мембер из пачки с подготовленным телом запроса в Webitel
+type bulkMember struct {
+	name     string
+	body     map[string]interface{}
+	recall   *bool
+	clientID *string
+	verdicts []*model.FilterVerdict // Все сработавшие правила
+	verdict  *model.FilterVerdict   // Итоговое решение по сработавшим правилам
+	result   *model.MemberBulkResult
+}
+
+// Запуск fn для 0..count-1, одновременно выполняется не больше limit вызовов
+func runBounded(count int, limit int, fn func(idx int)) {
+	if limit <= 0 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for idx := 0; idx < count; idx++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(idx)
+		}(idx)
+	}
+	wg.Wait()
+}
+
+// Чтение мемберов из CSV. Первая строка - заголовок, номер в колонке number или destination.
+// Колонки name, type_id, priority, timezone_id, min_offering_at попадают в поля мембера, остальные в variables
+func readMembersCSV(src io.Reader, typeID string) ([]model.Members, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1 // Количество колонок в строках может отличаться
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV is empty")
+	}
+
+	header := make([]string, len(records[0]))
+	numberColumn := -1
+	for idx, column := range records[0] {
+		header[idx] = strings.ToLower(strings.TrimSpace(column))
+		if header[idx] == "number" || header[idx] == "destination" {
+			numberColumn = idx
+		}
+	}
+	if numberColumn < 0 {
+		return nil, fmt.Errorf("CSV header must contain 'number' or 'destination' column")
+	}
+
+	members := make([]model.Members, 0, len(records)-1)
+	for _, record := range records[1:] {
+		var member model.Members
+		variables := map[string]string{}
+		memberTypeID := typeID
+		var destination string
+
+		for idx, value := range record {
+			if idx >= len(header) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			switch header[idx] {
+			case "number", "destination":
+				destination = value
+			case "name":
+				name := value
+				member.Name = &name
+			case "type_id":
+				memberTypeID = value
+			case "priority":
+				priority, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid priority '%s' for number %s: %w", value, record[numberColumn], err)
+				}
+				member.Priority = &priority
+			case "timezone_id":
+				member.Timezone = &model.Timezone{ID: &model.IntString{Value: value}}
+			case "min_offering_at":
+				minOfferingAt := value
+				member.MinOfferingAt = &minOfferingAt
+			default:
+				variables[header[idx]] = value
+			}
+		}
+
+		communication := model.Communication{
+			Destination: &destination,
+			Type:        &model.CommunicationType{ID: &model.IntString{Value: memberTypeID}},
+		}
+		member.Communications = &[]model.Communication{communication}
+		member.Variables = &variables
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// Чтение мемберов из файла формы, CSV в теле запроса или JSON массива
+func readBulkMembers(c *gin.Context) ([]model.Members, error) {
+	typeID := c.DefaultQuery("type_id", "1")
+
+	if file, err := c.FormFile("file"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("unable to open file: %w", err)
+		}
+		defer src.Close()
+		return readMembersCSV(src, typeID)
+	}
+
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		return readMembersCSV(c.Request.Body, typeID)
+	}
+
+	var members []model.Members
+	if err := c.ShouldBindJSON(&members); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON array of members: %w", err)
+	}
+	return members, nil
+}
+
+// Номера кандидатов для проверки правила одним запросом
+func bulkNumbers(candidates []*bulkMember) pgtype.TextArray {
+	numbers := make([]string, len(candidates))
+	for idx, member := range candidates {
+		numbers[idx] = member.name
+	}
+	return StrArr2PgTextArr(numbers)
+}
+
+// Проверка правила для всей пачки, возвращает индексы кандидатов, на которых правило сработало
+func bulkRuleMatches(db *sqlx.DB, rule model.FilterRule, teamID int, queueID string, candidates []*bulkMember) (map[int]bool, error) {
+	matches := map[int]bool{}
+	numbers := bulkNumbers(candidates)
+
+	// Номера, на которых сработало правило
+	var matched []string
+	var err error
+
+	switch rule.Code {
+	case ruleBlacklist:
+		err = db.Select(&matched, `SELECT "number" FROM caf.blacklist WHERE "number" = ANY($1)
+			AND ("scope" = $4 OR team_id = $5) AND (expires_at IS NULL OR expires_at > now())
+			UNION SELECT "number" FROM caf.numbers WHERE "number" = ANY($1) AND blocked = $2 AND repeated_check = $3`, numbers, true, false, blacklistGlobal, teamID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check numbers for blocking: %w", err)
+		}
+	case ruleClientID:
+		// Правило проверяется по паре номер и client_id
+		var pairNumbers, pairClients []string
+		for _, member := range candidates {
+			if member.clientID != nil {
+				pairNumbers = append(pairNumbers, member.name)
+				pairClients = append(pairClients, *member.clientID)
+			}
+		}
+		if len(pairNumbers) == 0 {
+			return matches, nil
+		}
+
+		var pairs []struct {
+			Number   string `db:"number"`
+			ClientID string `db:"client_id"`
+		}
+		err = db.Select(&pairs, `SELECT DISTINCT i.number, i.client_id FROM unnest($1::text[], $2::text[]) AS i("number", client_id)
+			WHERE EXISTS (SELECT 1 FROM caf.numbers WHERE client_id = i.client_id AND "number" != i.number AND DATE(last_load_at) = CURRENT_DATE)
+			AND EXISTS (SELECT 1 FROM caf.numbers WHERE client_id = i.client_id AND today_success_call = $3)`,
+			StrArr2PgTextArr(pairNumbers), StrArr2PgTextArr(pairClients), true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check client_id for numbers: %w", err)
+		}
+
+		matchedPairs := map[string]bool{}
+		for _, pair := range pairs {
+			matchedPairs[pair.Number+"\x00"+pair.ClientID] = true
+		}
+		for idx, member := range candidates {
+			if member.clientID != nil && matchedPairs[member.name+"\x00"+*member.clientID] {
+				matches[idx] = true
+			}
+		}
+		return matches, nil
+	case ruleTodaySuccess:
+		if rule.Params.MinTalkSec == nil {
+			err = db.Select(&matched, `SELECT "number" FROM caf.numbers WHERE today_success_call = $1 AND "number" = ANY($2)`, true, numbers)
+		} else {
+			err = db.Select(&matched, `SELECT DISTINCT n.number FROM caf.numbers AS n
+				INNER JOIN caf.calls AS c ON c.num_id = n.id
+				WHERE n.today_success_call = $1 AND n.number = ANY($2)
+				AND c.success = TRUE AND c.created_at >= CURRENT_DATE AND COALESCE(c.talk_sec, 7) >= $3`, true, numbers, *rule.Params.MinTalkSec)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check today success calls: %w", err)
+		}
+	case ruleSuccessWindow:
+		windowDays, maxSuccesses := successWindow(rule)
+		minTalk := 0
+		if rule.Params.MinTalkSec != nil {
+			minTalk = *rule.Params.MinTalkSec
+		}
+
+		err = db.Select(&matched, `SELECT n.number FROM caf.calls AS c
+			INNER JOIN caf.numbers AS n ON c.num_id = n.id
+			WHERE n.number = ANY($1) AND c.success = TRUE AND COALESCE(c.talk_sec, 7) >= $3
+			AND c.created_at >= CASE WHEN $2::int4 > 0 THEN now() - make_interval(days => $2::int4) ELSE DATE_TRUNC('week', CURRENT_DATE) END
+			GROUP BY n.number HAVING COUNT(*) >= $4`, numbers, windowDays, minTalk, maxSuccesses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count success calls: %w", err)
+		}
+	case ruleDuplicate:
+		return bulkDuplicates(db, queueID, candidates)
+	}
+
+	matchedNumbers := map[string]bool{}
+	for _, number := range matched {
+		matchedNumbers[number] = true
+	}
+	for idx, member := range candidates {
+		if matchedNumbers[member.name] {
+			matches[idx] = true
+		}
+	}
+	return matches, nil
+}
+
+// Дубли внутри пачки и номера, которые сегодня уже загружены и ещё есть в очереди Webitel
+func bulkDuplicates(db *sqlx.DB, queueID string, candidates []*bulkMember) (map[int]bool, error) {
+	matches := map[int]bool{}
+
+	// Повторный номер в пачке - дубль первого
+	seen := map[string]bool{}
+	for idx, member := range candidates {
+		if seen[member.name] {
+			matches[idx] = true
+		}
+		seen[member.name] = true
+	}
+
+	var loaded []struct {
+		Number   string `db:"number"`
+		MemberID string `db:"member_id"`
+	}
+	err := db.Select(&loaded, `SELECT DISTINCT ON ("number") "number", member_id FROM caf.numbers
+		WHERE DATE(last_load_at) = CURRENT_DATE AND "number" = ANY($1) AND member_id IS NOT NULL
+		ORDER BY "number", last_load_at DESC`, bulkNumbers(candidates))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check today load numbers: %w", err)
+	}
+
+	// Проверяем в Webitel, есть ли мемберы из предыдущей загрузки в очереди
+	inQueue := make([]bool, len(loaded))
+	errs := make([]error, len(loaded))
+	runBounded(len(loaded), bulkConcurrency(), func(idx int) {
+		url := fmt.Sprintf("%s/call_center/queues/%s/members/%s", config.API_Webitel.URL, queueID, loaded[idx].MemberID)
+		_, statusCode, err := APIFetch(config.API_Webitel.Header, config.API_Webitel.Key, "GET", url, nil)
+		if err != nil {
+			errs[idx] = fmt.Errorf("failed to send request to Webitel: %w", err)
+			return
+		}
+		inQueue[idx] = statusCode >= 200 && statusCode < 300
+	})
+
+	duplicates := map[string]bool{}
+	for idx, number := range loaded {
+		if errs[idx] != nil {
+			return nil, errs[idx]
+		}
+		if inQueue[idx] {
+			duplicates[number.Number] = true
+		}
+	}
+	for idx, member := range candidates {
+		if duplicates[member.name] {
+			matches[idx] = true
+		}
+	}
+	return matches, nil
+}
+
+// Проверка пачки по правилам команды: правила проверяются по порядку, каждое одним запросом для всех ещё не отклонённых мемберов.
+// log и delay не останавливают проверку мембера, итоговое решение выбирается по всем сработавшим правилам
+func filterBulkMembers(db *sqlx.DB, rules model.FilterRules, teamID int, queueID string, members []*bulkMember) error {
+	for _, rule := range rules {
+		// Мемберы с recall yes не фильтруются
+		var candidates []*bulkMember
+		for _, member := range members {
+			if !bulkRejected(member) && (member.recall == nil || !*member.recall) {
+				candidates = append(candidates, member)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		matches, err := bulkRuleMatches(db, rule, teamID, queueID, candidates)
+		if err != nil {
+			return fmt.Errorf("failed to check rule '%s': %w", rule.Code, err)
+		}
+		for idx := range matches {
+			candidates[idx].verdicts = append(candidates[idx].verdicts, ruleVerdict(rule))
+		}
+	}
+
+	for _, member := range members {
+		member.verdict = decisiveVerdict(member.verdicts)
+	}
+	return nil
+}
+
+// Мембер уже отклонён одним из правил
+func bulkRejected(member *bulkMember) bool {
+	count := len(member.verdicts)
+	return count != 0 && member.verdicts[count-1].Action == actionReject
+}
+
+// Отправка мемберов в Webitel пачками, одновременно выполняется не больше bulk_concurrency запросов
+func sendBulkMembers(queueID string, members []*bulkMember) {
+	batchSize := config.Members.BulkBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBulkBatchSize
+	}
+
+	var batches [][]*bulkMember
+	for start := 0; start < len(members); start += batchSize {
+		end := start + batchSize
+		if end > len(members) {
+			end = len(members)
+		}
+		batches = append(batches, members[start:end])
+	}
+
+	url := fmt.Sprintf("%s/call_center/queues/%s/members/bulk", config.API_Webitel.URL, queueID)
+	runBounded(len(batches), bulkConcurrency(), func(idx int) {
+		batch := batches[idx]
+		items := make([]map[string]interface{}, len(batch))
+		for i, member := range batch {
+			items[i] = member.body
+		}
+
+		err := sendBulkBatch(url, items, batch)
+		if err != nil {
+			message := err.Error()
+			for _, member := range batch {
+				member.result.Status = bulkError
+				member.result.Error = &message
+			}
+		}
+	})
+}
+
+// Отправка одной пачки, ID мемберов Webitel возвращает в порядке отправки
+func sendBulkBatch(url string, items []map[string]interface{}, batch []*bulkMember) error {
+	responseBody, statusCode, err := APIFetch(config.API_Webitel.Header, config.API_Webitel.Key, "POST", url, map[string]interface{}{"items": items})
+	if err != nil {
+		return fmt.Errorf("failed to send request to Webitel: %w", err)
+	}
+
+	var response struct {
+		IDs    []string `json:"ids"`
+		Detail *string  `json:"detail"`
+	}
+	err = json.Unmarshal(responseBody, &response)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response from Webitel: %w", err)
+	}
+
+	if statusCode < 200 || statusCode >= 300 {
+		if response.Detail != nil {
+			return fmt.Errorf("webitel responded with status %d: %s", statusCode, *response.Detail)
+		}
+		return fmt.Errorf("webitel responded with status %d", statusCode)
+	}
+	if len(response.IDs) != len(batch) {
+		return fmt.Errorf("webitel returned %d member IDs for %d members", len(response.IDs), len(batch))
+	}
+
+	for idx, member := range batch {
+		memberID := response.IDs[idx]
+		member.result.Status = bulkAccepted
+		member.result.MemberID = &memberID
+	}
+	return nil
+}
+
+func bulkConcurrency() int {
+	if config.Members.BulkConcurrency <= 0 {
+		return defaultBulkConcurrency
+	}
+	return config.Members.BulkConcurrency
+}
+
+// Добавление или обновление принятых мемберов в базе двумя запросами
+func addBulkMembersToDB(db *sqlx.DB, queueID string, teamID int, members []*bulkMember) error {
+	numbers := make([]string, 0, len(members))
+	memberIDs := make([]string, 0, len(members))
+	clientIDs := make([]*string, 0, len(members))
+	for _, member := range members {
+		if member.result.MemberID == nil {
+			continue
+		}
+		numbers = append(numbers, member.name)
+		memberIDs = append(memberIDs, *member.result.MemberID)
+		clientIDs = append(clientIDs, member.clientID)
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	var clientArr pgtype.TextArray
+	if err := clientArr.Set(clientIDs); err != nil {
+		return fmt.Errorf("failed to prepare client IDs: %w", err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	now := time.Now()
+
+	// Обновляем записи, которые уже есть в базе
+	_, err = tx.Exec(`UPDATE caf.numbers AS n SET queue_id = $1, team_id = $2, member_id = i.member_id, last_load_at = $3,
+		load_counter = load_counter + 1, client_id = i.client_id
+		FROM unnest($4::text[], $5::text[], $6::text[]) AS i("number", member_id, client_id)
+		WHERE n.number = i.number`, queueID, teamID, now, StrArr2PgTextArr(numbers), StrArr2PgTextArr(memberIDs), clientArr)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to update numbers: %w", err)
+	}
+
+	// Остальные номера добавляем
+	_, err = tx.Exec(`INSERT INTO caf.numbers (load_counter, first_load_at, last_load_at, queue_id, team_id, member_id, "number", client_id)
+		SELECT DISTINCT ON (i.number) 1, $3, $3, $1, $2, i.member_id, i.number, i.client_id
+		FROM unnest($4::text[], $5::text[], $6::text[]) AS i("number", member_id, client_id)
+		WHERE NOT EXISTS (SELECT 1 FROM caf.numbers WHERE "number" = i.number)`, queueID, teamID, now, StrArr2PgTextArr(numbers), StrArr2PgTextArr(memberIDs), clientArr)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to insert new numbers: %w", err)
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Запись сработавших правил в лог одним запросом. Для номеров, которых нет в caf.numbers, num_id остаётся пустым
+func addBulkRuleLogs(db *sqlx.DB, teamID int, members []*bulkMember) error {
+	var numbers, descriptions, rules, actions, details []string
+	var filtered []bool
+	for _, member := range members {
+		for _, verdict := range member.verdicts {
+			numbers = append(numbers, member.name)
+			descriptions = append(descriptions, verdict.Message)
+			rules = append(rules, verdict.Rule)
+			actions = append(actions, verdict.Action)
+			filtered = append(filtered, verdict.Action == actionReject)
+
+			// Пустая строка станет NULL
+			detailsJSON := ""
+			if verdict.Details != nil {
+				value, err := json.Marshal(verdict.Details)
+				if err != nil {
+					return fmt.Errorf("failed to marshal log details: %w", err)
+				}
+				detailsJSON = string(value)
+			}
+			details = append(details, detailsJSON)
+		}
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	var filteredArr pgtype.BoolArray
+	if err := filteredArr.Set(filtered); err != nil {
+		return fmt.Errorf("failed to prepare filtered flags: %w", err)
+	}
+
+	_, err := db.Exec(`INSERT INTO caf.logs (created_at, team_id, num_id, "number", description, filtered, "rule", "action", details)
+		SELECT $1, $2, n.id, i.number, i.description, i.filtered, i.rule, i.action, NULLIF(i.details, '')::jsonb
+		FROM unnest($3::text[], $4::text[], $5::bool[], $6::text[], $7::text[], $8::text[]) AS i("number", description, filtered, "rule", "action", details)
+		LEFT JOIN LATERAL (SELECT id FROM caf.numbers WHERE "number" = i.number LIMIT 1) AS n ON true`,
+		time.Now(), teamID, StrArr2PgTextArr(numbers), StrArr2PgTextArr(descriptions), filteredArr, StrArr2PgTextArr(rules), StrArr2PgTextArr(actions), StrArr2PgTextArr(details))
+	if err != nil {
+		return fmt.Errorf("failed to add logs: %w", err)
+	}
+	return nil
+}
+
+// Bulk add members godoc
+// @Summary      Bulk add members
+// @Description  Check members by team filter rules in batches and send accepted members to Webitel. Members are sent as JSON array, CSV body (text/csv) or CSV file. CSV header must contain number or destination column
+// @Tags         Members
+// @Accept       json
+// @Accept       text/csv
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id   path      int  true  "Queue ID"
+// @Param        type_id   query      string  false  "Communication type ID for CSV without type_id column (default 1)"
+// @Param        file   formData      file  false  "CSV file"
+// @Param        members   body      []model.Members  false  "Members"
+// @Success      200  {array}   model.SwaggerMemberBulk
+// @Router       /{id}/members/bulk [post]
+// @Security ApiKeyAuth
+func ReceiveMembersBulk(db *sqlx.DB, c *gin.Context) {
+	id := c.Param("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Queue ID must be a number", "error": err.Error()})
+		return
+	}
+
+	rawMembers, err := readBulkMembers(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid members data", "error": err.Error()})
+		return
+	}
+	if len(rawMembers) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Members list is empty"})
+		return
+	}
+
+	teamID, rules, err := getQueueFilterRules(db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get filter rules", "error": err.Error()})
+		return
+	}
+
+	response := model.MemberBulkResponse{
+		Total: len(rawMembers),
+		Items: make([]model.MemberBulkResult, len(rawMembers)),
+	}
+
+	// Мемберы без номера сразу отмечаем ошибкой
+	var members []*bulkMember
+	for idx, raw := range rawMembers {
+		result := &response.Items[idx]
+		result.Index = idx
+
+		if raw.Communications == nil || len(*raw.Communications) == 0 || (*raw.Communications)[0].Destination == nil ||
+			*(*raw.Communications)[0].Destination == "" || (*raw.Communications)[0].Type == nil {
+			message := "Param Communications not found"
+			result.Status = bulkError
+			result.Error = &message
+			continue
+		}
+
+		name, body, recall, clientID := prepareMember(raw)
+		result.Number = name
+		members = append(members, &bulkMember{name: name, body: body, recall: recall, clientID: clientID, result: result})
+	}
+
+	err = filterBulkMembers(db, rules, teamID, id, members)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed check filter", "error": err.Error()})
+		return
+	}
+
+	// Отклонённые не отправляем, остальным при delay откладываем звонок
+	var accepted []*bulkMember
+	for _, member := range members {
+		if member.verdict != nil {
+			member.result.Rule = &member.verdict.Rule
+			member.result.Action = &member.verdict.Action
+			member.result.Reason = &member.verdict.Message
+
+			switch member.verdict.Action {
+			case actionReject:
+				member.result.Status = bulkFiltered
+				continue
+			case actionDelay:
+				member.body["min_offering_at"] = delayedOffering(member.verdict.Delay)
+			}
+		}
+		accepted = append(accepted, member)
+	}
+
+	sendBulkMembers(id, accepted)
+
+	err = addBulkMembersToDB(db, id, teamID, accepted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to add members to DB", "error": err.Error(), "data": response})
+		return
+	}
+
+	// Записываем в лог события по сработавшим правилам
+	err = addBulkRuleLogs(db, teamID, members)
+	if err != nil {
+		ErrLog.Printf("Failed save to log: %s", err)
+	}
+
+	for _, item := range response.Items {
+		switch item.Status {
+		case bulkAccepted:
+			response.Accepted++
+		case bulkFiltered:
+			response.Filtered++
+		default:
+			response.Failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": response})
+}
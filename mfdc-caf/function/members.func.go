@@ -13,9 +13,10 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-func checkMemberInBlock(db *sqlx.DB, number string) (blocked bool, err error) {
-	// Проверяем в ЧС
-	err = db.Get(&blocked, "SELECT EXISTS (SELECT 1 FROM caf.blacklist WHERE number = $1)", number)
+func checkMemberInBlock(db *sqlx.DB, number string, teamID int) (blocked bool, err error) {
+	// Проверяем в ЧС: общие записи и записи команды, срок которых не истёк
+	err = db.Get(&blocked, `SELECT EXISTS (SELECT 1 FROM caf.blacklist WHERE number = $1
+		AND (scope = $2 OR team_id = $3) AND (expires_at IS NULL OR expires_at > now()))`, number, blacklistGlobal, teamID)
 	if err != nil {
 		// Возвращаем ошибку, а не просто выводим ее
 		return false, fmt.Errorf("%w", err)
@@ -106,7 +107,7 @@ func getTeamIDByQueueID(db *sqlx.DB, queueID string) (int, bool, error) {
 	return 0, false, fmt.Errorf("no team found for queue ID: %s", queueID)
 }
 
-func checkNumberSuccessToday(db *sqlx.DB, number string, recall *bool) (success bool, err error) {
+func checkNumberSuccessToday(db *sqlx.DB, number string, recall *bool, minTalkSec *int) (success bool, err error) {
 	if recall != nil && *recall {
 		_, err := db.Exec("UPDATE caf.numbers SET today_success_call = $1 WHERE number = $2", false, number)
 		if err != nil {
@@ -116,7 +117,22 @@ func checkNumberSuccessToday(db *sqlx.DB, number string, recall *bool) (success
 	}
 
 	// Проверяем наличие номера с today_success_call = true
-	err = db.Get(&success, "SELECT EXISTS (SELECT 1 FROM caf.numbers WHERE today_success_call = $1 AND number = $2)", true, number)
+	query := "SELECT EXISTS (SELECT 1 FROM caf.numbers WHERE today_success_call = $1 AND number = $2)"
+	args := []interface{}{true, number}
+
+	// Если задана минимальная длительность, учитываем только достаточно долгие звонки за сегодня из истории.
+	// У звонков, сохранённых до появления длительности, она считается минимально успешной (7 сек)
+	if minTalkSec != nil {
+		query = `SELECT EXISTS (
+			SELECT 1 FROM caf.numbers AS n
+			INNER JOIN caf.calls AS c ON c.num_id = n.id
+			WHERE n.today_success_call = $1 AND n.number = $2
+			AND c.success = TRUE AND c.created_at >= CURRENT_DATE AND COALESCE(c.talk_sec, 7) >= $3
+		)`
+		args = append(args, *minTalkSec)
+	}
+
+	err = db.Get(&success, query, args...)
 	if err != nil {
 		return false, fmt.Errorf("failed to check number exists: %w", err)
 	}
@@ -156,28 +172,27 @@ func checkNumberByClientID(db *sqlx.DB, number string, clientID *string) (succes
 	return false, nil
 }
 
-func checkNumberSuccessWeek(db *sqlx.DB, number string) (bool, error) {
-	var success bool
+// Проверка, что на номер за окно уже было maxSuccesses успешных звонков по истории caf.calls.
+// Без windowDays окно - текущая неделя
+func checkNumberSuccessWindow(db *sqlx.DB, number string, windowDays int, maxSuccesses int, minTalkSec *int) (bool, error) {
+	minTalk := 0
+	if minTalkSec != nil {
+		minTalk = *minTalkSec
+	}
 
-	// Выполняем запрос для проверки существования номера с успешными вызовами за 7 дней
 	query :=
-		`SELECT EXISTS (
-			SELECT 1 
-			FROM caf.numbers 
-			WHERE first_success_call_at IS NOT NULL 
-			AND second_success_call_at IS NOT NULL 
-			AND number = $1
-			AND first_success_call_at >= DATE_TRUNC('week', CURRENT_DATE)
-			AND first_success_call_at < DATE_TRUNC('week', CURRENT_DATE) + INTERVAL '1 week'
-		)`
+		`SELECT COUNT(*) FROM caf.calls AS c
+		INNER JOIN caf.numbers AS n ON c.num_id = n.id
+		WHERE n.number = $1 AND c.success = TRUE AND COALESCE(c.talk_sec, 7) >= $3
+		AND c.created_at >= CASE WHEN $2::int4 > 0 THEN now() - make_interval(days => $2::int4) ELSE DATE_TRUNC('week', CURRENT_DATE) END`
 
-	// Выполняем запрос и проверяем наличие ошибок
-	err := db.Get(&success, query, number)
+	var successes int
+	err := db.Get(&successes, query, number, windowDays, minTalk)
 	if err != nil {
-		return false, fmt.Errorf("failed to check if number exists: %w", err)
+		return false, fmt.Errorf("failed to count success calls: %w", err)
 	}
 
-	return success, nil
+	return successes >= maxSuccesses, nil
 }
 
 func checkNumberDuoble(db *sqlx.DB, number string, QueueID string) (bool, error) {
@@ -225,103 +240,58 @@ func checkNumberDuoble(db *sqlx.DB, number string, QueueID string) (bool, error)
 }
 
 func addLog(db *sqlx.DB, teamID int, number string, description string, filtered bool) error {
-	var numID int64
-	err := db.Get(&numID, "SELECT id FROM caf.numbers WHERE number = $1", number)
-	if err != nil {
-		return fmt.Errorf("failed to get number ID: %w", err)
-	}
-
-	_, err = db.Exec("INSERT INTO caf.logs (created_at, team_id, num_id, number, description, filtered) VALUES ($1, $2, $3, $4, $5, $6)", time.Now(), teamID, numID, number, description, filtered)
-	if err != nil {
-		return fmt.Errorf("failed to add log for number %s: %w", number, err)
-	}
-	return nil
+	return addRuleLog(db, teamID, number, description, filtered, nil)
 }
 
-func checkMember(db *sqlx.DB, queueID string, number string, recall *bool, clientID *string) (string, bool, error) {
-	// Проверяем заблокирован ли номер
-	numberBlocked, err := checkMemberInBlock(db, number)
-	if err != nil {
-		return "", false, fmt.Errorf("failed to check number %s for blocking: %w", number, err)
-	}
-	if numberBlocked {
-		return "Номер в чёрном списке", true, nil
-	}
-
-	// Проверяем, что такого жу client_id нет у другого номера в БД загруженного сегодня
-	clientIDAlready, err := checkNumberByClientID(db, number, clientID)
-	if err != nil {
-		return "", false, fmt.Errorf("failed to check client_id for number %s: %w", number, err)
-	}
-	if clientIDAlready {
-		return "Такой client_id сегодня уже был загружен с другим номером и по нему был успешный дозвон", true, nil
+// Запись в лог с кодом сработавшего правила и его действием
+func addRuleLog(db *sqlx.DB, teamID int, number string, description string, filtered bool, verdict *model.FilterVerdict) error {
+	// Номер мог ещё ни разу не загружаться, тогда запись в лог делается без num_id
+	var numID *int64
+	err := db.Get(&numID, "SELECT id FROM caf.numbers WHERE number = $1 LIMIT 1", number)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get number ID: %w", err)
 	}
 
-	// Проверяем, что сегодня успешных звонков не было
-	todaySuccess, err := checkNumberSuccessToday(db, number, recall)
-	if err != nil {
-		return "", false, fmt.Errorf("failed to check today success call for number %s: %w", number, err)
-	}
-	if todaySuccess {
-		return "Сегодня уже был успешный вызов на этот номер", true, nil
+	var rule, action *string
+	var details model.LogDetails
+	if verdict != nil {
+		rule = &verdict.Rule
+		action = &verdict.Action
+		details = verdict.Details
 	}
 
-	// Проверяем, что за последние 7 дней на номер уже было 2 успешных звонка
-	weekSuccess, err := checkNumberSuccessWeek(db, number)
+	_, err = db.Exec(`INSERT INTO caf.logs (created_at, team_id, num_id, number, description, filtered, "rule", "action", details) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, time.Now(), teamID, numID, number, description, filtered, rule, action, details)
 	if err != nil {
-		return "", false, fmt.Errorf("failed to check week success call for number %s: %w", number, err)
-	}
-	if weekSuccess {
-		return "На этот номер уже было 2 успешных звонка за последние 7 дней", true, nil
+		return fmt.Errorf("failed to add log for number %s: %w", number, err)
 	}
+	return nil
+}
 
-	// Проверяем дату последней загрузки и если она сегодняшняя,
-	// то проверяем есть ли member_id из предыдущей загрузки в очереди Webitel
-	duoble, err := checkNumberDuoble(db, number, queueID)
-	if err != nil {
-		return "", false, fmt.Errorf("failed to check double for number %s: %w", number, err)
-	}
-	if duoble {
-		return "Сегодня номер уже был добавлен", true, nil
+// Проверка мембера по правилам команды. Правила проверяются по порядку, log и delay не останавливают проверку,
+// возвращаются все сработавшие правила, последним может быть только reject
+func checkMember(db *sqlx.DB, rules model.FilterRules, teamID int, queueID string, number string, recall *bool, clientID *string) ([]*model.FilterVerdict, error) {
+	var verdicts []*model.FilterVerdict
+	for _, rule := range rules {
+		message, err := checkRule(db, rule, teamID, queueID, number, recall, clientID)
+		if err != nil {
+			return nil, err
+		}
+		if message != "" {
+			verdict := ruleVerdict(rule)
+			verdicts = append(verdicts, verdict)
+			if verdict.Action == actionReject {
+				break
+			}
+		}
 	}
 
-	return "", false, nil
+	return verdicts, nil
 }
 
-// Add members godoc
-// @Summary      Add members
-// @Description  Add members
-// @Tags         Members
-// @Accept       json
-// @Produce      json
-// @Param        id   path      int  true  "Queue ID"
-// @Success      200  {array}   model.SwaggerDefaultResponse
-// @Router       /{id}/members [post]
-// @Security ApiKeyAuth
-func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
-	id := c.Param("id")
-	CheckIDAsInt(id, c)
-
-	var members model.Members
-
-	memberName := "Unknown" // Значение по умолчанию
-
-	// Чтение данных из тела запроса
-	err := c.ShouldBindJSON(&members)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"id":     "mfdc.caf.api.request.error",
-			"status": "Bad Request",
-			"code":   400,
-			"detail": "Failed to parse JSON for number: " + memberName,
-		})
-		return
-	}
-
-	if members.Communications == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"id": "mfdc.caf.api.request.error", "status": "Bad Request", "code": 400, "detail": "Param Communications not found"})
-		return
-	}
+// Имя мембера, тело запроса в Webitel и переменные recall и client_id.
+// Communications должен содержать хотя бы один элемент
+func prepareMember(members model.Members) (memberName string, body map[string]interface{}, reCall *bool, clientID *string) {
+	memberName = "Unknown" // Значение по умолчанию
 
 	// Получаем номер, если он присутствует
 	if members.Name != nil {
@@ -335,8 +305,6 @@ func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
 		}
 	}
 
-	url := fmt.Sprintf("%s/call_center/queues/%s/members", config.API_Webitel.URL, id)
-
 	Variables := map[string]string{}
 	if members.Variables != nil {
 		Variables = *members.Variables
@@ -350,7 +318,7 @@ func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
 		}
 	}
 
-	body := map[string]interface{}{
+	body = map[string]interface{}{
 		"communications": []map[string]interface{}{
 			{
 				"destination": (*members.Communications)[0].Destination,
@@ -367,8 +335,6 @@ func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
 	}
 
 	// Проверяем требуется ли повторная загрузка мембера
-	var reCall *bool
-
 	// Инициализация переменной reCall
 	if recallValue, exists := Variables["recall"]; exists {
 		// Если значение "recall" равно "yes", создаем переменную и присваиваем ей true
@@ -384,21 +350,79 @@ func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
 	}
 
 	// Проверяем наличие переменной client_id
-	var clientID *string
 	if clientIDValue, existsCID := Variables["client_id"]; existsCID {
 		// Если переменная существует, создаем указатель на её значение
 		clientID = &clientIDValue
 	}
 
-	var filterMessage string
-	isFiltered := false
+	return memberName, body, reCall, clientID
+}
 
-	if reCall != nil && *reCall {
-		// Если reCall yes, то фильтрация не требуется
-		filterMessage = ""
-	} else {
+// Команда очереди и её правила фильтрации.
+// Если команда не найдена, правила только пишут в лог, как и без фильтрации
+func getQueueFilterRules(db *sqlx.DB, queueID string) (int, model.FilterRules, error) {
+	teamID, filterMode, err := getTeamIDByQueueID(db, queueID)
+	if err != nil {
+		ErrLog.Printf("Failed to get team ID: %s", err)
+		return 0, defaultFilterRules(false), nil
+	}
+
+	rules, err := getTeamFilterRules(db, teamID, filterMode)
+	if err != nil {
+		return 0, nil, err
+	}
+	return teamID, rules, nil
+}
+
+// Add members godoc
+// @Summary      Add members
+// @Description  Add members
+// @Tags         Members
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Queue ID"
+// @Success      200  {array}   model.SwaggerDefaultResponse
+// @Router       /{id}/members [post]
+// @Security ApiKeyAuth
+func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
+	id := c.Param("id")
+	CheckIDAsInt(id, c)
+
+	var members model.Members
+
+	// Чтение данных из тела запроса
+	err := c.ShouldBindJSON(&members)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"id":     "mfdc.caf.api.request.error",
+			"status": "Bad Request",
+			"code":   400,
+			"detail": "Failed to parse JSON for number: Unknown",
+		})
+		return
+	}
+
+	if members.Communications == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"id": "mfdc.caf.api.request.error", "status": "Bad Request", "code": 400, "detail": "Param Communications not found"})
+		return
+	}
+
+	url := fmt.Sprintf("%s/call_center/queues/%s/members", config.API_Webitel.URL, id)
+
+	memberName, body, reCall, clientID := prepareMember(members)
+
+	// Получаем Team ID по Queue ID и правила команды
+	teamID, rules, err := getQueueFilterRules(db, id)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"id": "mfdc.caf.api.request.error", "status": "Bad Gateway", "code": 500, "detail": "Failed to get filter rules: " + memberName + " Info: " + err.Error()})
+		return
+	}
+
+	// Если reCall yes, то фильтрация не требуется
+	var verdicts []*model.FilterVerdict
+	if reCall == nil || !*reCall {
 		// Выполняем проверку контакта
-		filterMsg, filtered, err := checkMember(db, id, memberName, reCall, clientID)
+		verdicts, err = checkMember(db, rules, teamID, id, memberName, reCall, clientID)
 		if err != nil {
 			c.JSON(http.StatusBadGateway, gin.H{
 				"id":     "mfdc.caf.api.request.error",
@@ -408,34 +432,28 @@ func ReceiveMembers(db *sqlx.DB, c *gin.Context) {
 			})
 			return
 		}
-		filterMessage = filterMsg
-		isFiltered = filtered
 	}
 
-	// Получаем Team ID по Queue ID
-	teamID, filterMode, err := getTeamIDByQueueID(db, id)
-	if err != nil {
-		ErrLog.Printf("Failed to get team ID: %s", err)
+	// Записываем в лог все сработавшие правила, reject помечаем как отфильтрованный
+	for _, fired := range verdicts {
+		err := addRuleLog(db, teamID, memberName, fired.Message, fired.Action == actionReject, fired)
+		if err != nil {
+			ErrLog.Printf("Failed save to log: %s", err)
+		}
 	}
 
-	if filterMode {
-		// Если мембер был отфитрован то не отправляем его в Webitel
-		if isFiltered {
-			// Записываем в лог событие почему был отфильтрован номер
-			err := addLog(db, teamID, memberName, filterMessage, true)
-			if err != nil {
-				ErrLog.Printf("Failed save to log: %s", err)
-			}
-			c.JSON(http.StatusBadRequest, gin.H{"id": "mfdc.caf.api.request.error", "status": "Bad Request", "code": 400, "detail": filterMessage})
+	if verdict := decisiveVerdict(verdicts); verdict != nil {
+		// Сообщаем, какое правило сработало
+		c.Header("X-CAF-Rule", verdict.Rule)
+
+		switch verdict.Action {
+		case actionReject:
+			// Если мембер был отфитрован то не отправляем его в Webitel
+			c.JSON(http.StatusBadRequest, gin.H{"id": "mfdc.caf.api.request.error", "status": "Bad Request", "code": 400, "detail": verdict.Message, "rule": verdict.Rule})
 			return
-		}
-	} else { // Записываем в лог для отправки списка отфильтрованных номеров
-		if isFiltered {
-			// Записываем в лог событие почему был отфильтрован номер
-			err := addLog(db, teamID, memberName, filterMessage, false)
-			if err != nil {
-				ErrLog.Printf("Failed save to log: %s", err)
-			}
+		case actionDelay:
+			// Звонок откладываем
+			body["min_offering_at"] = delayedOffering(verdict.Delay)
 		}
 	}
 
@@ -0,0 +1,638 @@
+package function
+
+import (
+	"bytes"
+	"caf/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jmoiron/sqlx"
+)
+
+// Типы каналов уведомлений
+const (
+	notifySMTP     = "smtp"
+	notifyWebhook  = "webhook"
+	notifyTelegram = "telegram"
+	notifyLocal    = "local" // Сводки остаются в памяти сервиса, для проверки доставки без внешних систем
+)
+
+const (
+	defaultNotifyIntervalMin = 60
+	defaultNotifyTimeoutSec  = 15
+	defaultTelegramURL       = "https://api.telegram.org"
+	telegramMaxText          = 4000       // Telegram принимает до 4096 символов, оставляем запас
+	notifyOutboxSize         = 100        // Сколько последних сводок хранит канал local
+	notifySecretMask         = "********" // Замена bot_token и заголовков webhook в ответах API
+)
+
+// Стандартные шаблоны сводки
+const (
+	defaultNotifySubject = "MFDC filter info"
+
+	defaultNotifyHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<title>Filtered contacts</title>
+</head>
+<body>
+	<h1>Отфильтрованные контакты "{{.TeamName}}" за период<br>
+	{{.FromDate}} - {{.ToDate}}
+	</h1>
+	<table border="1">
+		<tr>
+			<th>Дата</th>
+			<th>Номер</th>
+			<th>Описание</th>
+		</tr>
+		{{range .Logs}}
+		<tr>
+			<td>{{formatTime .CreatedAt}}</td>
+			<td>{{.Number}}</td>
+			<td>{{.Description}}</td>
+		</tr>
+		{{end}}
+	</table>
+</body>
+</html>`
+
+	defaultNotifyText = `Отфильтрованные контакты "{{.TeamName}}" за период {{.FromDate}} - {{.ToDate}}
+{{range .Logs}}
+{{formatTime .CreatedAt}} {{.Number}}{{with .Description}} {{.}}{{end}}{{end}}`
+)
+
+// Сводки, доставленные в канал local
+var (
+	notifyOutboxMu sync.Mutex
+	notifyOutbox   []model.NotifyMessage
+)
+
+// Канал доставки сводки по команде
+type Notifier interface {
+	Notify(message model.NotifyMessage) error
+}
+
+// Письмо через SMTP из config.MAIL
+type smtpNotifier struct {
+	to []string
+}
+
+func (n smtpNotifier) Notify(message model.NotifyMessage) error {
+	return SendEmail(n.to, message.Subject, message.HTML, "html")
+}
+
+// JSON POST сводки на произвольный адрес
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+}
+
+func (n webhookNotifier) Notify(message model.NotifyMessage) error {
+	return postNotify(n.url, n.headers, message)
+}
+
+// Сообщение через Telegram Bot API, длинная сводка делится на несколько сообщений
+type telegramNotifier struct {
+	botToken string
+	chatID   string
+}
+
+func (n telegramNotifier) Notify(message model.NotifyMessage) error {
+	baseURL := config.Notify.TelegramURL
+	if baseURL == "" {
+		baseURL = defaultTelegramURL
+	}
+	sendURL := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(baseURL, "/"), n.botToken)
+
+	for _, text := range splitNotifyText(message.Text, telegramMaxText) {
+		err := postNotify(sendURL, nil, map[string]string{"chat_id": n.chatID, "text": text})
+		if err != nil {
+			// Токен бота не должен попасть в логи вместе с адресом
+			return fmt.Errorf("failed to send telegram message: %s", strings.ReplaceAll(err.Error(), n.botToken, "***"))
+		}
+	}
+	return nil
+}
+
+// Тестовый канал: сводка сохраняется в памяти и доступна через /notify/outbox
+type localNotifier struct {
+	name string
+}
+
+func (n localNotifier) Notify(message model.NotifyMessage) error {
+	message.Channel = n.name
+
+	notifyOutboxMu.Lock()
+	defer notifyOutboxMu.Unlock()
+
+	notifyOutbox = append(notifyOutbox, message)
+	if len(notifyOutbox) > notifyOutboxSize {
+		notifyOutbox = notifyOutbox[len(notifyOutbox)-notifyOutboxSize:]
+	}
+	return nil
+}
+
+// Отправка JSON во внешний сервис уведомлений
+func postNotify(url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-From-Service", "MFDC")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	timeoutSec := config.Notify.TimeoutSec
+	if timeoutSec <= 0 {
+		timeoutSec = defaultNotifyTimeoutSec
+	}
+	client := &http.Client{Timeout: time.Duration(timeoutSec) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 || resp.StatusCode < 200 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, responseBody)
+	}
+	return nil
+}
+
+// Деление текста на части не длиннее limit символов, по возможности по строкам
+func splitNotifyText(text string, limit int) []string {
+	var parts []string
+	var part strings.Builder
+	partLen := 0
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		runes := []rune(line)
+		for len(runes) > 0 {
+			if partLen+len(runes) <= limit {
+				part.WriteString(string(runes))
+				partLen += len(runes)
+				break
+			}
+			if partLen == 0 {
+				// Строка длиннее лимита, режем её
+				part.WriteString(string(runes[:limit]))
+				runes = runes[limit:]
+				partLen = limit
+			}
+			parts = append(parts, part.String())
+			part.Reset()
+			partLen = 0
+		}
+	}
+	if partLen > 0 {
+		parts = append(parts, part.String())
+	}
+	return parts
+}
+
+// Адреса из email команды через запятую, без него - адрес отправителя
+func notifyEmails(email *string) []string {
+	value := config.MAIL.AuthUser
+	if email != nil && *email != "" {
+		value = *email
+	}
+
+	var emails []string
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			emails = append(emails, address)
+		}
+	}
+	return emails
+}
+
+// Имя канала для логов и ответов API
+func notifyChannelName(channel model.NotifyChannel) string {
+	if channel.Name != "" {
+		return channel.Name
+	}
+	return channel.Type
+}
+
+// Создание канала доставки по настройкам команды
+func newNotifier(channel model.NotifyChannel, email *string) (Notifier, error) {
+	switch channel.Type {
+	case notifySMTP:
+		to := channel.To
+		if len(to) == 0 {
+			to = notifyEmails(email)
+		}
+		if len(to) == 0 {
+			return nil, fmt.Errorf("no email recipients")
+		}
+		return smtpNotifier{to: to}, nil
+	case notifyWebhook:
+		return webhookNotifier{url: channel.URL, headers: channel.Headers}, nil
+	case notifyTelegram:
+		return telegramNotifier{botToken: channel.BotToken, chatID: channel.ChatID}, nil
+	case notifyLocal:
+		return localNotifier{name: notifyChannelName(channel)}, nil
+	}
+	return nil, fmt.Errorf("unknown notify channel type '%s'", channel.Type)
+}
+
+// Каналы команды, без настроек - письмо на email команды, как раньше
+func teamNotifyChannels(team model.TeamDB) []model.NotifyChannel {
+	if team.NotifyChannels == nil || len(*team.NotifyChannels) == 0 {
+		return []model.NotifyChannel{{Type: notifySMTP}}
+	}
+
+	var channels []model.NotifyChannel
+	for _, channel := range *team.NotifyChannels {
+		if channel.Enabled == nil || *channel.Enabled {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// Проверка каналов уведомлений команды
+func validateNotifyChannels(channels model.NotifyChannels) error {
+	for idx, channel := range channels {
+		switch channel.Type {
+		case notifySMTP, notifyLocal:
+		case notifyWebhook:
+			if !strings.HasPrefix(channel.URL, "http://") && !strings.HasPrefix(channel.URL, "https://") {
+				return fmt.Errorf("channel %d: webhook requires http(s) url", idx+1)
+			}
+		case notifyTelegram:
+			if channel.BotToken == "" || channel.ChatID == "" {
+				return fmt.Errorf("channel %d: telegram requires bot_token and chat_id", idx+1)
+			}
+		default:
+			return fmt.Errorf("channel %d: unknown type '%s', available: smtp, webhook, telegram, local", idx+1, channel.Type)
+		}
+	}
+	return nil
+}
+
+// Каналы для ответа API: bot_token и значения заголовков webhook заменяются маской
+func maskNotifyChannels(channels *model.NotifyChannels) *model.NotifyChannels {
+	if channels == nil {
+		return nil
+	}
+	masked := make(model.NotifyChannels, len(*channels))
+	for idx, channel := range *channels {
+		if channel.BotToken != "" {
+			channel.BotToken = notifySecretMask
+		}
+		if channel.Headers != nil {
+			headers := make(map[string]string, len(channel.Headers))
+			for key := range channel.Headers {
+				headers[key] = notifySecretMask
+			}
+			channel.Headers = headers
+		}
+		masked[idx] = channel
+	}
+	return &masked
+}
+
+// Возврат сохранённых секретов вместо маски из запроса. Канал сопоставляется с сохранённым по позиции и типу
+func restoreNotifySecrets(channels *model.NotifyChannels, stored *model.NotifyChannels) error {
+	if channels == nil {
+		return nil
+	}
+	for idx := range *channels {
+		channel := &(*channels)[idx]
+		var previous *model.NotifyChannel
+		if stored != nil && idx < len(*stored) && (*stored)[idx].Type == channel.Type {
+			previous = &(*stored)[idx]
+		}
+
+		if channel.BotToken == notifySecretMask {
+			if previous == nil || previous.BotToken == "" {
+				return fmt.Errorf("channel %d: masked bot_token has no stored value", idx+1)
+			}
+			channel.BotToken = previous.BotToken
+		}
+		for key, value := range channel.Headers {
+			if value != notifySecretMask {
+				continue
+			}
+			storedValue, ok := "", false
+			if previous != nil {
+				storedValue, ok = previous.Headers[key]
+			}
+			if !ok {
+				return fmt.Errorf("channel %d: masked header '%s' has no stored value", idx+1, key)
+			}
+			channel.Headers[key] = storedValue
+		}
+	}
+	return nil
+}
+
+// Проверка настроек уведомлений из запроса команды
+func checkTeamNotify(team model.Team) error {
+	if team.NotifyChannels != nil {
+		if err := validateNotifyChannels(*team.NotifyChannels); err != nil {
+			return err
+		}
+	}
+	if team.NotifyIntervalMin != nil && *team.NotifyIntervalMin < 1 {
+		return fmt.Errorf("notify_interval_min must be at least 1")
+	}
+	if team.NotifyTemplates != nil {
+		if err := validateNotifyTemplates(*team.NotifyTemplates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Шаблон с функциями, доступными в сводке
+func parseNotifyTemplate(name string, custom *string, standard string) (*template.Template, error) {
+	text := standard
+	if custom != nil && strings.TrimSpace(*custom) != "" {
+		text = *custom
+	}
+	return template.New(name).Funcs(template.FuncMap{
+		"formatTime": formatTime,
+	}).Parse(text)
+}
+
+// Сборка сводки по шаблонам команды
+func renderNotify(templates *model.NotifyTemplates, data model.EmailData) (model.NotifyMessage, error) {
+	var custom model.NotifyTemplates
+	if templates != nil {
+		custom = *templates
+	}
+
+	message := model.NotifyMessage{
+		TeamName: data.TeamName,
+		FromDate: data.FromDate,
+		ToDate:   data.ToDate,
+		Logs:     data.Logs,
+	}
+
+	parts := []struct {
+		name     string
+		custom   *string
+		standard string
+		result   *string
+	}{
+		{"subject", custom.Subject, defaultNotifySubject, &message.Subject},
+		{"html", custom.HTML, defaultNotifyHTML, &message.HTML},
+		{"text", custom.Text, defaultNotifyText, &message.Text},
+	}
+	for _, part := range parts {
+		t, err := parseNotifyTemplate(part.name, part.custom, part.standard)
+		if err != nil {
+			return message, fmt.Errorf("failed to parse %s template: %w", part.name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return message, fmt.Errorf("failed to execute %s template: %w", part.name, err)
+		}
+		*part.result = buf.String()
+	}
+	message.Subject = strings.TrimSpace(message.Subject)
+
+	return message, nil
+}
+
+// Проверка шаблонов на примере сводки, чтобы ошибка не всплыла при отправке
+func validateNotifyTemplates(templates model.NotifyTemplates) error {
+	now := time.Now()
+	number := "79990000000"
+	description := "Example"
+	_, err := renderNotify(&templates, model.EmailData{
+		Logs:     []model.Logs{{CreatedAt: &now, Number: &number, Description: &description}},
+		TeamName: "Example",
+		FromDate: formatTime(now.Add(-1 * time.Hour)),
+		ToDate:   formatTime(now),
+	})
+	return err
+}
+
+// Ключ канала для учёта доставки: имя канала или тип с адресом получателя
+func notifyChannelKey(channel model.NotifyChannel) string {
+	if channel.Name != "" {
+		return "name:" + channel.Name
+	}
+	switch channel.Type {
+	case notifySMTP:
+		return notifySMTP + ":" + strings.Join(channel.To, ",")
+	case notifyWebhook:
+		return notifyWebhook + ":" + channel.URL
+	case notifyTelegram:
+		return notifyTelegram + ":" + channel.ChatID
+	}
+	return channel.Type
+}
+
+// Отправка сводки в один канал команды
+func sendNotifyChannel(team model.TeamDB, channel model.NotifyChannel, message model.NotifyMessage) model.NotifyResult {
+	result := model.NotifyResult{Channel: notifyChannelName(channel), Status: "success"}
+
+	notifier, err := newNotifier(channel, team.EMail)
+	if err == nil {
+		err = notifier.Notify(message)
+	}
+	if err != nil {
+		errText := err.Error()
+		result.Status = "failed"
+		result.Error = &errText
+	}
+	return result
+}
+
+// Отправка сводки во все каналы команды
+func sendTeamNotify(team model.TeamDB, message model.NotifyMessage) []model.NotifyResult {
+	var results []model.NotifyResult
+	for _, channel := range teamNotifyChannels(team) {
+		results = append(results, sendNotifyChannel(team, channel, message))
+	}
+	return results
+}
+
+// Запись уже доставлена в канал
+func logNotified(log model.Logs, key string) bool {
+	if log.NotifiedChannels == nil {
+		return false
+	}
+	for _, channel := range PgTextArr2StrArr(*log.NotifiedChannels) {
+		if channel == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Сводка по команде за период
+func teamNotifyMessage(team model.TeamDB, logs []model.Logs, fromDate time.Time, toDate time.Time) (model.NotifyMessage, error) {
+	teamName := "Unknown"
+	if team.Name != nil {
+		teamName = *team.Name
+	}
+
+	message, err := renderNotify(team.NotifyTemplates, model.EmailData{
+		Logs:     logs,
+		TeamName: teamName,
+		FromDate: formatTime(fromDate),
+		ToDate:   formatTime(toDate),
+	})
+	if err != nil {
+		return message, err
+	}
+	message.TeamID = *team.ID
+	message.SentAt = time.Now()
+	return message, nil
+}
+
+// Период сводки команды
+func teamNotifyInterval(team model.TeamDB) time.Duration {
+	intervalMin := defaultNotifyIntervalMin
+	if team.NotifyIntervalMin != nil && *team.NotifyIntervalMin > 0 {
+		intervalMin = *team.NotifyIntervalMin
+	}
+	return time.Duration(intervalMin) * time.Minute
+}
+
+// Сводка по номерам, которые правила пропустили с записью в лог. Каждая команда получает её
+// в свои каналы раз в notify_interval_min, за период с прошлой сводки. Доставка учитывается по каждому каналу:
+// записи, которые не принял канал, остаются неотправленными и уходят в него со следующей сводкой
+func FilteredNotify(db *sqlx.DB) error {
+
+	// Команды без своих каналов получают письмо, только если не фильтруют номера, как раньше
+	var teams []model.TeamDB
+	err := db.Select(&teams, "SELECT * FROM caf.teams WHERE active = $1 AND (filtration = $2 OR notify_channels IS NOT NULL)", true, false)
+	if err != nil {
+		return fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	loc, err := time.LoadLocation(config.API.TimeZone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone: %s", err)
+	}
+
+	toDate := time.Now().In(loc)
+
+	for _, team := range teams {
+		interval := teamNotifyInterval(team)
+		if team.NotifiedAt != nil && toDate.Before(team.NotifiedAt.Add(interval)) {
+			continue
+		}
+		channels := teamNotifyChannels(team)
+		if len(channels) == 0 {
+			continue
+		}
+
+		fromDate := toDate.Add(-interval)
+		if team.NotifiedAt != nil {
+			fromDate = team.NotifiedAt.In(loc)
+		}
+
+		// Записи за период и записи прошлых сводок, которые приняли не все каналы
+		var logs []model.Logs
+		err = db.Select(&logs, `SELECT * FROM caf.logs WHERE team_id = $1 AND filtered = $2 AND sent = $3 AND created_at <= $5
+			AND (created_at >= $4 OR notified_channels IS NOT NULL) ORDER BY created_at`,
+			team.ID, false, false, fromDate, toDate)
+		if err != nil {
+			return fmt.Errorf("failed to get logs: %s", err)
+		}
+
+		delivered := len(logs) == 0
+		if len(logs) != 0 {
+			for i := range logs { // Используем индекс для изменения исходного среза
+				log := &logs[i] // Получаем указатель на текущий лог
+				if log.CreatedAt != nil {
+					CreatedAtTz := log.CreatedAt.In(loc) // Применяем временную зону
+					log.CreatedAt = &CreatedAtTz         // Обновляем указатель на новое время
+				}
+			}
+
+			OutLog.Printf("Sending filtered notification for team %d, records: %d", *team.ID, len(logs))
+
+			keys := make([]string, len(channels))
+			for idx, channel := range channels {
+				keys[idx] = notifyChannelKey(channel)
+
+				// Каждый канал получает только записи, которые ещё не принимал
+				var channelLogs []model.Logs
+				var ids []int64
+				for _, log := range logs {
+					if !logNotified(log, keys[idx]) {
+						channelLogs = append(channelLogs, log)
+						ids = append(ids, log.ID)
+					}
+				}
+				if len(channelLogs) == 0 {
+					continue
+				}
+
+				message, err := teamNotifyMessage(team, channelLogs, fromDate, toDate)
+				if err != nil {
+					ErrLog.Printf("Failed to build notification for team %d: %s", *team.ID, err)
+					break
+				}
+
+				result := sendNotifyChannel(team, channel, message)
+				if result.Error != nil {
+					ErrLog.Printf("Failed to send notification for team %d to %s: %s", *team.ID, result.Channel, *result.Error)
+					continue
+				}
+				delivered = true
+
+				var logIDs pgtype.Int8Array
+				if err := logIDs.Set(ids); err != nil {
+					return fmt.Errorf("failed to prepare log ids: %w", err)
+				}
+				_, err = db.Exec("UPDATE caf.logs SET notified_channels = array_append(COALESCE(notified_channels, '{}'), $1) WHERE id = ANY($2)", keys[idx], logIDs)
+				if err != nil {
+					return fmt.Errorf("failed to update logs for notified channel: %w", err)
+				}
+			}
+
+			// Отправленными считаются записи, которые приняли все включённые каналы
+			ids := make([]int64, len(logs))
+			for i := range logs {
+				ids[i] = logs[i].ID
+			}
+			var logIDs pgtype.Int8Array
+			if err := logIDs.Set(ids); err != nil {
+				return fmt.Errorf("failed to prepare log ids: %w", err)
+			}
+			_, err = db.Exec("UPDATE caf.logs SET sent = $1 WHERE id = ANY($2) AND notified_channels @> $3", true, logIDs, StrArr2PgTextArr(keys))
+			if err != nil {
+				return fmt.Errorf("failed to update logs for sent status: %w", err)
+			}
+		}
+
+		// Если сводку не принял ни один канал, период не сдвигаем и повторим на следующем запуске
+		if !delivered {
+			continue
+		}
+
+		_, err = db.Exec("UPDATE caf.teams SET notified_at = $1 WHERE id = $2", toDate, team.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update team notified_at: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -219,16 +219,14 @@ func StartRecheckNumberForBlockByUnsuccessful(db *sqlx.DB, ctx context.Context)
 	}
 }
 
-// Запуск уведомления на email об отфильтрованных номерах
+// Запуск уведомлений об отфильтрованных номерах, период сводки у каждой команды свой
 func StartFilteredNotify(db *sqlx.DB, ctx context.Context) {
-	ticker := time.NewTicker(60 * time.Minute)
+	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			OutLog.Println("Start filtered notification")
-
 			filteredNotifyMu.Lock() // Блокируем мьютекс перед выполнением задачи
 			err := FilteredNotify(db)
 			filteredNotifyMu.Unlock() // Освобождаем мьютекс после завершения задачи
@@ -244,3 +242,59 @@ func StartFilteredNotify(db *sqlx.DB, ctx context.Context) {
 		}
 	}
 }
+
+// Запуск снятия блокировки с истёкших записей ЧС
+func StartBlacklistExpiry(db *sqlx.DB, ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			blacklistExpiryMu.Lock() // Блокируем мьютекс перед выполнением задачи
+			lifted, err := LiftExpiredBlacklist(db)
+			blacklistExpiryMu.Unlock() // Освобождаем мьютекс после завершения задачи
+
+			if err != nil {
+				ErrLog.Printf("Failed to lift expired blacklist: %s", err)
+			} else if lifted > 0 {
+				OutLog.Printf("Expired blacklist lifted for %d numbers", lifted)
+			}
+
+		case <-ctx.Done():
+			// Логируем завершение фоновой задачи
+			OutLog.Println("Stopping blacklist expiry...")
+			return // Завершаем выполнение функции
+		}
+	}
+}
+
+// Обработчик очереди синхронизации ЧС с Webitel и БП
+func StartSyncWorker(db *sqlx.DB, ctx context.Context) {
+	intervalSec := config.Sync.IntervalSec
+	if intervalSec <= 0 {
+		intervalSec = defaultSyncIntervalSec
+	}
+	ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			syncWorkerMu.Lock() // Блокируем мьютекс перед выполнением задачи
+			done, err := ProcessSyncJobs(db)
+			syncWorkerMu.Unlock() // Освобождаем мьютекс после завершения задачи
+
+			if err != nil {
+				ErrLog.Printf("Failed to process sync jobs: %s", err)
+			} else if done > 0 {
+				OutLog.Printf("Sync jobs done: %d", done)
+			}
+
+		case <-ctx.Done():
+			// Логируем завершение фоновой задачи
+			OutLog.Println("Stopping sync worker...")
+			return // Завершаем выполнение функции
+		}
+	}
+}
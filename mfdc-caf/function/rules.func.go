@@ -0,0 +1,266 @@
+package function
+
+import (
+	"caf/model"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Коды правил фильтрации
+const (
+	ruleBlacklist     = "blacklist"      // Номер в ЧС или заблокирован
+	ruleClientID      = "client_id"      // client_id сегодня загружен с другим номером и по нему был успешный дозвон
+	ruleTodaySuccess  = "today_success"  // Сегодня уже был успешный вызов
+	ruleSuccessWindow = "success_window" // Лимит успешных вызовов за окно
+	ruleDuplicate     = "duplicate"      // Номер сегодня уже загружен и есть в очереди Webitel
+)
+
+// Действия правил
+const (
+	actionReject = "reject" // Не отправляем мембера в Webitel
+	actionLog    = "log"    // Только пишем в лог
+	actionDelay  = "delay"  // Отправляем в Webitel с отсрочкой звонка
+)
+
+// Стандартный набор правил, который раньше был зашит в checkMember.
+// С filtration сработавшее правило отклоняет мембера, без неё только пишется в лог
+func defaultFilterRules(filtration bool) model.FilterRules {
+	action := actionLog
+	if filtration {
+		action = actionReject
+	}
+
+	codes := []string{ruleBlacklist, ruleClientID, ruleTodaySuccess, ruleSuccessWindow, ruleDuplicate}
+	rules := make(model.FilterRules, len(codes))
+	for idx, code := range codes {
+		rules[idx] = model.FilterRule{Code: code, Action: action}
+	}
+	return rules
+}
+
+// Проверка правил перед сохранением в команду
+func validateFilterRules(rules model.FilterRules) error {
+	codes := map[string]bool{}
+	for idx, rule := range rules {
+		switch rule.Code {
+		case ruleBlacklist, ruleClientID, ruleTodaySuccess, ruleSuccessWindow, ruleDuplicate:
+		default:
+			return fmt.Errorf("rule %d: unknown code '%s'", idx, rule.Code)
+		}
+		if codes[rule.Code] {
+			return fmt.Errorf("rule %d: code '%s' is duplicated", idx, rule.Code)
+		}
+		codes[rule.Code] = true
+
+		switch rule.Action {
+		case actionReject, actionLog:
+		case actionDelay:
+			if rule.Params.DelayMinutes == nil || *rule.Params.DelayMinutes <= 0 {
+				return fmt.Errorf("rule %d: action 'delay' requires positive delay_minutes", idx)
+			}
+		default:
+			return fmt.Errorf("rule %d: unknown action '%s'", idx, rule.Action)
+		}
+
+		params := rule.Params
+		if params.MinTalkSec != nil {
+			if rule.Code != ruleTodaySuccess && rule.Code != ruleSuccessWindow {
+				return fmt.Errorf("rule %d: min_talk_sec is not supported by '%s'", idx, rule.Code)
+			}
+			if *params.MinTalkSec < 0 {
+				return fmt.Errorf("rule %d: min_talk_sec can't be negative", idx)
+			}
+		}
+		if params.WindowDays != nil || params.MaxSuccesses != nil {
+			if rule.Code != ruleSuccessWindow {
+				return fmt.Errorf("rule %d: window_days and max_successes are supported only by '%s'", idx, ruleSuccessWindow)
+			}
+			if params.WindowDays != nil && *params.WindowDays < 0 {
+				return fmt.Errorf("rule %d: window_days can't be negative", idx)
+			}
+			if params.MaxSuccesses != nil && *params.MaxSuccesses < 1 {
+				return fmt.Errorf("rule %d: max_successes must be positive", idx)
+			}
+		}
+	}
+	return nil
+}
+
+// Правила команды, если они не заданы - стандартный набор
+func getTeamFilterRules(db *sqlx.DB, teamID int, filtration bool) (model.FilterRules, error) {
+	var rules model.FilterRules
+	err := db.Get(&rules, "SELECT rules FROM caf.teams WHERE id = $1", teamID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get rules for team %d: %w", teamID, err)
+	}
+	return effectiveFilterRules(rules, filtration), nil
+}
+
+// Действующие правила команды: без своих правил стандартный набор,
+// при выключенной фильтрации reject из своих правил только пишется в лог
+func effectiveFilterRules(rules model.FilterRules, filtration bool) model.FilterRules {
+	if len(rules) == 0 {
+		return defaultFilterRules(filtration)
+	}
+	if filtration {
+		return rules
+	}
+
+	effective := make(model.FilterRules, len(rules))
+	copy(effective, rules)
+	for idx := range effective {
+		if effective[idx].Action == actionReject {
+			effective[idx].Action = actionLog
+		}
+	}
+	return effective
+}
+
+// Итоговое решение по сработавшим правилам: reject важнее всего, затем самая долгая задержка, затем log
+func decisiveVerdict(verdicts []*model.FilterVerdict) *model.FilterVerdict {
+	var decisive *model.FilterVerdict
+	for _, verdict := range verdicts {
+		switch {
+		case verdict.Action == actionReject:
+			return verdict
+		case decisive == nil:
+			decisive = verdict
+		case verdict.Action == actionDelay && (decisive.Action != actionDelay || verdict.Delay > decisive.Delay):
+			decisive = verdict
+		}
+	}
+	return decisive
+}
+
+// Время, раньше которого Webitel не будет звонить мемберу, в миллисекундах
+func delayedOffering(minutes int) string {
+	minOfferingAt := time.Now().Add(time.Duration(minutes) * time.Minute).UnixMilli()
+	return strconv.FormatInt(minOfferingAt, 10)
+}
+
+// Окно в днях и лимит успешных звонков для success_window
+func successWindow(rule model.FilterRule) (int, int) {
+	windowDays := 0
+	if rule.Params.WindowDays != nil {
+		windowDays = *rule.Params.WindowDays
+	}
+	maxSuccesses := 2
+	if rule.Params.MaxSuccesses != nil {
+		maxSuccesses = *rule.Params.MaxSuccesses
+	}
+	return windowDays, maxSuccesses
+}
+
+// Описание сработавшего правила для лога и ответа
+func ruleMessage(rule model.FilterRule) string {
+	switch rule.Code {
+	case ruleBlacklist:
+		return "Номер в чёрном списке"
+	case ruleClientID:
+		return "Такой client_id сегодня уже был загружен с другим номером и по нему был успешный дозвон"
+	case ruleTodaySuccess:
+		return "Сегодня уже был успешный вызов на этот номер"
+	case ruleSuccessWindow:
+		windowDays, maxSuccesses := successWindow(rule)
+		if windowDays == 0 {
+			return fmt.Sprintf("На этот номер уже было успешных звонков за текущую неделю: %d", maxSuccesses)
+		}
+		return fmt.Sprintf("На этот номер уже было успешных звонков за последние %d дн.: %d", windowDays, maxSuccesses)
+	case ruleDuplicate:
+		return "Сегодня номер уже был добавлен"
+	}
+	return rule.Code
+}
+
+// Сработавшее правило для ответа и лога
+func ruleVerdict(rule model.FilterRule) *model.FilterVerdict {
+	verdict := model.FilterVerdict{
+		Rule:    rule.Code,
+		Action:  rule.Action,
+		Message: ruleMessage(rule),
+	}
+	if rule.Action == actionDelay && rule.Params.DelayMinutes != nil {
+		verdict.Delay = *rule.Params.DelayMinutes
+	}
+
+	// Пороги правила сохраняются в лог, чтобы решение можно было объяснить после смены правил
+	details := model.LogDetails{}
+	switch rule.Code {
+	case ruleSuccessWindow:
+		details["window_days"], details["max_successes"] = successWindow(rule)
+		if rule.Params.MinTalkSec != nil {
+			details["min_talk_sec"] = *rule.Params.MinTalkSec
+		}
+	case ruleTodaySuccess:
+		if rule.Params.MinTalkSec != nil {
+			details["min_talk_sec"] = *rule.Params.MinTalkSec
+		}
+	}
+	if verdict.Delay != 0 {
+		details["delay_minutes"] = verdict.Delay
+	}
+	if len(details) != 0 {
+		verdict.Details = details
+	}
+	return &verdict
+}
+
+// Проверка одного правила, возвращает описание для лога, если правило сработало
+func checkRule(db *sqlx.DB, rule model.FilterRule, teamID int, queueID string, number string, recall *bool, clientID *string) (string, error) {
+	switch rule.Code {
+	case ruleBlacklist:
+		// Проверяем заблокирован ли номер
+		numberBlocked, err := checkMemberInBlock(db, number, teamID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check number %s for blocking: %w", number, err)
+		}
+		if numberBlocked {
+			return ruleMessage(rule), nil
+		}
+	case ruleClientID:
+		// Проверяем, что такого жу client_id нет у другого номера в БД загруженного сегодня
+		clientIDAlready, err := checkNumberByClientID(db, number, clientID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check client_id for number %s: %w", number, err)
+		}
+		if clientIDAlready {
+			return ruleMessage(rule), nil
+		}
+	case ruleTodaySuccess:
+		// Проверяем, что сегодня успешных звонков не было
+		todaySuccess, err := checkNumberSuccessToday(db, number, recall, rule.Params.MinTalkSec)
+		if err != nil {
+			return "", fmt.Errorf("failed to check today success call for number %s: %w", number, err)
+		}
+		if todaySuccess {
+			return ruleMessage(rule), nil
+		}
+	case ruleSuccessWindow:
+		windowDays, maxSuccesses := successWindow(rule)
+
+		// Проверяем, сколько успешных звонков было на номер за окно
+		windowSuccess, err := checkNumberSuccessWindow(db, number, windowDays, maxSuccesses, rule.Params.MinTalkSec)
+		if err != nil {
+			return "", fmt.Errorf("failed to check window success call for number %s: %w", number, err)
+		}
+		if windowSuccess {
+			return ruleMessage(rule), nil
+		}
+	case ruleDuplicate:
+		// Проверяем дату последней загрузки и если она сегодняшняя,
+		// то проверяем есть ли member_id из предыдущей загрузки в очереди Webitel
+		duoble, err := checkNumberDuoble(db, number, queueID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check double for number %s: %w", number, err)
+		}
+		if duoble {
+			return ruleMessage(rule), nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,139 @@
+package function
+
+import (
+	"caf/model"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Внешние системы, в которые синхронизируется ЧС
+const (
+	syncWebitel = "webitel"
+	syncBP      = "bp"
+)
+
+// Операции синхронизации
+const (
+	syncAdd    = "add"
+	syncRemove = "remove"
+)
+
+// Состояния заданий
+const (
+	syncPending    = "pending" // Ожидает выполнения или повтора
+	syncDone       = "done"
+	syncDead       = "dead"       // Попытки исчерпаны, повтор только через API
+	syncSuperseded = "superseded" // Не выполнено и не будет повторено, есть более новое задание по номеру
+)
+
+// Значения по умолчанию для обработчика заданий
+const (
+	defaultSyncIntervalSec = 30
+	defaultSyncMaxAttempts = 8
+	defaultSyncBackoffSec  = 30
+	syncMaxBackoff         = 6 * time.Hour
+	syncBatchSize          = 100
+)
+
+// Условие для задания j: по тому же номеру в ту же систему поставлено более новое задание
+const syncNewerJobSQL = `EXISTS (SELECT 1 FROM caf.sync_jobs AS n WHERE n.target = j.target AND n."number" = j."number" AND n.id > j.id)`
+
+// Постановка задания на синхронизацию ЧС. Само обращение к внешней системе выполнит обработчик заданий
+func enqueueSync(db *sqlx.DB, target string, operation string, number string, clientID *string, description *string) error {
+	now := time.Now()
+	_, err := db.Exec(`INSERT INTO caf.sync_jobs (target, operation, "number", client_id, description, state, attempts, next_run_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, target, operation, number, clientID, description, syncPending, 0, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s %s for number %s: %w", target, operation, number, err)
+	}
+	return nil
+}
+
+// Задержка перед следующей попыткой: backoff_sec, затем удваивается
+func syncBackoff(attempts int) time.Duration {
+	backoffSec := config.Sync.BackoffSec
+	if backoffSec <= 0 {
+		backoffSec = defaultSyncBackoffSec
+	}
+
+	delay := time.Duration(backoffSec) * time.Second
+	for i := 1; i < attempts && delay < syncMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > syncMaxBackoff {
+		delay = syncMaxBackoff
+	}
+	return delay
+}
+
+// Обращение к внешней системе по заданию
+func runSyncJob(job model.SyncJob) error {
+	switch job.Target {
+	case syncWebitel:
+		if job.Operation == syncAdd {
+			description := ""
+			if job.Description != nil {
+				description = *job.Description
+			}
+			return SendNumberToWebitel(job.Number, description)
+		}
+		return RemoveNumberFromWebitel(job.Number)
+	case syncBP:
+		add := job.Operation == syncAdd
+		// ЧС БП ведётся по client_id, если он известен
+		return SendNumberToBP(job.ClientID != nil, job.Number, job.ClientID, add, add, add)
+	}
+	return fmt.Errorf("unknown sync target '%s'", job.Target)
+}
+
+// Выполнение готовых заданий. Задания по одному номеру и системе выполняются строго по порядку,
+// поэтому задание ждёт, пока не выполнятся предыдущие
+func ProcessSyncJobs(db *sqlx.DB) (int, error) {
+	maxAttempts := config.Sync.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultSyncMaxAttempts
+	}
+
+	var jobs []model.SyncJob
+	err := db.Select(&jobs, `SELECT * FROM caf.sync_jobs AS j
+		WHERE j.state = $1 AND j.next_run_at <= now()
+		AND NOT EXISTS (SELECT 1 FROM caf.sync_jobs AS p WHERE p.target = j.target AND p.number = j.number AND p.id < j.id AND p.state = $1)
+		ORDER BY j.id LIMIT $2`, syncPending, syncBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sync jobs: %w", err)
+	}
+
+	done := 0
+	for _, job := range jobs {
+		runErr := runSyncJob(job)
+		now := time.Now()
+
+		if runErr == nil {
+			_, err = db.Exec("UPDATE caf.sync_jobs SET state = $1, attempts = attempts + 1, last_error = NULL, updated_at = $2 WHERE id = $3", syncDone, now, job.ID)
+			if err != nil {
+				return done, fmt.Errorf("failed to update sync job %d: %w", job.ID, err)
+			}
+			done++
+			continue
+		}
+
+		// Ошибку сохраняем и откладываем повтор, после max_attempts задание уходит в dead
+		attempts := job.Attempts + 1
+		state := syncPending
+		nextRunAt := now.Add(syncBackoff(attempts))
+		if attempts >= maxAttempts {
+			state = syncDead
+			ErrLog.Printf("Sync job %d (%s %s %s) is dead after %d attempts: %s", job.ID, job.Target, job.Operation, job.Number, attempts, runErr)
+		}
+
+		_, err = db.Exec("UPDATE caf.sync_jobs SET state = $1, attempts = $2, next_run_at = $3, last_error = $4, updated_at = $5 WHERE id = $6",
+			state, attempts, nextRunAt, runErr.Error(), now, job.ID)
+		if err != nil {
+			return done, fmt.Errorf("failed to update sync job %d: %w", job.ID, err)
+		}
+	}
+
+	return done, nil
+}
@@ -1,6 +1,7 @@
 package function
 
 import (
+	"caf/model"
 	"database/sql"
 	"net/http"
 	"time"
@@ -17,6 +18,9 @@ import (
 // @Produce      json
 // @Param        class   path      string  true  "Type (success, try)"
 // @Param        number   path      string  true  "Number"
+// @Param        talk_sec   query      int  false  "Talk time in seconds (for success)"
+// @Param        hangup_by   query      string  false  "Who hung up the call (for success)"
+// @Param        sip_code   query      int  false  "SIP code (for success)"
 // @Success      200  {array}   model.SwaggerDefaultResponse
 // @Router       /webhooks/{type}/{number} [get]
 // @Security ApiKeyAuth
@@ -32,77 +36,142 @@ func CallHook(db *sqlx.DB, c *gin.Context) {
 	// Проверяем что присланы цифры
 	CheckIDAsInt(number, c)
 
-	var err error
-	var result sql.Result
-
-	existNumber := false
-	err = db.Get(&existNumber, "SELECT EXISTS (SELECT 1 FROM caf.numbers WHERE number = $1)", number)
+	var numberDB struct {
+		ID     int64 `db:"id"`
+		TeamID *int  `db:"team_id"`
+	}
+	err := db.Get(&numberDB, "SELECT id, team_id FROM caf.numbers WHERE number = $1 LIMIT 1", number)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Number not found"})
+			return
+		}
 		// Обработка ошибки при выполнении запроса
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to check number for exists", "error": err.Error()})
 		return
 	}
 
-	if !existNumber {
-		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Number not found"})
-		return
+	// Каждый звонок сохраняем в историю номера
+	call := model.Call{
+		NumID:  numberDB.ID,
+		Number: number,
+		TeamID: numberDB.TeamID,
 	}
 
+	var reason string
 	switch rtype {
 	case "success":
 		talk_sec := c.Query("talk_sec")
 		talkSec := CheckIDAsInt(talk_sec, c)
-		// Считаем успешным звонок только более 6 сек
-		if talkSec > 6 {
-			currentTime := time.Now()
-			/*
-				Если first_success_call_at равно NULL или меньше даты понедельника текущей недели, то ему присваивается значение $2.
-				Если first_success_call_at больше или равно дате понедельника текущей недели, то second_success_call_at присваивается значение $2
-			*/
-			query := `UPDATE caf.numbers
-						SET 
-							today_success_call = $1,
-							first_success_call_at = CASE 
-								WHEN first_success_call_at IS NULL OR first_success_call_at < date_trunc('week', CURRENT_DATE) 
-								THEN $2 
-								ELSE first_success_call_at 
-							END,
-							second_success_call_at = CASE 
-								WHEN first_success_call_at >= date_trunc('week', CURRENT_DATE) 
-								THEN $2 
-								ELSE second_success_call_at 
-							END,
-							success = $3,
-							stat_waiting = $4
-						WHERE number = $5;`
-			result, err = db.Exec(query, true, currentTime, true, false, number)
-		} else {
-			c.JSON(http.StatusNotAcceptable, gin.H{"status": "failed", "message": "Talk time less than 6 sec, it is unsuccessful"})
+		call.TalkSec = &talkSec
+		if hangupBy := c.Query("hangup_by"); hangupBy != "" {
+			call.HangupBy = &hangupBy
+		}
+		if sip_code := c.Query("sip_code"); sip_code != "" {
+			sipCode := CheckIDAsInt(sip_code, c)
+			call.SipCode = &sipCode
+		}
+
+		// Успешность звонка определяется критериями команды
+		criteria, err := getSuccessCriteria(db, numberDB.TeamID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get success criteria", "error": err.Error()})
 			return
 		}
+		call.Success, reason = checkCallSuccess(criteria, call)
 	case "try":
-		result, err = db.Exec("UPDATE caf.numbers SET attempts_counter = COALESCE(attempts_counter, 0) + 1 WHERE number = $1", number)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Param type is invalid"})
 		return
 	}
 
+	tx, err := db.Beginx()
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to begin transaction", "error": err.Error()})
+		return
+	}
+
+	err = addCallHistory(tx, &call)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to save call", "error": err.Error()})
+		return
+	}
+
+	// Неуспешный звонок только сохраняем в историю
+	if rtype == "success" && !call.Success {
+		// Подтверждаем транзакцию
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to commit transaction", "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotAcceptable, gin.H{"status": "failed", "message": reason})
+		return
+	}
+
+	var result sql.Result
+	if call.Success {
+		/*
+			Если first_success_call_at равно NULL или меньше даты понедельника текущей недели, то ему присваивается значение $2.
+			Если first_success_call_at больше или равно дате понедельника текущей недели, то second_success_call_at присваивается значение $2
+			Полная история звонков хранится в caf.calls, колонки оставлены для совместимости
+		*/
+		query := `UPDATE caf.numbers
+					SET 
+						today_success_call = $1,
+						first_success_call_at = CASE 
+							WHEN first_success_call_at IS NULL OR first_success_call_at < date_trunc('week', CURRENT_DATE) 
+							THEN $2 
+							ELSE first_success_call_at 
+						END,
+						second_success_call_at = CASE 
+							WHEN first_success_call_at >= date_trunc('week', CURRENT_DATE) 
+							THEN $2 
+							ELSE second_success_call_at 
+						END,
+						first_success_talk_sec = CASE 
+							WHEN first_success_call_at IS NULL OR first_success_call_at < date_trunc('week', CURRENT_DATE) 
+							THEN $6 
+							ELSE first_success_talk_sec 
+						END,
+						second_success_talk_sec = CASE 
+							WHEN first_success_call_at >= date_trunc('week', CURRENT_DATE) 
+							THEN $6 
+							ELSE second_success_talk_sec 
+						END,
+						success = $3,
+						stat_waiting = $4
+					WHERE number = $5;`
+		result, err = tx.Exec(query, true, *call.CreatedAt, true, false, number, call.TalkSec)
+	} else {
+		result, err = tx.Exec("UPDATE caf.numbers SET attempts_counter = COALESCE(attempts_counter, 0) + 1 WHERE number = $1", number)
+	}
+
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to update call for number", "error": err.Error()})
 		return
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to retrieve affected rows count", "error": err.Error()})
 		return
 	}
 
 	if rowsAffected == 0 {
+		tx.Rollback() // Откатываем транзакцию при ошибке
 		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Number not found"})
 		return
 	}
 
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to commit transaction", "error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Info updated for number: " + number})
 }
 
@@ -74,6 +74,16 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.TeamDelete(db.(*sqlx.DB), c)
 		})
+		teams.POST("/notify/test/:id", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.NotifyTest(db.(*sqlx.DB), c)
+		})
+	}
+
+	notify := router.Group("/notify")
+	{
+		notify.GET("/outbox", function.CheckUserAuth(), function.GetNotifyOutbox)
+		notify.DELETE("/outbox", function.CheckUserAuth(), function.ClearNotifyOutbox)
 	}
 
 	blacklist := router.Group("/blacklist")
@@ -90,6 +100,30 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.AddBL(db.(*sqlx.DB), c)
 		})
+		blacklist.GET("/export", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.BLExport(db.(*sqlx.DB), c)
+		})
+		blacklist.POST("/import", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.BLImport(db.(*sqlx.DB), c)
+		})
+	}
+
+	syncJobs := router.Group("/sync")
+	{
+		syncJobs.GET("/jobs", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.GetSyncJobs(db.(*sqlx.DB), c)
+		})
+		syncJobs.POST("/jobs/retry", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.RetryDeadSyncJobs(db.(*sqlx.DB), c)
+		})
+		syncJobs.POST("/jobs/:id/retry", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.RetrySyncJob(db.(*sqlx.DB), c)
+		})
 	}
 
 	router.POST("/:id/members", function.CheckLoop(), func(c *gin.Context) {
@@ -97,11 +131,21 @@ func main() {
 		function.ReceiveMembers(db.(*sqlx.DB), c)
 	})
 
+	router.POST("/:id/members/bulk", function.CheckUserAuth(), func(c *gin.Context) {
+		db, _ := function.CheckDB(c)
+		function.ReceiveMembersBulk(db.(*sqlx.DB), c)
+	})
+
 	router.GET("/webhooks/:type/:number", function.CheckUserAuth(), func(c *gin.Context) {
 		db, _ := function.CheckDB(c)
 		function.CallHook(db.(*sqlx.DB), c)
 	})
 
+	router.GET("/numbers/:number/explain", function.CheckUserAuth(), func(c *gin.Context) {
+		db, _ := function.CheckDB(c)
+		function.NumberExplain(db.(*sqlx.DB), c)
+	})
+
 	router.GET("/webhooks/recheck/:number", function.CheckUserAuth(), func(c *gin.Context) {
 		db, _ := function.CheckDB(c)
 		function.RecheckNumberHook(db.(*sqlx.DB), c)
@@ -182,6 +226,12 @@ func main() {
 		// Ежедневная очистка успешных звонков за сутки
 		go function.JOBClearTodaySuccessCall(db, ctx)
 
+		// Снятие блокировки с истёкших записей ЧС
+		go function.StartBlacklistExpiry(db, ctx)
+
+		// Отправка изменений ЧС в Webitel и БП из очереди синхронизации
+		go function.StartSyncWorker(db, ctx)
+
 	}
 
 	// Ожидание сигнала завершения
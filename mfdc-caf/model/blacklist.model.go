@@ -7,6 +7,8 @@ type BlockedRequest struct {
 	To_date   *string `json:"to_date,omitempty"`
 	Number    *string `json:"number,omitempty"` // Номер телефона
 	TeamID    *int    `json:"caf_team_id"`
+	Scope     *string `json:"scope,omitempty"`           // global, team
+	Category  *string `json:"reason_category,omitempty"` // Категория причины
 }
 
 type BlackList struct {
@@ -15,6 +17,9 @@ type BlackList struct {
 	Number      *string    `db:"number" json:"number,omitempty"`
 	TeamID      *int       `db:"team_id" json:"caf_team_id,omitempty"`
 	Description *string    `db:"description" json:"description,omitempty"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	Scope       *string    `db:"scope" json:"scope,omitempty"`
+	Category    *string    `db:"reason_category" json:"reason_category,omitempty"`
 	Logs        *[]BLLogs  `db:"logs" json:"logs,omitempty"`
 }
 
@@ -23,6 +28,31 @@ type BlackListAdd struct {
 	Number      *string `db:"number" json:"number,omitempty"`
 	TeamID      *int    `db:"team_id" json:"caf_team_id,omitempty"`
 	Description *string `db:"description" json:"description,omitempty"`
+	ExpiresAt   *string `json:"expires_at,omitempty" example:"2025-01-31 00:00"` // Без него блокировка бессрочная
+	Scope       *string `json:"scope,omitempty" example:"global"`                // global - для всех команд, team - только для caf_team_id
+	Category    *string `json:"reason_category,omitempty" example:"complaint"`   // complaint, fraud, do_not_call, invalid, strategy, other
+}
+
+// Результат импорта строки CSV в ЧС
+type BlackListImportRow struct {
+	Line   int     `json:"line"` // Номер строки в файле
+	Number string  `json:"number"`
+	Status string  `json:"status" example:"added"` // added, exists, error
+	ID     *int64  `json:"id,omitempty"`
+	Error  *string `json:"error,omitempty"`
+}
+
+type BlackListImport struct {
+	Total  int                  `json:"total"`
+	Added  int                  `json:"added"`
+	Exists int                  `json:"exists"`
+	Failed int                  `json:"failed"`
+	Rows   []BlackListImportRow `json:"rows"`
+}
+
+type SwaggerBlackListImport struct {
+	Status string          `json:"status"`
+	Data   BlackListImport `json:"data"`
 }
 
 type BLLogs struct {
@@ -1,11 +1,11 @@
 package model
 
 type ReasonsStat struct {
-	ID        int64  `db:"id"`
-	NumID     int64  `db:"num_id"`
-	Count     int    `db:"count"`
-	SipCode   string `db:"sip_code"`
-	SipReason string `db:"sip_reason"`
+	ID        int64  `db:"id" json:"-"`
+	NumID     int64  `db:"num_id" json:"num_id"`
+	Count     int    `db:"count" json:"count"`
+	SipCode   string `db:"sip_code" json:"sip_code"`
+	SipReason string `db:"sip_reason" json:"sip_reason"`
 }
 
 type JRPSResponse struct {
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Звонок на номер, присланный вебхуком
+type Call struct {
+	ID        int64      `db:"id" json:"id"`
+	NumID     int64      `db:"num_id" json:"num_id"`
+	Number    string     `db:"number" json:"number"`
+	TeamID    *int       `db:"team_id" json:"team_id,omitempty"`
+	CreatedAt *time.Time `db:"created_at" json:"created_at,omitempty"`
+	TalkSec   *int       `db:"talk_sec" json:"talk_sec,omitempty"`
+	HangupBy  *string    `db:"hangup_by" json:"hangup_by,omitempty"`
+	SipCode   *int       `db:"sip_code" json:"sip_code,omitempty"`
+	Success   bool       `db:"success" json:"success"`
+}
+
+// Критерии успешного звонка команды
+type SuccessCriteria struct {
+	MinTalkSec int      // Минимальная длительность разговора
+	HangupBy   []string // Кто может завершить звонок, пустой - кто угодно
+	SipCodes   []int    // Допустимые SIP коды, пустой - любые
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Запись номера по команде и очереди
+type ExplainNumber struct {
+	ID               int64      `db:"id" json:"id"`
+	ClientID         *string    `db:"client_id" json:"client_id,omitempty"`
+	QueueID          *int       `db:"queue_id" json:"queue_id,omitempty"`
+	TeamID           *int       `db:"team_id" json:"caf_team_id,omitempty"`
+	FirstLoadAt      *time.Time `db:"first_load_at" json:"first_load_at,omitempty"`
+	LastLoadAt       *time.Time `db:"last_load_at" json:"last_load_at,omitempty"`
+	LoadCounter      *int       `db:"load_counter" json:"load_counter,omitempty"`
+	AttemptsCounter  *int       `db:"attempts_counter" json:"attempts_counter,omitempty"`
+	Success          *bool      `db:"success" json:"success,omitempty"`
+	TodaySuccessCall *bool      `db:"today_success_call" json:"today_success_call,omitempty"`
+	Blocked          *bool      `db:"blocked" json:"blocked,omitempty"`
+	BlockedAt        *time.Time `db:"blocked_at" json:"blocked_at,omitempty"`
+	StopExpirid      *time.Time `db:"stop_expirid" json:"stop_expirid,omitempty"`
+	StatWaiting      *bool      `db:"stat_waiting" json:"stat_waiting,omitempty"`
+	RepeatedCheck    *bool      `db:"repeated_check" json:"repeated_check,omitempty"`
+	BlockRechecked   *bool      `db:"block_rechecked" json:"block_rechecked,omitempty"`
+}
+
+// Текущие настройки команды, по которым принимаются решения
+type ExplainTeam struct {
+	ID                  int         `json:"caf_team_id"`
+	Name                *string     `json:"name,omitempty"`
+	Strategy            *string     `json:"strategy,omitempty"`
+	Filtration          *bool       `json:"filtration,omitempty"`
+	StopDays            *int        `json:"stop_days,omitempty"`
+	AnalizeAttemptCount *int        `json:"analize_attempt_count,omitempty"`
+	BadSipCodes         []int       `json:"bad_sip_codes,omitempty"`
+	Rules               FilterRules `json:"rules"` // Действующие правила, с учётом стандартного набора и filtration
+}
+
+// Событие в истории номера
+type ExplainEvent struct {
+	At          time.Time  `json:"at"`
+	Type        string     `json:"type" example:"filter"` // first_load, last_load, call, filter, block, unblock, log, blacklist, sync
+	TeamID      *int       `json:"caf_team_id,omitempty"`
+	Rule        *string    `json:"rule,omitempty"`   // Код правила или стратегии
+	Action      *string    `json:"action,omitempty"` // reject, log, delay, block, unblock
+	Description *string    `json:"description,omitempty"`
+	Details     LogDetails `json:"details,omitempty"`
+}
+
+// История решений по номеру
+type NumberExplain struct {
+	Number    string          `json:"number"`
+	Numbers   []ExplainNumber `json:"numbers"`   // Записи номера в caf.numbers
+	Teams     []ExplainTeam   `json:"teams"`     // Команды, которые встречаются в истории
+	Blacklist []BlackList     `json:"blacklist"` // Записи номера в ЧС
+	Reasons   []ReasonsStat   `json:"reasons"`   // SIP статистика, ещё не обработанная стратегией cause
+	Timeline  []ExplainEvent  `json:"timeline"`  // События по времени
+}
+
+type SwaggerNumberExplain struct {
+	Status string        `json:"status"`
+	Data   NumberExplain `json:"data"`
+}
@@ -1,6 +1,13 @@
 package model
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+)
 
 type Logs struct {
 	ID          int64      `db:"id" json:"-"`
@@ -11,6 +18,36 @@ type Logs struct {
 	Description *string    `db:"description" json:"description,omitempty"`
 	Filtered    *bool      `db:"filtered" json:"filtered,omitempty"`
 	Sent        *bool      `db:"sent" json:"sent,omitempty"`
+	Rule        *string    `db:"rule" json:"rule,omitempty"`       // Код сработавшего правила
+	Action      *string    `db:"action" json:"action,omitempty"`   // reject, log, delay, block, unblock
+	Details     LogDetails `db:"details" json:"details,omitempty"` // Параметры решения: стратегия, пороги, SIP код
+
+	NotifiedChannels *pgtype.TextArray `db:"notified_channels" json:"-"` // Каналы уведомлений, которые уже получили запись
+}
+
+// Параметры решения в логе, хранятся в jsonb
+type LogDetails map[string]interface{}
+
+// Scan для работы с базой данных
+func (d *LogDetails) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	}
+	return fmt.Errorf("cannot scan type %T into %T", value, d)
+}
+
+// Value для работы с базой данных
+func (d LogDetails) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(d)
 }
 
 type EmailData struct {
@@ -34,6 +34,19 @@ type Config struct {
 		Key         string `json:"key"`
 		BlacklistID int64  `json:"blacklist_id"`
 	} `json:"webitel_api"`
+	Members struct {
+		BulkBatchSize   int `json:"bulk_batch_size"`  // Сколько мемберов отправлять в Webitel одним запросом, по умолчанию 100
+		BulkConcurrency int `json:"bulk_concurrency"` // Сколько запросов в Webitel выполнять одновременно, по умолчанию 4
+	} `json:"members"`
+	Sync struct {
+		IntervalSec int `json:"interval_sec"` // Период запуска обработчика заданий, по умолчанию 30 сек
+		MaxAttempts int `json:"max_attempts"` // После стольких ошибок задание уходит в dead, по умолчанию 8
+		BackoffSec  int `json:"backoff_sec"`  // Первая задержка повтора, дальше удваивается, по умолчанию 30 сек
+	} `json:"sync"`
+	Notify struct {
+		TelegramURL string `json:"telegram_url"` // Адрес Telegram Bot API, по умолчанию https://api.telegram.org
+		TimeoutSec  int    `json:"timeout_sec"`  // Таймаут запросов webhook и telegram, по умолчанию 15 сек
+	} `json:"notify"`
 	MAIL struct {
 		ServerAddr   string `json:"smtp_server_addr"`
 		ServerPort   string `json:"smtp_server_port"`
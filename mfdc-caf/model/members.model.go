@@ -69,3 +69,29 @@ type Members struct {
 	Communications *[]Communication   `json:"communications,omitempty"`
 	MinOfferingAt  *string            `json:"min_offering_at,omitempty"`
 }
+
+// Результат загрузки одного мембера в /{id}/members/bulk
+type MemberBulkResult struct {
+	Index    int     `json:"index"` // Порядковый номер мембера в запросе
+	Number   string  `json:"number"`
+	Status   string  `json:"status" example:"accepted"` // accepted, filtered, error
+	MemberID *string `json:"member_id,omitempty"`
+	Rule     *string `json:"rule,omitempty"`   // Сработавшее правило фильтрации
+	Action   *string `json:"action,omitempty"` // Действие правила
+	Reason   *string `json:"reason,omitempty"`
+	Error    *string `json:"error,omitempty"`
+}
+
+// Итог загрузки мемберов
+type MemberBulkResponse struct {
+	Total    int                `json:"total"`
+	Accepted int                `json:"accepted"`
+	Filtered int                `json:"filtered"`
+	Failed   int                `json:"failed"`
+	Items    []MemberBulkResult `json:"items"`
+}
+
+type SwaggerMemberBulk struct {
+	Status string             `json:"status"`
+	Data   MemberBulkResponse `json:"data"`
+}
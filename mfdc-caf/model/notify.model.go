@@ -0,0 +1,100 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Канал уведомлений команды, используются только нужные типу поля
+type NotifyChannel struct {
+	Type     string            `json:"type" example:"telegram"`     // smtp, webhook, telegram, local
+	Enabled  *bool             `json:"enabled,omitempty"`           // Без поля канал включён
+	To       []string          `json:"to,omitempty"`                // smtp: адреса, без них - email команды
+	URL      string            `json:"url,omitempty"`               // webhook: адрес для JSON POST
+	Headers  map[string]string `json:"headers,omitempty"`           // webhook: дополнительные заголовки, в ответах значения скрыты маской
+	BotToken string            `json:"bot_token,omitempty"`         // telegram: токен бота, в ответах скрыт маской. Маска в запросе сохраняет прежнее значение
+	ChatID   string            `json:"chat_id,omitempty"`           // telegram: чат для сообщений
+	Name     string            `json:"name,omitempty" example:"qa"` // Необязательное имя канала для логов
+}
+
+// Список каналов команды, хранится в jsonb
+type NotifyChannels []NotifyChannel
+
+// Scan для работы с базой данных
+func (n *NotifyChannels) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, n)
+	case string:
+		return json.Unmarshal([]byte(v), n)
+	}
+	return fmt.Errorf("cannot scan type %T into %T", value, n)
+}
+
+// Value для работы с базой данных
+func (n NotifyChannels) Value() (driver.Value, error) {
+	if n == nil {
+		return nil, nil
+	}
+	return json.Marshal(n)
+}
+
+// Шаблоны уведомлений команды (text/template), пустые заменяются стандартными
+type NotifyTemplates struct {
+	Subject *string `json:"subject,omitempty"` // Тема письма
+	HTML    *string `json:"html,omitempty"`    // Письмо smtp
+	Text    *string `json:"text,omitempty"`    // Сообщение telegram, webhook и local
+}
+
+// Scan для работы с базой данных
+func (n *NotifyTemplates) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, n)
+	case string:
+		return json.Unmarshal([]byte(v), n)
+	}
+	return fmt.Errorf("cannot scan type %T into %T", value, n)
+}
+
+// Value для работы с базой данных
+func (n NotifyTemplates) Value() (driver.Value, error) {
+	return json.Marshal(n)
+}
+
+// Сводка по команде, которую получает канал. Она же тело запроса webhook
+type NotifyMessage struct {
+	TeamID   int       `json:"caf_team_id"`
+	TeamName string    `json:"team_name"`
+	FromDate string    `json:"from_date"`
+	ToDate   string    `json:"to_date"`
+	Subject  string    `json:"subject"`
+	HTML     string    `json:"-"`
+	Text     string    `json:"text"`
+	Logs     []Logs    `json:"logs"`
+	Channel  string    `json:"channel,omitempty"` // Имя или тип канала, заполняется для local
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// Результат отправки в канал
+type NotifyResult struct {
+	Channel string  `json:"channel"`
+	Status  string  `json:"status"` // success, failed
+	Error   *string `json:"error,omitempty"`
+}
+
+type SwaggerNotifyTest struct {
+	Status string         `json:"status"`
+	Data   []NotifyResult `json:"data"`
+}
+
+type SwaggerNotifyOutbox struct {
+	Status string          `json:"status"`
+	Data   []NotifyMessage `json:"data"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Параметры правила фильтрации, используются только нужные правилу
+type FilterRuleParams struct {
+	WindowDays   *int `json:"window_days,omitempty"`   // Окно в днях, для success_window без него - текущая неделя
+	MaxSuccesses *int `json:"max_successes,omitempty"` // Сколько успешных звонков за окно допускается
+	MinTalkSec   *int `json:"min_talk_sec,omitempty"`  // Звонок считается успешным от этой длительности разговора
+	DelayMinutes *int `json:"delay_minutes,omitempty"` // Отсрочка звонка для действия delay
+}
+
+// Правило фильтрации мемберов команды
+type FilterRule struct {
+	Code   string           `json:"code" example:"blacklist"` // blacklist, client_id, today_success, success_window, duplicate
+	Action string           `json:"action" example:"reject"`  // reject, log, delay
+	Params FilterRuleParams `json:"params"`
+}
+
+// Упорядоченный список правил команды, хранится в jsonb
+type FilterRules []FilterRule
+
+// Scan для работы с базой данных
+func (r *FilterRules) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	}
+	return fmt.Errorf("cannot scan type %T into %T", value, r)
+}
+
+// Value для работы с базой данных
+func (r FilterRules) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// Сработавшее правило
+type FilterVerdict struct {
+	Rule    string     `json:"rule"`
+	Action  string     `json:"action"`
+	Message string     `json:"message"`
+	Delay   int        `json:"delay_minutes,omitempty"`
+	Details LogDetails `json:"details,omitempty"` // Параметры правила или стратегии на момент решения
+}
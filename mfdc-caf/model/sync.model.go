@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Задание на синхронизацию ЧС с внешней системой
+type SyncJob struct {
+	ID          int64      `db:"id" json:"id"`
+	Target      string     `db:"target" json:"target" example:"webitel"`   // webitel, bp
+	Operation   string     `db:"operation" json:"operation" example:"add"` // add, remove
+	Number      string     `db:"number" json:"number"`
+	ClientID    *string    `db:"client_id" json:"client_id,omitempty"`
+	Description *string    `db:"description" json:"description,omitempty"`
+	State       string     `db:"state" json:"state" example:"pending"` // pending, done, dead, superseded
+	Attempts    int        `db:"attempts" json:"attempts"`
+	NextRunAt   *time.Time `db:"next_run_at" json:"next_run_at,omitempty"`
+	LastError   *string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type SwaggerSyncJobs struct {
+	Status string    `json:"status"`
+	Count  int       `json:"count"`
+	Data   []SyncJob `json:"data"`
+}
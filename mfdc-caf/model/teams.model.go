@@ -1,18 +1,29 @@
 package model
 
-import "github.com/jackc/pgtype"
+import (
+	"time"
+
+	"github.com/jackc/pgtype"
+)
 
 type Team struct {
-	ID                  *int    `db:"id" json:"caf_team_id,omitempty"`
-	Name                *string `db:"name" json:"name,omitempty"`
-	Active              *bool   `db:"active" json:"active,omitempty"`
-	Filtration          *bool   `db:"filtration" json:"filtration,omitempty"`
-	EMail               *string `db:"email" json:"email,omitempty"`
-	StopDays            *int    `db:"stop_days" json:"stop_days,omitempty"`
-	AnalizeAttemptCount *int    `db:"analize_attempt_count" json:"analize_attempt_count,omitempty"`
-	Strategy            *string `db:"strategy" json:"strategy,omitempty"`
-	WebitelQueuesIDS    *[]int  `db:"webitel_queues_ids" json:"webitel_queues_ids,omitempty"`
-	BadSipCodes         *[]int  `db:"bad_sip_codes" json:"bad_sip_codes,omitempty"`
+	ID                  *int             `db:"id" json:"caf_team_id,omitempty"`
+	Name                *string          `db:"name" json:"name,omitempty"`
+	Active              *bool            `db:"active" json:"active,omitempty"`
+	Filtration          *bool            `db:"filtration" json:"filtration,omitempty"`
+	EMail               *string          `db:"email" json:"email,omitempty"`
+	StopDays            *int             `db:"stop_days" json:"stop_days,omitempty"`
+	AnalizeAttemptCount *int             `db:"analize_attempt_count" json:"analize_attempt_count,omitempty"`
+	Strategy            *string          `db:"strategy" json:"strategy,omitempty"`
+	WebitelQueuesIDS    *[]int           `db:"webitel_queues_ids" json:"webitel_queues_ids,omitempty"`
+	BadSipCodes         *[]int           `db:"bad_sip_codes" json:"bad_sip_codes,omitempty"`
+	Rules               *FilterRules     `db:"rules" json:"rules,omitempty"`                               // Правила фильтрации по порядку, без них - стандартный набор. При filtration=false reject пишется в лог
+	SuccessMinTalkSec   *int             `db:"success_min_talk_sec" json:"success_min_talk_sec,omitempty"` // Успешный звонок от этой длительности, по умолчанию 7 сек
+	SuccessHangupBy     *[]string        `db:"success_hangup_by" json:"success_hangup_by,omitempty"`       // Кто может завершить успешный звонок
+	SuccessSipCodes     *[]int           `db:"success_sip_codes" json:"success_sip_codes,omitempty"`       // SIP коды успешного звонка
+	NotifyChannels      *NotifyChannels  `db:"notify_channels" json:"notify_channels,omitempty"`           // Каналы уведомлений, без них - письмо на email
+	NotifyIntervalMin   *int             `db:"notify_interval_min" json:"notify_interval_min,omitempty"`   // Период сводки в минутах, по умолчанию 60
+	NotifyTemplates     *NotifyTemplates `db:"notify_templates" json:"notify_templates,omitempty"`         // Шаблоны сводки
 }
 
 type TeamDB struct {
@@ -26,6 +37,14 @@ type TeamDB struct {
 	Strategy            *string           `db:"strategy"`
 	WebitelQueuesIDS    *pgtype.Int4Array `db:"webitel_queues_ids"`
 	BadSipCodes         *pgtype.Int4Array `db:"bad_sip_codes"`
+	Rules               *FilterRules      `db:"rules"`
+	SuccessMinTalkSec   *int              `db:"success_min_talk_sec"`
+	SuccessHangupBy     *pgtype.TextArray `db:"success_hangup_by"`
+	SuccessSipCodes     *pgtype.Int4Array `db:"success_sip_codes"`
+	NotifyChannels      *NotifyChannels   `db:"notify_channels"`
+	NotifyIntervalMin   *int              `db:"notify_interval_min"`
+	NotifyTemplates     *NotifyTemplates  `db:"notify_templates"`
+	NotifiedAt          *time.Time        `db:"notified_at"`
 }
 
 type SwaggerTeamsList struct {
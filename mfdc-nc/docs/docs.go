@@ -46,6 +46,34 @@ const docTemplate = `{
                 }
             }
         },
+        "/leader/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Current leader of background jobs (rotation, subpool activation, VC sync) and its lease",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leader"
+                ],
+                "summary": "Background jobs leader",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerLeaderStatus"
+                        }
+                    }
+                }
+            }
+        },
         "/logs": {
             "post": {
                 "security": [
@@ -102,6 +130,55 @@ const docTemplate = `{
                 }
             }
         },
+        "/numbers/callerid/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Best local caller ID for the callee: active team number with the same area code, otherwise current team number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Caller ID lookup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Callee number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team name (routing)",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "team_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerCallerIDLookup"
+                        }
+                    }
+                }
+            }
+        },
         "/numbers/exclusion": {
             "patch": {
                 "security": [
@@ -144,6 +221,53 @@ const docTemplate = `{
                 }
             }
         },
+        "/numbers/health/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get number health score and its history built from outbound calls",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Get number health history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number format 79XXXXXXXXX",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "History records",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.NumberHealthResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/numbers/info/{number}": {
             "get": {
                 "security": [
@@ -284,6 +408,45 @@ const docTemplate = `{
                 }
             }
         },
+        "/numbers/state": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move numbers through lifecycle: active, cooldown, quarantined (period from vendor quarantine_days), retired. Changes are logged in nc.logs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Change numbers state",
+                "parameters": [
+                    {
+                        "description": "Numbers and new state",
+                        "name": "ip",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.NumberStateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerNumberStateResult"
+                        }
+                    }
+                }
+            }
+        },
         "/numbers/upload": {
             "post": {
                 "security": [
@@ -291,7 +454,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Upload a file and JSON data",
+                "description": "Validate numbers file (CSV, XLSX or JSON) and return report with accepted and rejected rows. Pool is created only after confirmation",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -305,11 +468,17 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "file",
-                        "description": "CSV file",
+                        "description": "CSV, XLSX (first column of first sheet) or JSON (array of numbers) file",
                         "name": "file",
                         "in": "formData",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "File format: csv, xlsx, json (by default from file extension)",
+                        "name": "format",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "name": "name",
@@ -331,6 +500,86 @@ const docTemplate = `{
                         "in": "formData"
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerNumbersImport"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/numbers/upload/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get validation report of uploaded numbers file",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Upload report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerNumbersImport"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/numbers/upload/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create pool with subpools for accepted numbers of validated upload (or append them to existing pool) in one transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Confirm upload",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -391,7 +640,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List pools",
+                "description": "List pools with exhaustion forecast (days left until numbers are re-used)",
                 "consumes": [
                     "application/json"
                 ],
@@ -457,14 +706,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/pools/{id}/activate": {
+        "/pools/numsmove/batches": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Activate pool",
+                "description": "History of /pools/numsmove redistributions",
                 "consumes": [
                     "application/json"
                 ],
@@ -474,37 +723,40 @@ const docTemplate = `{
                 "tags": [
                     "Pools"
                 ],
-                "summary": "Activate pool",
+                "summary": "Redistribution batches",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Pool ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Source or destination pool ID",
+                        "name": "pool_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Records",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
-                            }
+                            "$ref": "#/definitions/model.SwaggerNumsMoveBatches"
                         }
                     }
                 }
             }
         },
-        "/pools/{id}/deactivate": {
+        "/pools/numsmove/batches/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deactivate pool",
+                "description": "Redistribution with moved numbers and their source pool, subpool, team and vendor",
                 "consumes": [
                     "application/json"
                 ],
@@ -514,11 +766,11 @@ const docTemplate = `{
                 "tags": [
                     "Pools"
                 ],
-                "summary": "Deactivate pool",
+                "summary": "Redistribution batch",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Pool ID",
+                        "description": "Batch ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -528,23 +780,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
-                            }
+                            "$ref": "#/definitions/model.SwaggerNumsMoveBatch"
                         }
                     }
                 }
             }
         },
-        "/schedule/add": {
+        "/pools/numsmove/batches/{id}/rollback": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add new schedule",
+                "description": "Returns moved numbers to their source pools atomically. Refused if any moved number was rotated after the redistribution",
                 "consumes": [
                     "application/json"
                 ],
@@ -552,41 +801,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "Pools"
                 ],
-                "summary": "Add scheduler",
+                "summary": "Redistribution rollback",
                 "parameters": [
                     {
-                        "description": "Schedule, time format: 00:00:00+03",
-                        "name": "route",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Scheduler"
-                        }
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
-                            }
+                            "$ref": "#/definitions/model.SwaggerDefaultResponse"
                         }
                     }
                 }
             }
         },
-        "/schedule/delete/{id}": {
-            "delete": {
+        "/pools/{id}/activate": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Drop schedule",
+                "description": "Activate pool",
                 "consumes": [
                     "application/json"
                 ],
@@ -594,13 +838,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "Pools"
                 ],
-                "summary": "Delete schedule",
+                "summary": "Activate pool",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Schedule ID",
+                        "description": "Pool ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -619,40 +863,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/schedule/edit/{id}": {
-            "patch": {
+        "/pools/{id}/append": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Edit schedule params",
+                "description": "Validate numbers file (CSV, XLSX or JSON) for appending to existing pool. After confirmation numbers fill incomplete subpools up to subpool block, the rest go to new subpools",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "Pools"
                 ],
-                "summary": "Edt schedule",
+                "summary": "Append numbers to pool",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Schedule ID",
+                        "description": "Pool ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Data without ID",
-                        "name": "ip",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Scheduler"
-                        }
+                        "type": "file",
+                        "description": "CSV, XLSX (first column of first sheet) or JSON (array of numbers) file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File format: csv, xlsx, json (by default from file extension)",
+                        "name": "format",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -661,21 +909,21 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                                "$ref": "#/definitions/model.SwaggerNumbersImport"
                             }
                         }
                     }
                 }
             }
         },
-        "/schedule/list": {
+        "/pools/{id}/deactivate": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List scheduls",
+                "description": "Deactivate pool",
                 "consumes": [
                     "application/json"
                 ],
@@ -683,30 +931,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "Pools"
+                ],
+                "summary": "Deactivate pool",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pool ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List scheduls",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Scheduler"
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
                             }
                         }
                     }
                 }
             }
         },
-        "/subpools/next": {
-            "get": {
+        "/pools/{id}/strategy": {
+            "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Manual activate new subpool",
+                "description": "Set rotation strategy for pool: sequential, random, lru, weighted",
                 "consumes": [
                     "application/json"
                 ],
@@ -714,9 +971,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Subpools"
+                    "Pools"
+                ],
+                "summary": "Set pool rotation strategy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pool ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Strategy",
+                        "name": "params",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PoolStrategyRequest"
+                        }
+                    }
                 ],
-                "summary": "Manual activate next subpool",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -730,127 +1005,229 @@ const docTemplate = `{
                 }
             }
         },
-        "/subpools/{pool_id}/next": {
+        "/reports/activations": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Manual activate new subpool for pool",
+                "description": "Number activations per day and team with average active time of numbers activated that day",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Subpools"
+                    "Reports"
                 ],
-                "summary": "Manual activate next subpool for pool",
+                "summary": "Activations per day",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period start: 2006-01-02 or 2006-01-02 15:04",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period end, date without time includes the whole day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vendor ID",
+                        "name": "vendor_id",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Pool ID",
                         "name": "pool_id",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
-                            }
+                            "$ref": "#/definitions/model.SwaggerActivationsReport"
                         }
                     }
                 }
             }
         },
-        "/teams/activenums": {
+        "/reports/exposure": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Active teams numbers",
+                "description": "Total time numbers were active in Webitel resources, grouped by number, team, vendor or pool, with activations count and average active time",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Teams"
+                    "Reports"
+                ],
+                "summary": "Exposure report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period start: 2006-01-02 or 2006-01-02 15:04",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period end, date without time includes the whole day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "number",
+                        "description": "number, team, vendor or pool",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vendor ID",
+                        "name": "vendor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pool ID",
+                        "name": "pool_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Active teams numbers",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.SwaggerActiveTeamNumber"
-                            }
+                            "$ref": "#/definitions/model.SwaggerExposureReport"
                         }
                     }
                 }
             }
         },
-        "/teams/add": {
-            "post": {
+        "/reports/unused": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add new team\nJSON object containing resource IDs",
+                "description": "Numbers that were never activated, or not activated in the period when from and to are set",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Teams"
+                    "Reports"
                 ],
-                "summary": "Add team",
+                "summary": "Never used numbers",
                 "parameters": [
                     {
-                        "description": "Team",
-                        "name": "route",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.SwaggerTeams"
-                        }
+                        "type": "string",
+                        "description": "Period start: 2006-01-02 or 2006-01-02 15:04",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period end, date without time includes the whole day",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vendor ID",
+                        "name": "vendor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pool ID",
+                        "name": "pool_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
-                            }
+                            "$ref": "#/definitions/model.SwaggerUnusedReport"
                         }
                     }
                 }
             }
         },
-        "/teams/daynums": {
+        "/schedule/add": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List teams numbers per day",
+                "description": "Add new schedule",
                 "consumes": [
                     "application/json"
                 ],
@@ -858,17 +1235,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "Scheduler"
                 ],
-                "summary": "List teams numbers per day",
+                "summary": "Add scheduler",
                 "parameters": [
                     {
-                        "description": "Data",
-                        "name": "data",
+                        "description": "Schedule: start_time/stop_time (00:00:00+03), cron (* 9-18 * * 1-5) or windows ([{days: [1,2,3,4,5], start: 09:00, stop: 13:00}]), exceptions ([2025-01-01])",
+                        "name": "route",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.TeamsDayRequest"
+                            "$ref": "#/definitions/model.Scheduler"
                         }
                     }
                 ],
@@ -878,21 +1255,21 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.SwaggerTeamDayNumbers"
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
                             }
                         }
                     }
                 }
             }
         },
-        "/teams/delete/{id}": {
+        "/schedule/delete/{id}": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Drop team",
+                "description": "Drop schedule",
                 "consumes": [
                     "application/json"
                 ],
@@ -900,13 +1277,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "Scheduler"
                 ],
-                "summary": "Delete team",
+                "summary": "Delete schedule",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Team ID",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -925,14 +1302,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/teams/edit/{id}": {
+        "/schedule/edit/{id}": {
             "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Edit team params\nJSON object containing resource IDs",
+                "description": "Edit schedule params",
                 "consumes": [
                     "application/json"
                 ],
@@ -940,24 +1317,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "Scheduler"
                 ],
-                "summary": "Edt team",
+                "summary": "Edt schedule",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Team ID",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Data without ID",
+                        "description": "Data without ID, empty cron/windows/exceptions clear the value",
                         "name": "ip",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.SwaggerTeams"
+                            "$ref": "#/definitions/model.Scheduler"
                         }
                     }
                 ],
@@ -974,14 +1351,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/teams/list": {
+        "/schedule/list": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List teams",
+                "description": "List scheduls",
                 "consumes": [
                     "application/json"
                 ],
@@ -989,30 +1366,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "Scheduler"
                 ],
-                "summary": "List teams",
+                "summary": "List scheduls",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.SwaggerTeamsList"
+                                "$ref": "#/definitions/model.Scheduler"
                             }
                         }
                     }
                 }
             }
         },
-        "/teams/{id}/rotate": {
+        "/schedule/simulate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Dry-run of schedule rotation over the next N hours without touching Webitel or DB. Draft schedule replaces saved schedules of its team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scheduler"
+                ],
+                "summary": "Simulate rotation schedules",
+                "parameters": [
+                    {
+                        "description": "Hours (1-168, default 24), team filter and optional draft schedule",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SimulateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerSimulateResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subpools/next": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Rotate team number",
+                "description": "Manual activate new subpool",
                 "consumes": [
                     "application/json"
                 ],
@@ -1020,14 +1439,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Teams"
+                    "Subpools"
                 ],
-                "summary": "Rotate team number",
+                "summary": "Manual activate next subpool",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subpools/{pool_id}/next": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Manual activate new subpool for pool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subpools"
+                ],
+                "summary": "Manual activate next subpool for pool",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Team ID",
-                        "name": "id",
+                        "description": "Pool ID",
+                        "name": "pool_id",
                         "in": "path",
                         "required": true
                     }
@@ -1045,14 +1495,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/vendors/add": {
+        "/teams/activenums": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Active teams numbers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Active teams numbers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerActiveTeamNumber"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/add": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add new vendor",
+                "description": "Add new team\nJSON object containing resource IDs",
                 "consumes": [
                     "application/json"
                 ],
@@ -1060,17 +1541,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vendors"
+                    "Teams"
                 ],
-                "summary": "Add vendor",
+                "summary": "Add team",
                 "parameters": [
                     {
-                        "description": "Vendor",
+                        "description": "Team",
                         "name": "route",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.VendorSimple"
+                            "$ref": "#/definitions/model.SwaggerTeams"
                         }
                     }
                 ],
@@ -1087,14 +1568,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/vendors/delete/{id}": {
+        "/teams/daynums": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List teams numbers per day",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "List teams numbers per day",
+                "parameters": [
+                    {
+                        "description": "Data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamsDayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerTeamDayNumbers"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/delete/{id}": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Drop vendor",
+                "description": "Drop team",
                 "consumes": [
                     "application/json"
                 ],
@@ -1102,13 +1625,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vendors"
+                    "Teams"
                 ],
-                "summary": "Delete vendor",
+                "summary": "Delete team",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Vendor ID",
+                        "description": "Team ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1127,14 +1650,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/vendors/edit/{id}": {
+        "/teams/edit/{id}": {
             "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Edit vendor params",
+                "description": "Edit team params\nJSON object containing resource IDs",
                 "consumes": [
                     "application/json"
                 ],
@@ -1142,13 +1665,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vendors"
+                    "Teams"
                 ],
-                "summary": "Edt vendor",
+                "summary": "Edt team",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Vendor ID",
+                        "description": "Team ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1159,7 +1682,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.VendorSimple"
+                            "$ref": "#/definitions/model.SwaggerTeams"
                         }
                     }
                 ],
@@ -1176,14 +1699,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/vendors/list": {
+        "/teams/list": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List vendors",
+                "description": "List teams",
                 "consumes": [
                     "application/json"
                 ],
@@ -1191,119 +1714,1614 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vendors"
+                    "Teams"
                 ],
-                "summary": "List vendors",
+                "summary": "List teams",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.SwaggerVendorList"
+                                "$ref": "#/definitions/model.SwaggerTeamsList"
                             }
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.ActiveTeamNumber": {
-            "type": "object",
-            "properties": {
-                "activated_at": {
-                    "type": "string"
-                },
-                "expired_at": {
-                    "type": "string"
-                },
-                "expired_at_unixtime": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "number": {
-                    "type": "string"
-                },
-                "spin": {
-                    "type": "integer"
-                },
-                "team_id": {
-                    "type": "integer"
-                },
-                "vendor_name": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.DayLogs": {
-            "type": "object",
-            "properties": {
-                "end_at": {
-                    "type": "string"
-                },
-                "start_at": {
-                    "type": "string"
-                }
-            }
         },
-        "model.DayNumbers": {
-            "type": "object",
-            "properties": {
-                "active": {
-                    "type": "boolean"
-                },
-                "enabled": {
-                    "type": "boolean"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "last_activated_at": {
-                    "type": "string"
+        "/teams/{id}/control": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rotation pause and pinned number of team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Team rotation control",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerTeamControl"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/drift": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compare numbers in team Webitel resources with active numbers in NC and list mismatches. Read-only, use POST /teams/{id}/drift/repush to fix them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Team resources drift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerResourceDrift"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/drift/repush": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compare numbers in team Webitel resources with active numbers in NC and re-push active number to drifted resources",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Repush team resources drift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerResourceDrift"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pause team rotation until the given time or until resume. Audited in logs with user email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Pause team rotation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pause",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamPauseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerTeamControl"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/pin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Push the number to team resources and keep it until the given time or until unpin. Audited in logs with user email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Pin team number",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pin",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamPinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerTeamControl"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resume paused team rotation. Audited in logs with user email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Resume team rotation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resume",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamResumeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/rotate": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rotate team number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Rotate team number",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/unpin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return team to scheduled rotation. Audited in logs with user email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Unpin team number",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Unpin",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.TeamResumeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/worker": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "State of team rotation worker: last run, last error and next run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Team rotation worker",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerTeamWorker"
+                        }
+                    }
+                }
+            }
+        },
+        "/vendors/add": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add new vendor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vendors"
+                ],
+                "summary": "Add vendor",
+                "parameters": [
+                    {
+                        "description": "Vendor",
+                        "name": "route",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.VendorSimple"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vendors/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Drop vendor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vendors"
+                ],
+                "summary": "Delete vendor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vendor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vendors/edit/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Edit vendor params",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vendors"
+                ],
+                "summary": "Edt vendor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vendor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data without ID",
+                        "name": "ip",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.VendorSimple"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerDefaultResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/vendors/failover": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Called by VC when team switched to another vendor: activates the vendor pool and pushes a fresh number to its Webitel resources",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vendors"
+                ],
+                "summary": "Vendor failover",
+                "parameters": [
+                    {
+                        "description": "Team and new actual vendor, by ID or by name",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.VendorFailoverRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerVendorFailover"
+                        }
+                    }
+                }
+            }
+        },
+        "/vendors/failover/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "History of vendor failovers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vendors"
+                ],
+                "summary": "Vendor failovers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Records",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SwaggerVendorFailoverList"
+                        }
+                    }
+                }
+            }
+        },
+        "/vendors/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List vendors",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vendors"
+                ],
+                "summary": "List vendors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SwaggerVendorList"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.ActivationsDay": {
+            "type": "object",
+            "properties": {
+                "activations": {
+                    "type": "integer"
+                },
+                "avg_active_sec": {
+                    "type": "number"
+                },
+                "day": {
+                    "description": "2006-01-02 в часовом поясе config.Rotate.TimeZone",
+                    "type": "string"
+                },
+                "numbers": {
+                    "type": "integer"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ActiveTeamNumber": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "expired_at_unixtime": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "spin": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "vendor_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CallerIDLookup": {
+            "type": "object",
+            "properties": {
+                "area_code": {
+                    "type": "string"
+                },
+                "callee": {
+                    "type": "string"
+                },
+                "callee_area_code": {
+                    "type": "string"
+                },
+                "callee_region": {
+                    "type": "string"
+                },
+                "match": {
+                    "description": "area - совпал код региона, default - текущий номер команды",
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DayLogs": {
+            "type": "object",
+            "properties": {
+                "end_at": {
+                    "type": "string"
+                },
+                "start_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DayNumbers": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_activated_at": {
+                    "type": "string"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DayLogs"
+                    }
+                },
+                "marked": {
+                    "type": "boolean"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "spin": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.DayPools": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "active_subpool": {
+                    "$ref": "#/definitions/model.DaySubPool"
+                },
+                "block": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finish": {
+                    "type": "boolean"
+                },
+                "finish_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "num_count": {
+                    "type": "integer"
+                },
+                "rotation": {
+                    "type": "boolean"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DaySubPool": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "numbers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DayNumbers"
+                    }
+                },
+                "spin": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ExposureReport": {
+            "type": "object",
+            "properties": {
+                "activations": {
+                    "type": "integer"
+                },
+                "avg_active_sec": {
+                    "type": "number"
+                },
+                "exposure_sec": {
+                    "description": "Суммарное время активности в периоде",
+                    "type": "number"
+                },
+                "id": {
+                    "description": "ID номера, команды, вендора или пула",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Номер, имя команды, вендора или пула",
+                    "type": "string"
+                },
+                "numbers": {
+                    "description": "Разных номеров, которые были активны",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ImportRow": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "description": "Номер после нормализации",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Номер строки в файле (с 1)",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "accepted, rejected",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Значение из файла",
+                    "type": "string"
+                }
+            }
+        },
+        "model.LeaderLease": {
+            "type": "object",
+            "properties": {
+                "acquired_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "renewed_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LeaderStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Выборы включены, иначе фоновые задачи запускает каждый экземпляр",
+                    "type": "boolean"
+                },
+                "expired": {
+                    "description": "Аренда не продлевалась дольше lease_second, лидер мог упасть",
+                    "type": "boolean"
+                },
+                "instance": {
+                    "description": "Текущий экземпляр nc-api",
+                    "type": "string"
+                },
+                "is_leader": {
+                    "description": "Текущий экземпляр выполняет фоновые задачи",
+                    "type": "boolean"
+                },
+                "leader": {
+                    "description": "Последняя аренда лидера",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.LeaderLease"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.LogJsonResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {},
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LogRequest": {
+            "type": "object",
+            "properties": {
+                "from_date": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "Номер телефона",
+                    "type": "string"
+                },
+                "pool_id": {
+                    "type": "integer"
+                },
+                "subpool_id": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "to_date": {
+                    "type": "string"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NumberHealth": {
+            "type": "object",
+            "properties": {
+                "answer_rate": {
+                    "type": "number"
+                },
+                "answered": {
+                    "type": "integer"
+                },
+                "calc_at": {
+                    "type": "string"
+                },
+                "calls": {
+                    "type": "integer"
+                },
+                "number_id": {
+                    "type": "integer"
+                },
+                "reject_ratio": {
+                    "type": "number"
+                },
+                "rejected": {
+                    "description": "Отбои с SIP 403/603",
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "short_call_ratio": {
+                    "type": "number"
+                },
+                "short_calls": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NumberHealthResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NumberHealth"
+                    }
+                },
+                "health_score": {
+                    "type": "number"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumberInfo": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "active": {
+                    "type": "boolean"
+                },
+                "area_code": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "health_score": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NumberLogs"
+                    }
+                },
+                "moved_at": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "Номер телефона",
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "spin": {
+                    "type": "integer"
+                },
+                "state": {
+                    "description": "active, cooldown, quarantined, retired",
+                    "type": "string"
+                },
+                "state_reason": {
+                    "type": "string"
+                },
+                "state_until": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "used": {
+                    "type": "boolean"
+                },
+                "vendor": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumberLogs": {
+            "type": "object",
+            "properties": {
+                "end_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "description": "activation - номер был в ротации, state - смена состояния",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "state_from": {
+                    "type": "string"
+                },
+                "state_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumberStateRequest": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Длительность охлаждения или карантина вместо настроек",
+                    "type": "integer"
+                },
+                "number_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "spam complaints"
+                },
+                "state": {
+                    "description": "active, cooldown, quarantined, retired",
+                    "type": "string",
+                    "example": "quarantined"
+                }
+            }
+        },
+        "model.NumberStateResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "number_id": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumberTeamInfo": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "description": "Номер телефона",
+                    "type": "string"
+                },
+                "routing": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumbersExclusion": {
+            "type": "object",
+            "properties": {
+                "numbers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NumbersExclusionData"
+                    }
+                }
+            }
+        },
+        "model.NumbersExclusionData": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "number_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NumbersImport": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "confirmed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "import_id": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "description": "create - новый пул, append - дозагрузка в пул pool_id",
+                    "type": "string"
+                },
+                "pool_id": {
+                    "type": "integer"
+                },
+                "pool_name": {
+                    "type": "string"
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportRow"
+                    }
+                },
+                "status": {
+                    "description": "pending, confirmed",
+                    "type": "string"
+                },
+                "subpool_block": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NumbersJsonResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {},
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NumsMoveBatch": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "logs": {
+                "created_subpools": {
+                    "description": "Сабпулы, созданные в пуле назначения",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "dst_pool_id": {
+                    "type": "integer"
+                },
+                "dst_subpool_block": {
+                    "type": "integer"
+                },
+                "dst_subpool_count": {
+                    "type": "integer"
+                },
+                "dst_team_id": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "numbers_count": {
+                    "type": "integer"
+                },
+                "rolled_back_at": {
+                    "type": "string"
+                },
+                "rolled_back_by": {
+                    "type": "string"
+                },
+                "src_pool_id": {
+                    "type": "integer"
+                },
+                "src_subpool_block": {
+                    "description": "Параметры пулов до перераспределения",
+                    "type": "integer"
+                },
+                "src_subpool_count": {
+                    "type": "integer"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NumsMoveBatchInfo": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_subpools": {
+                    "description": "Сабпулы, созданные в пуле назначения",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "dst_pool_id": {
+                    "type": "integer"
+                },
+                "dst_subpool_block": {
+                    "type": "integer"
+                },
+                "dst_subpool_count": {
+                    "type": "integer"
+                },
+                "dst_team_id": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "numbers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NumsMoveNumber"
+                    }
+                },
+                "numbers_count": {
+                    "type": "integer"
+                },
+                "rolled_back_at": {
+                    "type": "string"
+                },
+                "rolled_back_by": {
+                    "type": "string"
+                },
+                "src_pool_id": {
+                    "type": "integer"
+                },
+                "src_subpool_block": {
+                    "description": "Параметры пулов до перераспределения",
+                    "type": "integer"
+                },
+                "src_subpool_count": {
+                    "type": "integer"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NumsMoveNumber": {
+            "type": "object",
+            "properties": {
+                "dst_subpool_id": {
+                    "type": "integer"
+                },
+                "number_id": {
+                    "type": "integer"
+                },
+                "src_activated_at": {
+                    "type": "string"
+                },
+                "src_label": {
+                    "type": "boolean"
+                },
+                "src_moved_at": {
+                    "type": "string"
+                },
+                "src_pool_id": {
+                    "type": "integer"
+                },
+                "src_subpool_id": {
+                    "type": "integer"
+                },
+                "src_team_id": {
+                    "type": "integer"
+                },
+                "src_vendor_id": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Pool": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "block": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finish": {
+                    "type": "boolean"
+                },
+                "finish_at": {
+                    "type": "string"
+                },
+                "forecast": {
+                    "$ref": "#/definitions/model.PoolForecast"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "num_count": {
+                    "type": "integer"
+                },
+                "rotation": {
+                    "type": "boolean"
+                },
+                "strategy": {
+                    "description": "Стратегия ротации: sequential, random, lru, weighted",
+                    "type": "string"
+                },
+                "subpool_count": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PoolForecast": {
+            "type": "object",
+            "properties": {
+                "activations": {
+                    "description": "Активаций номеров за окно прогноза",
+                    "type": "integer"
+                },
+                "days_left": {
+                    "description": "Сколько дней осталось до повторного использования номеров, пусто если пул не ротируется",
+                    "type": "number"
+                },
+                "reusing": {
+                    "description": "Все номера пула уже использованы, идёт повторный цикл",
+                    "type": "boolean"
+                },
+                "reusing_at": {
+                    "description": "Когда пул начал повторный цикл",
+                    "type": "string"
+                },
+                "rotations_per_day": {
+                    "description": "Средний темп ротации в сутки",
+                    "type": "number"
+                },
+                "unused_numbers": {
+                    "description": "Номера, которые ещё ни разу не выдавались",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PoolRedistribution": {
+            "type": "object",
+            "properties": {
+                "dst_subpools_count": {
+                    "type": "integer"
+                },
+                "dst_team_id": {
+                    "type": "integer"
+                },
+                "dst_vendor_id": {
+                    "type": "integer"
+                },
+                "from_pool_id": {
+                    "type": "integer"
+                },
+                "move_count_numbers": {
+                    "type": "integer"
+                },
+                "src_subpools_count": {
+                    "type": "integer"
+                },
+                "src_vendor_id": {
+                    "type": "integer"
+                },
+                "to_pool_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PoolStrategyRequest": {
+            "type": "object",
+            "properties": {
+                "strategy": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Reload": {
+            "type": "object",
+            "properties": {
+                "reload": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Resource": {
+            "type": "object",
+            "properties": {
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ResourceDrift": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expected": {
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "missing - в ресурсе нет номера, mismatch - другой номер, extra - лишние номера, error - ресурс не прочитан",
+                    "type": "string"
+                },
+                "repushed": {
+                    "type": "boolean"
+                },
+                "resource_id": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ScheduleWindow": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Дни недели как в cron: 0 - воскресенье ... 6 - суббота (7 - тоже воскресенье), пусто - каждый день",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.DayLogs"
+                        "type": "integer"
                     }
                 },
-                "marked": {
-                    "type": "boolean"
-                },
-                "number": {
+                "start": {
+                    "description": "15:04, если start \u003e stop, окно переходит через полночь",
                     "type": "string"
                 },
-                "spin": {
-                    "type": "integer"
-                },
-                "used": {
-                    "type": "boolean"
+                "stop": {
+                    "type": "string"
                 }
             }
         },
-        "model.DayPools": {
+        "model.Scheduler": {
             "type": "object",
             "properties": {
-                "active": {
-                    "type": "boolean"
-                },
-                "active_subpool": {
-                    "$ref": "#/definitions/model.DaySubPool"
-                },
-                "block": {
-                    "type": "integer"
-                },
-                "created_at": {
+                "cron": {
+                    "description": "Cron-выражение (минута час день месяц день_недели), активны минуты, которые ему соответствуют",
                     "type": "string"
                 },
-                "finish": {
-                    "type": "boolean"
-                },
-                "finish_at": {
-                    "type": "string"
+                "exceptions": {
+                    "description": "Даты, в которые ротация не запускается",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "id": {
                     "type": "integer"
@@ -1311,300 +3329,317 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "num_count": {
+                "periodic_sec": {
                     "type": "integer"
                 },
-                "rotation": {
-                    "type": "boolean"
+                "start_time": {
+                    "$ref": "#/definitions/model.TimeTZ"
                 },
-                "vendor_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "model.DaySubPool": {
-            "type": "object",
-            "properties": {
-                "activated_at": {
-                    "type": "string"
+                "stop_time": {
+                    "$ref": "#/definitions/model.TimeTZ"
                 },
-                "id": {
+                "team_id": {
                     "type": "integer"
                 },
-                "numbers": {
+                "team_name": {
+                    "type": "string"
+                },
+                "windows": {
+                    "description": "Недельные окна",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.DayNumbers"
+                        "$ref": "#/definitions/model.ScheduleWindow"
                     }
-                },
-                "spin": {
-                    "type": "integer"
                 }
             }
         },
-        "model.LogJsonResponse": {
+        "model.SimulateDryPool": {
             "type": "object",
             "properties": {
-                "count": {
+                "at": {
+                    "type": "string"
+                },
+                "pool_id": {
                     "type": "integer"
                 },
-                "data": {},
-                "status": {
+                "pool_name": {
+                    "type": "string"
+                },
+                "reason": {
                     "type": "string"
                 }
             }
         },
-        "model.LogRequest": {
+        "model.SimulateEvent": {
             "type": "object",
             "properties": {
-                "from_date": {
+                "at": {
+                    "type": "string"
+                },
+                "event": {
+                    "description": "rotate, subpool_activate, subpool_cycle, pool_reuse, no_number",
                     "type": "string"
                 },
                 "number": {
-                    "description": "Номер телефона",
                     "type": "string"
                 },
+                "number_id": {
+                    "type": "integer"
+                },
                 "pool_id": {
                     "type": "integer"
                 },
+                "pool_name": {
+                    "type": "string"
+                },
                 "subpool_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "model.SimulatePoolStat": {
+            "type": "object",
+            "properties": {
+                "numbers_unused": {
+                    "type": "integer"
                 },
-                "team_id": {
+                "numbers_used": {
                     "type": "integer"
                 },
-                "to_date": {
+                "pool_id": {
+                    "type": "integer"
+                },
+                "pool_name": {
                     "type": "string"
+                }
+            }
+        },
+        "model.SimulateRequest": {
+            "type": "object",
+            "properties": {
+                "hours": {
+                    "type": "integer"
                 },
-                "vendor_id": {
+                "schedule": {
+                    "description": "Черновик расписания, который ещё не сохранён в nc.scheduler",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Scheduler"
+                        }
+                    ]
+                },
+                "team_id": {
                     "type": "integer"
                 }
             }
         },
-        "model.NumberInfo": {
+        "model.SimulateResponse": {
             "type": "object",
             "properties": {
-                "activated_at": {
+                "from": {
                     "type": "string"
                 },
-                "active": {
-                    "type": "boolean"
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SimulateTeam"
+                    }
                 },
-                "enabled": {
-                    "type": "boolean"
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SimulateTeam": {
+            "type": "object",
+            "properties": {
+                "dry_pools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SimulateDryPool"
+                    }
                 },
-                "id": {
+                "periodic_sec": {
                     "type": "integer"
                 },
-                "logs": {
+                "pools": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.NumberLogs"
+                        "$ref": "#/definitions/model.SimulatePoolStat"
                     }
                 },
-                "moved_at": {
-                    "type": "string"
+                "rotations": {
+                    "type": "integer"
                 },
-                "number": {
-                    "description": "Номер телефона",
+                "schedule": {
                     "type": "string"
                 },
-                "spin": {
+                "team_id": {
                     "type": "integer"
                 },
-                "team": {
+                "team_name": {
                     "type": "string"
                 },
-                "used": {
-                    "type": "boolean"
-                },
-                "vendor": {
-                    "type": "string"
+                "timeline": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SimulateEvent"
+                    }
                 }
             }
         },
-        "model.NumberLogs": {
+        "model.SwaggerActivationsReport": {
             "type": "object",
             "properties": {
-                "end_at": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "start_at": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ActivationsDay"
+                    }
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "model.NumberTeamInfo": {
+        "model.SwaggerActiveTeamNumber": {
             "type": "object",
             "properties": {
-                "number": {
-                    "description": "Номер телефона",
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ActiveTeamNumber"
+                    }
                 },
-                "routing": {
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "model.NumbersExclusion": {
+        "model.SwaggerCallerIDLookup": {
             "type": "object",
             "properties": {
-                "numbers": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.NumbersExclusionData"
-                    }
+                "data": {
+                    "$ref": "#/definitions/model.CallerIDLookup"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "model.NumbersExclusionData": {
+        "model.SwaggerDefaultResponse": {
             "type": "object",
             "properties": {
-                "enabled": {
-                    "type": "boolean"
+                "message": {
+                    "type": "string"
                 },
-                "number_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "model.NumbersJsonResponse": {
+        "model.SwaggerExposureReport": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "data": {},
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExposureReport"
+                    }
+                },
                 "status": {
                     "type": "string"
                 }
             }
         },
-        "model.Pool": {
+        "model.SwaggerLeaderStatus": {
             "type": "object",
             "properties": {
-                "active": {
-                    "type": "boolean"
-                },
-                "block": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "finish": {
-                    "type": "boolean"
-                },
-                "finish_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/model.LeaderStatus"
                 },
-                "name": {
+                "status": {
                     "type": "string"
-                },
-                "num_count": {
-                    "type": "integer"
-                },
-                "rotation": {
-                    "type": "boolean"
-                },
-                "subpool_count": {
-                    "type": "integer"
-                },
-                "team_id": {
-                    "type": "integer"
-                },
-                "vendor_id": {
-                    "type": "integer"
                 }
             }
         },
-        "model.PoolRedistribution": {
+        "model.SwaggerNumberStateResult": {
             "type": "object",
             "properties": {
-                "dst_subpools_count": {
-                    "type": "integer"
-                },
-                "dst_team_id": {
-                    "type": "integer"
-                },
-                "dst_vendor_id": {
-                    "type": "integer"
-                },
-                "from_pool_id": {
-                    "type": "integer"
-                },
-                "move_count_numbers": {
-                    "type": "integer"
-                },
-                "src_subpools_count": {
-                    "type": "integer"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NumberStateResult"
+                    }
                 },
-                "src_vendor_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerNumbersImport": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.NumbersImport"
                 },
-                "to_pool_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "model.Reload": {
+        "model.SwaggerNumsMoveBatch": {
             "type": "object",
             "properties": {
-                "reload": {
+                "data": {
+                    "$ref": "#/definitions/model.NumsMoveBatchInfo"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "model.Resource": {
+        "model.SwaggerNumsMoveBatches": {
             "type": "object",
             "properties": {
-                "resources": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/model.NumsMoveBatch"
                     }
                 },
-                "vendor_id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "model.Scheduler": {
+        "model.SwaggerPoolsList": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "periodic_sec": {
-                    "type": "integer"
-                },
-                "start_time": {
-                    "$ref": "#/definitions/model.TimeTZ"
-                },
-                "stop_time": {
-                    "$ref": "#/definitions/model.TimeTZ"
-                },
-                "team_id": {
-                    "type": "integer"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Pool"
+                    }
                 },
-                "team_name": {
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "model.SwaggerActiveTeamNumber": {
+        "model.SwaggerResourceDrift": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.ActiveTeamNumber"
+                        "$ref": "#/definitions/model.ResourceDrift"
                     }
                 },
                 "status": {
@@ -1612,25 +3647,22 @@ const docTemplate = `{
                 }
             }
         },
-        "model.SwaggerDefaultResponse": {
+        "model.SwaggerSimulateResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "data": {
+                    "$ref": "#/definitions/model.SimulateResponse"
                 },
                 "status": {
                     "type": "string"
                 }
             }
         },
-        "model.SwaggerPoolsList": {
+        "model.SwaggerTeamControl": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Pool"
-                    }
+                    "$ref": "#/definitions/model.TeamControl"
                 },
                 "status": {
                     "type": "string"
@@ -1651,12 +3683,28 @@ const docTemplate = `{
                 }
             }
         },
+        "model.SwaggerTeamWorker": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.TeamWorker"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "model.SwaggerTeams": {
             "type": "object",
             "properties": {
                 "actual_vendor_id": {
                     "type": "integer"
                 },
+                "backend": {
+                    "description": "webitel, db, file, memory (только при api.debug_mode)",
+                    "type": "string",
+                    "example": "webitel"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1682,6 +3730,51 @@ const docTemplate = `{
                 }
             }
         },
+        "model.SwaggerUnusedReport": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UnusedNumber"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerVendorFailover": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.VendorFailover"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SwaggerVendorFailoverList": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.VendorFailover"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "model.SwaggerVendorList": {
             "type": "object",
             "properties": {
@@ -1696,6 +3789,44 @@ const docTemplate = `{
                 }
             }
         },
+        "model.TeamControl": {
+            "type": "object",
+            "properties": {
+                "pause_reason": {
+                    "type": "string"
+                },
+                "paused_at": {
+                    "type": "string"
+                },
+                "paused_by": {
+                    "type": "string"
+                },
+                "paused_until": {
+                    "type": "string"
+                },
+                "pin_reason": {
+                    "type": "string"
+                },
+                "pinned_at": {
+                    "type": "string"
+                },
+                "pinned_by": {
+                    "type": "string"
+                },
+                "pinned_number": {
+                    "type": "string"
+                },
+                "pinned_number_id": {
+                    "type": "integer"
+                },
+                "pinned_until": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "model.TeamDayNumbers": {
             "type": "object",
             "properties": {
@@ -1713,6 +3844,100 @@ const docTemplate = `{
                 }
             }
         },
+        "model.TeamPauseRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "campaign"
+                },
+                "until": {
+                    "description": "Пусто - до resume",
+                    "type": "string",
+                    "example": "2026-10-20T09:00:00+03:00"
+                }
+            }
+        },
+        "model.TeamPinRequest": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "description": "Номер вместо number_id",
+                    "type": "string"
+                },
+                "number_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "campaign"
+                },
+                "until": {
+                    "description": "Пусто - до unpin",
+                    "type": "string",
+                    "example": "2026-10-20T09:00:00+03:00"
+                }
+            }
+        },
+        "model.TeamResumeRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TeamWorker": {
+            "type": "object",
+            "properties": {
+                "instance": {
+                    "description": "Экземпляр nc-api, на котором работает воркер",
+                    "type": "string"
+                },
+                "last_duration_sec": {
+                    "type": "number"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "panics": {
+                    "description": "Шаги ротации, завершившиеся паникой, с запуска воркера",
+                    "type": "integer"
+                },
+                "periodic_sec": {
+                    "type": "integer"
+                },
+                "restarts": {
+                    "description": "Перезапуски воркера после изменения расписания",
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "description": "waiting, running, stopped",
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
         "model.TeamsDayRequest": {
             "type": "object",
             "properties": {
@@ -1732,6 +3957,29 @@ const docTemplate = `{
                 }
             }
         },
+        "model.UnusedNumber": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "pool": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "vendor": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Vendor": {
             "type": "object",
             "properties": {
@@ -1740,6 +3988,66 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "quarantine_days": {
+                    "description": "Длительность карантина номеров вендора в днях",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.VendorFailover": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "from_vendor_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "Номер, отправленный в ресурсы нового вендора",
+                    "type": "string"
+                },
+                "pool_id": {
+                    "description": "Пул нового вендора, который был активирован",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "webhook или sync",
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "to_vendor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.VendorFailoverRequest": {
+            "type": "object",
+            "properties": {
+                "team": {
+                    "description": "Имя команды как в VC, если не передан team_id",
+                    "type": "string",
+                    "example": "Sales"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "vendor": {
+                    "description": "Имя вендора как в VC, если не передан vendor_id",
+                    "type": "string",
+                    "example": "Vendor"
+                },
+                "vendor_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -1748,6 +4056,9 @@ const docTemplate = `{
             "properties": {
                 "name": {
                     "type": "string"
+                },
+                "quarantine_days": {
+                    "type": "integer"
                 }
             }
         }
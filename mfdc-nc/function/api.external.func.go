@@ -92,6 +92,43 @@ func VCAPIFetch(method, url string, jsonData interface{}) ([]byte, int, error) {
 	return body, resp.StatusCode, nil
 }
 
+// Запрос во внешний вебхук без авторизации
+func WebhookFetch(method, url string, jsonData interface{}) ([]byte, int, error) {
+	var reqBody io.Reader
+
+	// Устанавливаем reqBody только если метод не GET и не DELETE
+	if jsonData != nil && method != http.MethodGet && method != http.MethodDelete {
+		body, err := json.Marshal(jsonData)
+		if err != nil {
+			return nil, 500, fmt.Errorf("Failed to marshal JSON data: %w", err)
+		}
+		reqBody = bytes.NewBuffer(body)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, 500, fmt.Errorf("Failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Выполняем запрос
+	client := &http.Client{Timeout: 25 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 500, fmt.Errorf("Failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Читаем тело ответа
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 500, fmt.Errorf("Failed to read response body: %w", err)
+	}
+
+	// Возвращаем body ответа
+	return body, resp.StatusCode, nil
+}
+
 func putToWebitel(resource_id int, number string) error {
 	// Преоразуем из int в string
 	resourceIDStr := strconv.Itoa(resource_id)
@@ -217,9 +254,10 @@ func SyncActualVendor(db *sqlx.DB) error {
 				return fmt.Errorf("failed to get VendorID for vendor '%s': %w", *resource.Vendor, err)
 			}
 
-			_, err = db.Exec("UPDATE nc.teams SET actual_vendor_id = $1 WHERE id = $2", vendorID, teamID)
+			// При смене вендора сразу переключаем ротацию на его пул
+			_, err = switchActualVendor(db, teamID, vendorID, failoverSync)
 			if err != nil {
-				return fmt.Errorf("failed to update actual VendorID for team ID '%d': %w", teamID, err)
+				ErrLog.Printf("VC sync failover error: %s", err)
 			}
 		}
 	}
@@ -56,14 +56,18 @@ func GetLogs(db *sqlx.DB, c *gin.Context) {
 	}
 
 	var logs []model.LogList
+	// У действий супервизора с командой нет номера, вендора и пула
 	query := `SELECT 
 			l.start_at, 
 			l.end_at, 
-			l.comment,
-			n.value AS number,
-			t.name AS team,
-			v.name AS vendor,
-			p.name AS pool_name
+			COALESCE(l.comment, '') AS comment,
+			COALESCE(n.value, '') AS number,
+			COALESCE(t.name, '') AS team,
+			COALESCE(v.name, '') AS vendor,
+			COALESCE(p.name, '') AS pool_name,
+			l.event,
+			l.reason,
+			l.email
 			FROM nc.logs AS l
 			LEFT JOIN nc.numbers AS n ON l.number_id=n.id
 			LEFT JOIN nc.teams AS t ON l.team_id=t.id
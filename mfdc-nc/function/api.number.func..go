@@ -26,7 +26,7 @@ func NumberInfo(db *sqlx.DB, c *gin.Context) {
 	number := c.Param("number")
 	CheckIDAsInt(number, c)
 
-	query := `SELECT n.id, n.value, n.activated_at, n.used, n.active, n.spin, v.name AS vendor, t.name AS team FROM nc.numbers AS n
+	query := `SELECT n.id, n.value, n.activated_at, n.used, n.active, n.spin, n.health_score, n.state, n.state_until, n.state_reason, n.area_code, n.region, v.name AS vendor, t.name AS team FROM nc.numbers AS n
 			LEFT JOIN nc.vendors AS v ON n.vendor_id=v.id
 			LEFT JOIN nc.teams AS t ON n.team_id=t.id
 			WHERE n.value = $1 LIMIT 1`
@@ -43,7 +43,7 @@ func NumberInfo(db *sqlx.DB, c *gin.Context) {
 	}
 
 	var numberLogs []model.NumberLogs
-	err = db.Select(&numberLogs, "SELECT start_at, end_at FROM nc.logs WHERE number_id = $1 ORDER By start_at DESC", numberInfo.ID)
+	err = db.Select(&numberLogs, "SELECT start_at, end_at, event, state_from, state_to, reason FROM nc.logs WHERE number_id = $1 ORDER By start_at DESC", numberInfo.ID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to get number logs", "error": err.Error()})
 		return
@@ -87,6 +87,62 @@ func NumberTeamInfo(db *sqlx.DB, c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "success", "number": numberInfo.Number, "routing": strings.ToLower(numberInfo.Team)})
 }
 
+// Caller ID lookup godoc
+// @Summary      Caller ID lookup
+// @Description  Best local caller ID for the callee: active team number with the same area code, otherwise current team number
+// @Tags         Numbers
+// @Accept       json
+// @Produce      json
+// @Param        number   path      string  true  "Callee number"
+// @Param        team query string false "Team name (routing)"
+// @Param        team_id query int false "Team ID"
+// @Success      200  {object}   model.SwaggerCallerIDLookup
+// @Router       /numbers/callerid/{number} [get]
+// @Security ApiKeyAuth
+func CallerIDLookup(db *sqlx.DB, c *gin.Context) {
+	callee, err := normalizeNumber(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid callee number", "error": err.Error()})
+		return
+	}
+
+	// Команда по ID или по имени маршрутизации из /numbers/routing
+	var teamID int
+	if teamStr := c.Query("team_id"); teamStr != "" {
+		teamID, err = strconv.Atoi(teamStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+			return
+		}
+		err = db.Get(&teamID, "SELECT id FROM nc.teams WHERE id = $1", teamID)
+	} else if team := c.Query("team"); team != "" {
+		err = db.Get(&teamID, "SELECT id FROM nc.teams WHERE lower(name) = lower($1) LIMIT 1", team)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team or team_id is required"})
+		return
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Team not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get team", "error": err.Error()})
+		return
+	}
+
+	lookup, err := lookupCallerID(db, teamID, callee)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get caller ID", "error": err.Error()})
+		return
+	}
+	if lookup == nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "No active number for team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": lookup})
+}
+
 // Get numbers in pool godoc
 // @Summary      Get numbers in pool
 // @Description  Get numbers in pool
@@ -209,3 +265,133 @@ func NumberExclusion(db *sqlx.DB, c *gin.Context) {
 	// Отправляем JSON-ответ
 	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": numCountStr + " numbers successfully edited"})
 }
+
+// Change numbers state godoc
+// @Summary      Change numbers state
+// @Description  Move numbers through lifecycle: active, cooldown, quarantined (period from vendor quarantine_days), retired. Changes are logged in nc.logs
+// @Tags         Numbers
+// @Accept       json
+// @Produce      json
+// @Param ip body model.NumberStateRequest true "Numbers and new state"
+// @Success      200  {object}   model.SwaggerNumberStateResult
+// @Router       /numbers/state [patch]
+// @Security ApiKeyAuth
+func NumberState(db *sqlx.DB, c *gin.Context) {
+	var request model.NumberStateRequest
+
+	// Чтение данных из тела запроса
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid request data", "error": err.Error()})
+		return
+	}
+
+	if len(request.NumberIDS) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Numbers array is empty"})
+		return
+	}
+
+	if _, ok := stateTransitions[request.State]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Unknown state, available: active, cooldown, quarantined, retired"})
+		return
+	}
+
+	reason := request.Reason
+	if reason == "" {
+		reason = "manual"
+	}
+	if email := c.GetString("email"); email != "" {
+		reason += " by " + email
+	}
+
+	days := 0
+	if request.Days != nil {
+		days = *request.Days
+	}
+
+	var results []model.NumberStateResult
+	for _, numberID := range request.NumberIDS {
+		result := model.NumberStateResult{NumberID: numberID, State: request.State}
+
+		// Каждый номер в своей транзакции, чтобы ошибка по одному не отменяла остальные
+		tx, err := db.Beginx()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to begin transaction", "error": err.Error()})
+			return
+		}
+
+		switch request.State {
+		case stateQuarantined:
+			result.State, result.Until, err = quarantineNumber(tx, numberID, days, reason)
+		case stateCooldown:
+			result.Until, err = cooldownNumber(tx, numberID, days, reason)
+		default:
+			err = changeNumberState(tx, numberID, request.State, nil, reason)
+		}
+
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			result.State = ""
+			result.Until = nil
+			result.Error = err.Error()
+		} else if err = tx.Commit(); err != nil { // Подтверждаем транзакцию
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "data": results})
+}
+
+// Get number health godoc
+// @Summary      Get number health history
+// @Description  Get number health score and its history built from outbound calls
+// @Tags         Numbers
+// @Accept       json
+// @Produce      json
+// @Param        number   path      int  true  "Number format 79XXXXXXXXX"
+// @Param limit query int false "History records" default(100)
+// @Success      200  {array}   model.NumberHealthResponse
+// @Router       /numbers/health/{number} [get]
+// @Security ApiKeyAuth
+func NumberHealthHistory(db *sqlx.DB, c *gin.Context) {
+	number := c.Param("number")
+	CheckIDAsInt(number, c)
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	var numberInfo struct {
+		ID          int      `db:"id"`
+		HealthScore *float64 `db:"health_score"`
+	}
+	err := db.Get(&numberInfo, "SELECT id, health_score FROM nc.numbers WHERE value = $1 LIMIT 1", number)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "No data for request"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to get number info", "error": err.Error()})
+		return
+	}
+
+	history := []model.NumberHealth{}
+	err = db.Select(&history, `SELECT number_id, calls, answered, short_calls, rejected, answer_rate, short_call_ratio, reject_ratio, score, calc_at
+		FROM nc.number_health WHERE number_id = $1 ORDER BY calc_at DESC LIMIT $2`, numberInfo.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to get number health", "error": err.Error()})
+		return
+	}
+
+	response := model.NumberHealthResponse{
+		Status:      "success",
+		Number:      number,
+		HealthScore: numberInfo.HealthScore,
+		Data:        history,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -2,9 +2,11 @@ package function
 
 import (
 	"database/sql"
+	"fmt"
 	"nc/model"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -12,7 +14,7 @@ import (
 
 // List pools godoc
 // @Summary      List pools
-// @Description  List pools
+// @Description  List pools with exhaustion forecast (days left until numbers are re-used)
 // @Tags         Pools
 // @Accept       json
 // @Produce      json
@@ -24,6 +26,19 @@ func PoolsList(db *sqlx.DB, c *gin.Context) {
 	err := db.Select(&pools, "SELECT * FROM nc.pools ORDER By name")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to get pool", "error": err.Error()})
+		return
+	}
+
+	// Прогноз исчерпания пулов
+	forecasts, err := calcPoolForecasts(db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to calculate pool forecast", "error": err.Error()})
+		return
+	}
+	for i := range pools {
+		if pools[i].ID != nil {
+			pools[i].Forecast = forecasts[*pools[i].ID]
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "data": pools})
@@ -157,6 +172,121 @@ func PoolDelete(db *sqlx.DB, c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Pool successfully deleted"})
 }
 
+// Pool strategy godoc
+// @Summary      Set pool rotation strategy
+// @Description  Set rotation strategy for pool: sequential, random, lru, weighted
+// @Tags         Pools
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Pool ID"
+// @Param        params body model.PoolStrategyRequest true "Strategy"
+// @Success      200  {array}   model.SwaggerDefaultResponse
+// @Router       /pools/{id}/strategy [patch]
+// @Security ApiKeyAuth
+func PoolStrategy(db *sqlx.DB, c *gin.Context) {
+	// Получение ID из URL
+	id := c.Param("id")
+	CheckIDAsInt(id, c)
+
+	var request model.PoolStrategyRequest
+
+	// Чтение данных из тела запроса
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid request data", "error": err.Error()})
+		return
+	}
+
+	if err := checkRotationStrategy(request.Strategy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid strategy", "error": err.Error()})
+		return
+	}
+
+	result, err := db.Exec("UPDATE nc.pools SET strategy = $1 WHERE id = $2", request.Strategy, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to update pool strategy", "error": err.Error()})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Pool not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Pool strategy set to " + request.Strategy})
+}
+
+// Append numbers godoc
+// @Summary      Append numbers to pool
+// @Description  Validate numbers file (CSV, XLSX or JSON) for appending to existing pool. After confirmation numbers fill incomplete subpools up to subpool block, the rest go to new subpools
+// @Tags         Pools
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id   path      int  true  "Pool ID"
+// @Param file formData file true "CSV, XLSX (first column of first sheet) or JSON (array of numbers) file"
+// @Param format formData string false "File format: csv, xlsx, json (by default from file extension)"
+// @Success      200  {array}   model.SwaggerNumbersImport
+// @Router       /pools/{id}/append [post]
+// @Security ApiKeyAuth
+func AppendNumbers(db *sqlx.DB, c *gin.Context) {
+	// Получение ID из URL
+	id := c.Param("id")
+	poolID, err := strconv.Atoi(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "ID must be a number", "error": err.Error()})
+		return
+	}
+
+	var pool model.Pool
+	err = db.Get(&pool, "SELECT * FROM nc.pools WHERE id = $1", poolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Pool not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get pool", "error": err.Error()})
+		return
+	}
+	if pool.SubPoolBlock == nil || pool.VendorID == nil || pool.TeamID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Pool has no subpool block, vendor or team"})
+		return
+	}
+
+	format, values, err := readUploadFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Unable to read file", "error": err.Error()})
+		return
+	}
+
+	report, err := buildImportReport(db, values)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to validate numbers", "error": err.Error()})
+		return
+	}
+
+	entry := model.NumbersImport{
+		PoolName:     *pool.Name,
+		TeamID:       *pool.TeamID,
+		VendorID:     *pool.VendorID,
+		SubPoolBlock: *pool.SubPoolBlock,
+		Format:       format,
+		Mode:         importModeAppend,
+		PoolID:       &poolID,
+		Rows:         report,
+	}
+	if email := c.GetString("email"); email != "" {
+		entry.Email = &email
+	}
+
+	err = createNumbersImport(db, &entry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to save import", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("File validated, confirm import %d with /numbers/upload/%d/confirm before %s", entry.ID, entry.ID, entry.ExpiresAt.Format(time.RFC3339)), "data": entry})
+}
+
 // Activate new subpool godoc
 // @Summary      Manual activate next subpool
 // @Description  Manual activate new subpool
@@ -239,11 +369,101 @@ func RedistributionPools(db *sqlx.DB, c *gin.Context) {
 	}
 
 	// Функция перераспределения номеров
-	err := NumbersMoveByPool(db, request.MoveCountNumbers, request.SrcPoolID, request.DstPoolID, request.DstTeamID, request.SrcVendorID, request.DstVendorID, request.SrcSubPoolsCount, request.DstSubPoolsCount)
+	batchID, err := NumbersMoveByPool(db, userEmail(c), request.MoveCountNumbers, request.SrcPoolID, request.DstPoolID, request.DstTeamID, request.SrcVendorID, request.DstVendorID, request.SrcSubPoolsCount, request.DstSubPoolsCount)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to redistribution", "error:": err.Error()})
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Redistribution successfully", "data:": request})
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Redistribution successfully", "data:": request, "batch_id": batchID})
+}
+
+// Redistribution batches godoc
+// @Summary      Redistribution batches
+// @Description  History of /pools/numsmove redistributions
+// @Tags         Pools
+// @Accept       json
+// @Produce      json
+// @Param pool_id query int false "Source or destination pool ID"
+// @Param limit query int false "Records" default(100)
+// @Success      200  {object}   model.SwaggerNumsMoveBatches
+// @Router       /pools/numsmove/batches [get]
+// @Security ApiKeyAuth
+func NumsMoveBatches(db *sqlx.DB, c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	var poolID *int
+	if poolStr := c.Query("pool_id"); poolStr != "" {
+		id, err := strconv.Atoi(poolStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Pool ID must be a number", "error": err.Error()})
+			return
+		}
+		poolID = &id
+	}
+
+	batches, err := getNumsMoveBatches(db, poolID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get redistributions", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": batches})
+}
+
+// Redistribution batch godoc
+// @Summary      Redistribution batch
+// @Description  Redistribution with moved numbers and their source pool, subpool, team and vendor
+// @Tags         Pools
+// @Accept       json
+// @Produce      json
+// @Param id path int true "Batch ID"
+// @Success      200  {object}   model.SwaggerNumsMoveBatch
+// @Router       /pools/numsmove/batches/{id} [get]
+// @Security ApiKeyAuth
+func NumsMoveBatch(db *sqlx.DB, c *gin.Context) {
+	batchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Batch ID must be a number", "error": err.Error()})
+		return
+	}
+
+	batch, err := getNumsMoveBatch(db, batchID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to get redistribution", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": batch})
+}
+
+// Redistribution rollback godoc
+// @Summary      Redistribution rollback
+// @Description  Returns moved numbers to their source pools atomically. Refused if any moved number was rotated after the redistribution
+// @Tags         Pools
+// @Accept       json
+// @Produce      json
+// @Param id path int true "Batch ID"
+// @Success      200  {object}   model.SwaggerDefaultResponse
+// @Router       /pools/numsmove/batches/{id}/rollback [post]
+// @Security ApiKeyAuth
+func NumsMoveRollback(db *sqlx.DB, c *gin.Context) {
+	batchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Batch ID must be a number", "error": err.Error()})
+		return
+	}
+
+	batch, err := rollbackNumsMoveBatch(db, batchID, userEmail(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to roll back redistribution", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Redistribution rolled back", "data": batch})
 }
@@ -0,0 +1,329 @@
+package function
+
+import (
+	"encoding/csv"
+	"fmt"
+	"nc/model"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// Группировки отчёта по экспозиции: ID и имя группы
+var exposureGroups = map[string][2]string{
+	"number": {"l.number_id", "n.value"},
+	"team":   {"l.team_id", "t.name"},
+	"vendor": {"l.vendor_id", "v.name"},
+	"pool":   {"l.pool_id", "p.name"},
+}
+
+// Общая часть запросов отчётов по nc.logs
+const reportLogsFrom = `FROM nc.logs AS l
+			LEFT JOIN nc.numbers AS n ON l.number_id=n.id
+			LEFT JOIN nc.teams AS t ON l.team_id=t.id
+			LEFT JOIN nc.vendors AS v ON l.vendor_id=v.id
+			LEFT JOIN nc.pools AS p ON l.pool_id=p.id`
+
+// Разбор даты отчёта: 2006-01-02 или 2006-01-02 15:04[:05] в часовом поясе ротации
+func parseReportDate(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected 2006-01-02 or 2006-01-02 15:04", value)
+	}
+	// Дата без времени в конце периода включает весь день
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// Период отчёта из параметров from и to
+func parseReportPeriod(c *gin.Context) (from time.Time, to time.Time, err error) {
+	fromStr, toStr := c.Query("from"), c.Query("to")
+	if fromStr == "" || toStr == "" {
+		return from, to, fmt.Errorf("please provide a date range")
+	}
+
+	loc, err := time.LoadLocation(config.Rotate.TimeZone)
+	if err != nil {
+		return from, to, fmt.Errorf("failed to load location: %w", err)
+	}
+
+	from, err = parseReportDate(fromStr, loc, false)
+	if err != nil {
+		return from, to, err
+	}
+	to, err = parseReportDate(toStr, loc, true)
+	if err != nil {
+		return from, to, err
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
+
+// Фильтры отчёта по team_id, vendor_id и pool_id
+func addReportFilters(c *gin.Context, query *string, prefix string, paramIndex int, args *[]interface{}) (int, error) {
+	for _, column := range []string{"team_id", "vendor_id", "pool_id"} {
+		value := c.Query(column)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return paramIndex, fmt.Errorf("%s must be a number", column)
+		}
+		addCondition(query, prefix+column, paramIndex, args, id)
+		paramIndex++
+	}
+	return paramIndex, nil
+}
+
+// Формат ответа отчёта: json (по умолчанию) или csv
+func checkReportFormat(c *gin.Context) bool {
+	switch c.Query("format") {
+	case "", "json", "csv":
+		return true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Unknown format, available: json, csv"})
+	return false
+}
+
+// Отправка отчёта в JSON или CSV, строки CSV собираются только при format=csv
+func writeReport(c *gin.Context, name string, header []string, count int, data interface{}, rows func() [][]string) {
+	if c.Query("format") != "csv" {
+		c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "count": count, "data": data})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.csv", name, time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(header)
+	writer.WriteAll(rows()) // WriteAll выполняет Flush
+	if err := writer.Error(); err != nil {
+		ErrLog.Printf("Failed to write %s report: %s", name, err)
+	}
+}
+
+func formatReportFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func formatReportString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func formatReportInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// Exposure report godoc
+// @Summary      Exposure report
+// @Description  Total time numbers were active in Webitel resources, grouped by number, team, vendor or pool, with activations count and average active time
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Param from query string true "Period start: 2006-01-02 or 2006-01-02 15:04"
+// @Param to query string true "Period end, date without time includes the whole day"
+// @Param group query string false "number, team, vendor or pool" default(number)
+// @Param team_id query int false "Team ID"
+// @Param vendor_id query int false "Vendor ID"
+// @Param pool_id query int false "Pool ID"
+// @Param format query string false "json or csv" default(json)
+// @Success      200  {object}   model.SwaggerExposureReport
+// @Router       /reports/exposure [get]
+// @Security ApiKeyAuth
+func ExposureReport(db *sqlx.DB, c *gin.Context) {
+	if !checkReportFormat(c) {
+		return
+	}
+
+	group := c.DefaultQuery("group", "number")
+	columns, ok := exposureGroups[group]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Unknown group, available: number, team, vendor, pool"})
+		return
+	}
+
+	from, to, err := parseReportPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid period", "error": err.Error()})
+		return
+	}
+
+	// Время активности обрезается границами периода, незакрытая активация считается до текущего момента
+	duration := "EXTRACT(EPOCH FROM LEAST(COALESCE(l.end_at, now()), $2) - GREATEST(l.start_at, $1))"
+	query := fmt.Sprintf(`SELECT %s AS id, COALESCE(%s, '') AS name,
+			COUNT(l.id) AS activations,
+			COUNT(DISTINCT l.number_id) AS numbers,
+			ROUND(COALESCE(SUM(%s), 0)::numeric, 2)::float8 AS exposure_sec,
+			ROUND(COALESCE(AVG(%s), 0)::numeric, 2)::float8 AS avg_active_sec
+			%s
+			WHERE l.event = $3 AND l.start_at < $2 AND COALESCE(l.end_at, now()) > $1`, columns[0], columns[1], duration, duration, reportLogsFrom)
+	args := []interface{}{from, to, logEventActivation}
+
+	_, err = addReportFilters(c, &query, "l.", 4, &args)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid filter", "error": err.Error()})
+		return
+	}
+	query += " GROUP BY 1, 2 ORDER BY exposure_sec DESC, name"
+
+	var report []model.ExposureReport
+	err = db.Select(&report, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to build exposure report", "error": err.Error()})
+		return
+	}
+
+	writeReport(c, "exposure-"+group, []string{"id", group, "activations", "numbers", "exposure_sec", "avg_active_sec"}, len(report), report, func() [][]string {
+		rows := make([][]string, 0, len(report))
+		for _, row := range report {
+			rows = append(rows, []string{formatReportInt(row.ID), row.Name, strconv.Itoa(row.Activations), strconv.Itoa(row.Numbers), formatReportFloat(row.ExposureSec), formatReportFloat(row.AvgActiveSec)})
+		}
+		return rows
+	})
+}
+
+// Activations report godoc
+// @Summary      Activations per day
+// @Description  Number activations per day and team with average active time of numbers activated that day
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Param from query string true "Period start: 2006-01-02 or 2006-01-02 15:04"
+// @Param to query string true "Period end, date without time includes the whole day"
+// @Param team_id query int false "Team ID"
+// @Param vendor_id query int false "Vendor ID"
+// @Param pool_id query int false "Pool ID"
+// @Param format query string false "json or csv" default(json)
+// @Success      200  {object}   model.SwaggerActivationsReport
+// @Router       /reports/activations [get]
+// @Security ApiKeyAuth
+func ActivationsReport(db *sqlx.DB, c *gin.Context) {
+	if !checkReportFormat(c) {
+		return
+	}
+
+	from, to, err := parseReportPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid period", "error": err.Error()})
+		return
+	}
+
+	query := `SELECT to_char(l.start_at AT TIME ZONE $4, 'YYYY-MM-DD') AS day,
+			l.team_id, t.name AS team,
+			COUNT(l.id) AS activations,
+			COUNT(DISTINCT l.number_id) AS numbers,
+			ROUND(COALESCE(AVG(EXTRACT(EPOCH FROM COALESCE(l.end_at, now()) - l.start_at)), 0)::numeric, 2)::float8 AS avg_active_sec
+			` + reportLogsFrom + `
+			WHERE l.event = $3 AND l.start_at >= $1 AND l.start_at < $2`
+	args := []interface{}{from, to, logEventActivation, config.Rotate.TimeZone}
+
+	_, err = addReportFilters(c, &query, "l.", 5, &args)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid filter", "error": err.Error()})
+		return
+	}
+	query += " GROUP BY 1, 2, 3 ORDER BY 1, 3"
+
+	var report []model.ActivationsDay
+	err = db.Select(&report, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to build activations report", "error": err.Error()})
+		return
+	}
+
+	writeReport(c, "activations", []string{"day", "team_id", "team", "activations", "numbers", "avg_active_sec"}, len(report), report, func() [][]string {
+		rows := make([][]string, 0, len(report))
+		for _, row := range report {
+			rows = append(rows, []string{row.Day, formatReportInt(row.TeamID), formatReportString(row.Team), strconv.Itoa(row.Activations), strconv.Itoa(row.Numbers), formatReportFloat(row.AvgActiveSec)})
+		}
+		return rows
+	})
+}
+
+// Unused numbers report godoc
+// @Summary      Never used numbers
+// @Description  Numbers that were never activated, or not activated in the period when from and to are set
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Param from query string false "Period start: 2006-01-02 or 2006-01-02 15:04"
+// @Param to query string false "Period end, date without time includes the whole day"
+// @Param team_id query int false "Team ID"
+// @Param vendor_id query int false "Vendor ID"
+// @Param pool_id query int false "Pool ID"
+// @Param format query string false "json or csv" default(json)
+// @Success      200  {object}   model.SwaggerUnusedReport
+// @Router       /reports/unused [get]
+// @Security ApiKeyAuth
+func UnusedNumbersReport(db *sqlx.DB, c *gin.Context) {
+	if !checkReportFormat(c) {
+		return
+	}
+
+	args := []interface{}{logEventActivation}
+	period := ""
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, err := parseReportPeriod(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid period", "error": err.Error()})
+			return
+		}
+		period = " AND l.start_at < $3 AND COALESCE(l.end_at, now()) > $2"
+		args = append(args, from, to)
+	}
+
+	query := `SELECT n.id, n.value AS number, n.state, p.name AS pool, t.name AS team, v.name AS vendor
+			FROM nc.numbers AS n
+			LEFT JOIN nc.pools AS p ON n.pool_id=p.id
+			LEFT JOIN nc.teams AS t ON n.team_id=t.id
+			LEFT JOIN nc.vendors AS v ON n.vendor_id=v.id
+			WHERE NOT EXISTS (SELECT 1 FROM nc.logs AS l WHERE l.number_id = n.id AND l.event = $1` + period + `)`
+
+	_, err := addReportFilters(c, &query, "n.", len(args)+1, &args)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid filter", "error": err.Error()})
+		return
+	}
+	query += " ORDER BY n.pool_id, n.id"
+
+	var report []model.UnusedNumber
+	err = db.Select(&report, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to build unused numbers report", "error": err.Error()})
+		return
+	}
+
+	writeReport(c, "unused", []string{"id", "number", "state", "pool", "team", "vendor"}, len(report), report, func() [][]string {
+		rows := make([][]string, 0, len(report))
+		for _, row := range report {
+			rows = append(rows, []string{strconv.Itoa(row.ID), row.Number, row.State, formatReportString(row.Pool), formatReportString(row.Team), formatReportString(row.Vendor)})
+		}
+		return rows
+	})
+}
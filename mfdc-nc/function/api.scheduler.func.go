@@ -15,7 +15,7 @@ import (
 // @Accept       json
 // @Produce      json
 // @Success      200  {array}   model.SwaggerDefaultResponse
-// @Param route body model.Scheduler true "Schedule, time format: 00:00:00+03"
+// @Param route body model.Scheduler true "Schedule: start_time/stop_time (00:00:00+03), cron (* 9-18 * * 1-5) or windows ([{days: [1,2,3,4,5], start: 09:00, stop: 13:00}]), exceptions ([2025-01-01])"
 // @Router       /schedule/add [post]
 // @Security ApiKeyAuth
 func AddSchedule(db *sqlx.DB, c *gin.Context) {
@@ -28,13 +28,18 @@ func AddSchedule(db *sqlx.DB, c *gin.Context) {
 		return
 	}
 
-	if request.Name == nil || request.PeriodicSecond == nil || request.StartTime == nil || request.StopTime == nil || request.TeamID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "All columns are required"})
+	if request.Name == nil || request.PeriodicSecond == nil || request.TeamID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Columns name, periodic_sec and team_id are required"})
+		return
+	}
+
+	if err := validateSchedule(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid schedule", "error": err.Error()})
 		return
 	}
 
 	var scheduleID int
-	err := db.QueryRow("INSERT INTO nc.scheduler (name, start_time, stop_time, periodic_sec, team_id) VALUES ($1, $2, $3, $4, $5) RETURNING id", *request.Name, *request.StartTime, *request.StopTime, *request.PeriodicSecond, *request.TeamID).Scan(&scheduleID)
+	err := db.QueryRow("INSERT INTO nc.scheduler (name, start_time, stop_time, cron, windows, exceptions, periodic_sec, team_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id", *request.Name, request.StartTime, request.StopTime, request.Cron, request.Windows, request.Exceptions, *request.PeriodicSecond, *request.TeamID).Scan(&scheduleID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to insert new schedule", "error": err})
 		return
@@ -70,7 +75,7 @@ func SchedulsList(db *sqlx.DB, c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Schedule ID"
-// @Param ip body model.Scheduler true "Data without ID"
+// @Param ip body model.Scheduler true "Data without ID, empty cron/windows/exceptions clear the value"
 // @Success      200  {array}   model.SwaggerDefaultResponse
 // @Router       /schedule/edit/{id} [patch]
 // @Security ApiKeyAuth
@@ -113,8 +118,25 @@ func ScheduleEdit(db *sqlx.DB, c *gin.Context) {
 	if request.TeamID == nil {
 		request.TeamID = data.TeamID
 	}
+	// Пустые значения (cron: "", windows: [], exceptions: []) очищают поле
+	if request.Cron == nil {
+		request.Cron = data.Cron
+	} else if *request.Cron == "" {
+		request.Cron = nil
+	}
+	if request.Windows == nil {
+		request.Windows = data.Windows
+	}
+	if request.Exceptions == nil {
+		request.Exceptions = data.Exceptions
+	}
+
+	if err := validateSchedule(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid schedule", "error": err.Error()})
+		return
+	}
 
-	_, err = db.Exec("UPDATE nc.scheduler SET name = $1, start_time = $2, stop_time = $3, periodic_sec = $4, team_id = $5 WHERE id = $6", request.Name, request.StartTime, request.StopTime, request.PeriodicSecond, request.TeamID, id)
+	_, err = db.Exec("UPDATE nc.scheduler SET name = $1, start_time = $2, stop_time = $3, cron = $4, windows = $5, exceptions = $6, periodic_sec = $7, team_id = $8 WHERE id = $9", request.Name, request.StartTime, request.StopTime, request.Cron, request.Windows, request.Exceptions, request.PeriodicSecond, request.TeamID, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to update schedule", "error": err.Error()})
 		return
@@ -160,3 +182,31 @@ func ScheduleDelete(db *sqlx.DB, c *gin.Context) {
 	// Отправляем JSON-ответ
 	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Schedule successfully deleted"})
 }
+
+// Simulate schedules godoc
+// @Summary      Simulate rotation schedules
+// @Description  Dry-run of schedule rotation over the next N hours without touching Webitel or DB. Draft schedule replaces saved schedules of its team
+// @Tags         Scheduler
+// @Accept       json
+// @Produce      json
+// @Param data body model.SimulateRequest true "Hours (1-168, default 24), team filter and optional draft schedule"
+// @Success      200  {array}   model.SwaggerSimulateResponse
+// @Router       /schedule/simulate [post]
+// @Security ApiKeyAuth
+func SimulateSchedule(db *sqlx.DB, c *gin.Context) {
+	var request model.SimulateRequest
+
+	// Чтение данных из тела запроса
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid request data", "error": err.Error()})
+		return
+	}
+
+	data, err := SimulateRotation(db, request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to simulate rotation", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": data})
+}
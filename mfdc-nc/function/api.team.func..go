@@ -52,8 +52,19 @@ func TeamNumberRotate(db *sqlx.DB, c *gin.Context) {
 		return
 	}
 
+	// На паузе или с закреплённым номером DailyRotation() ничего не сделает
+	hold, err := checkTeamHold(db, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to manual rotate", "error": err.Error()})
+		return
+	}
+	if hold != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Rotation is on hold for team ID " + team_id, "error": hold})
+		return
+	}
+
 	// Вызываем функцию ротации
-	err = DailyRotation(db, id, nil)
+	err = rotateTeam(db, id, nil)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to manual rotate", "error": err.Error()})
 		return
@@ -62,6 +73,294 @@ func TeamNumberRotate(db *sqlx.DB, c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "New number has been manually rotated for team ID " + team_id})
 }
 
+// Team resources drift godoc
+// @Summary      Team resources drift
+// @Description  Compare numbers in team Webitel resources with active numbers in NC and list mismatches. Read-only, use POST /teams/{id}/drift/repush to fix them
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Success      200  {array}   model.SwaggerResourceDrift
+// @Router       /teams/{id}/drift [get]
+// @Security ApiKeyAuth
+func TeamDrift(db *sqlx.DB, c *gin.Context) {
+	teamResourcesDrift(db, c, false)
+}
+
+// Team resources drift repush godoc
+// @Summary      Repush team resources drift
+// @Description  Compare numbers in team Webitel resources with active numbers in NC and re-push active number to drifted resources
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Success      200  {array}   model.SwaggerResourceDrift
+// @Router       /teams/{id}/drift/repush [post]
+// @Security ApiKeyAuth
+func TeamDriftRepush(db *sqlx.DB, c *gin.Context) {
+	teamResourcesDrift(db, c, true)
+}
+
+// Сверка ресурсов команды, с исправлением расхождений только для POST
+func teamResourcesDrift(db *sqlx.DB, c *gin.Context, repush bool) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	drifts, err := checkTeamResources(db, id, repush)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to check team resources", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": drifts})
+}
+
+// Team rotation worker godoc
+// @Summary      Team rotation worker
+// @Description  State of team rotation worker: last run, last error and next run
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Success      200  {object}   model.SwaggerTeamWorker
+// @Router       /teams/{id}/worker [get]
+// @Security ApiKeyAuth
+func TeamWorker(db *sqlx.DB, c *gin.Context) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	status, err := getTeamWorker(db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get team worker", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": status})
+}
+
+// Team rotation control godoc
+// @Summary      Team rotation control
+// @Description  Rotation pause and pinned number of team
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Success      200  {object}   model.SwaggerTeamControl
+// @Router       /teams/{id}/control [get]
+// @Security ApiKeyAuth
+func TeamControl(db *sqlx.DB, c *gin.Context) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	control, err := getTeamControl(db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get team control", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": control})
+}
+
+// Pause team rotation godoc
+// @Summary      Pause team rotation
+// @Description  Pause team rotation until the given time or until resume. Audited in logs with user email
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Param data body model.TeamPauseRequest true "Pause"
+// @Success      200  {object}   model.SwaggerTeamControl
+// @Router       /teams/{id}/pause [post]
+// @Security ApiKeyAuth
+func TeamPause(db *sqlx.DB, c *gin.Context) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	var request model.TeamPauseRequest
+	// Чтение данных из тела запроса
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid request data", "error": err.Error()})
+		return
+	}
+	if request.Until != nil && !request.Until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Until must be in the future"})
+		return
+	}
+
+	_, err = getTeamByID(db, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Team not found", "error": err.Error()})
+		return
+	}
+
+	control, err := pauseTeam(db, id, request.Until, request.Reason, userEmail(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to pause rotation", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Rotation paused for team ID " + team_id, "data": control})
+}
+
+// Resume team rotation godoc
+// @Summary      Resume team rotation
+// @Description  Resume paused team rotation. Audited in logs with user email
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Param data body model.TeamResumeRequest false "Resume"
+// @Success      200  {object}   model.SwaggerDefaultResponse
+// @Router       /teams/{id}/resume [post]
+// @Security ApiKeyAuth
+func TeamResume(db *sqlx.DB, c *gin.Context) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	// Тело запроса необязательное
+	var request model.TeamResumeRequest
+	c.ShouldBindJSON(&request)
+	if request.Reason == "" {
+		request.Reason = "manual"
+	}
+
+	resumed, err := clearTeamPause(db, id, false, request.Reason, userEmail(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to resume rotation", "error": err.Error()})
+		return
+	}
+	if !resumed {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Rotation is not paused for team ID " + team_id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Rotation resumed for team ID " + team_id})
+}
+
+// Pin team number godoc
+// @Summary      Pin team number
+// @Description  Push the number to team resources and keep it until the given time or until unpin. Audited in logs with user email
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Param data body model.TeamPinRequest true "Pin"
+// @Success      200  {object}   model.SwaggerTeamControl
+// @Router       /teams/{id}/pin [post]
+// @Security ApiKeyAuth
+func TeamPin(db *sqlx.DB, c *gin.Context) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	var request model.TeamPinRequest
+	// Чтение данных из тела запроса
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid request data", "error": err.Error()})
+		return
+	}
+	if request.Until != nil && !request.Until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Until must be in the future"})
+		return
+	}
+
+	var numberID int
+	switch {
+	case request.NumberID != nil:
+		numberID = *request.NumberID
+	case request.Number != nil:
+		err = db.Get(&numberID, "SELECT id FROM nc.numbers WHERE value = $1 AND team_id = $2 LIMIT 1", *request.Number, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Number not found in team ID " + team_id})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get number", "error": err.Error()})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Number or number_id is required"})
+		return
+	}
+
+	control, err := pinTeamNumber(db, id, numberID, request.Until, request.Reason, userEmail(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to pin number", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Number pinned for team ID " + team_id, "data": control})
+}
+
+// Unpin team number godoc
+// @Summary      Unpin team number
+// @Description  Return team to scheduled rotation. Audited in logs with user email
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Team ID"
+// @Param data body model.TeamResumeRequest false "Unpin"
+// @Success      200  {object}   model.SwaggerDefaultResponse
+// @Router       /teams/{id}/unpin [post]
+// @Security ApiKeyAuth
+func TeamUnpin(db *sqlx.DB, c *gin.Context) {
+	// Получение ID Team из URL
+	team_id := c.Param("id")
+	id, err := strconv.Atoi(team_id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+		return
+	}
+
+	// Тело запроса необязательное
+	var request model.TeamResumeRequest
+	c.ShouldBindJSON(&request)
+	if request.Reason == "" {
+		request.Reason = "manual"
+	}
+
+	unpinned, err := clearTeamPin(db, id, false, request.Reason, userEmail(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to unpin number", "error": err.Error()})
+		return
+	}
+	if !unpinned {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "No pinned number for team ID " + team_id})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Number unpinned for team ID " + team_id})
+}
+
 // List active teams numbers godoc
 // @Summary      Active teams numbers
 // @Description  Active teams numbers
@@ -217,7 +516,7 @@ func TeamsDayNumbers(db *sqlx.DB, c *gin.Context) {
 				}
 
 				logs = nil // Очистка массива перед использованием
-				err = db.Select(&logs, "SELECT start_at, end_at FROM nc.logs WHERE number_id = $1 ORDER BY start_at", number.ID)
+				err = db.Select(&logs, "SELECT start_at, end_at FROM nc.logs WHERE number_id = $1 AND event = $2 ORDER BY start_at", number.ID, logEventActivation)
 				if err != nil {
 					c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to get logs for number", "error": err.Error()})
 					return
@@ -260,8 +559,18 @@ func AddTeam(db *sqlx.DB, c *gin.Context) {
 		return
 	}
 
+	backend := backendWebitel
+	if request.Backend != nil && *request.Backend != "" {
+		backend = *request.Backend
+	}
+	if err := validateTeamBackend(backend); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid backend", "error": err.Error()})
+		return
+	}
+	request.Backend = &backend
+
 	var teamID int
-	err := db.QueryRow("INSERT INTO nc.teams (name, webitel_res_ids) VALUES ($1, $2) RETURNING id", *request.Name, *request.WebitelResourceIDS).Scan(&teamID)
+	err := db.QueryRow("INSERT INTO nc.teams (name, webitel_res_ids, backend) VALUES ($1, $2, $3) RETURNING id", *request.Name, *request.WebitelResourceIDS, backend).Scan(&teamID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to insert new team", "error": err.Error()})
 		return
@@ -316,7 +625,14 @@ func TeamEdit(db *sqlx.DB, c *gin.Context) {
 		WebitelResourceIDS = *request.WebitelResourceIDS
 	}
 
-	_, err = db.Exec("UPDATE nc.teams SET name = $1, webitel_res_ids = $2 WHERE id = $3", request.Name, WebitelResourceIDS, id)
+	if request.Backend == nil {
+		request.Backend = data.Backend
+	} else if err := validateTeamBackend(*request.Backend); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid backend", "error": err.Error()})
+		return
+	}
+
+	_, err = db.Exec("UPDATE nc.teams SET name = $1, webitel_res_ids = $2, backend = $3 WHERE id = $4", request.Name, WebitelResourceIDS, request.Backend, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to update team", "error": err.Error()})
 		return
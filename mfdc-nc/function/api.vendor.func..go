@@ -1,8 +1,10 @@
 package function
 
 import (
+	"database/sql"
 	"nc/model"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -53,8 +55,13 @@ func AddVendor(db *sqlx.DB, c *gin.Context) {
 		return
 	}
 
+	if request.QuarantineDays != nil && *request.QuarantineDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Quarantine days must be positive"})
+		return
+	}
+
 	var vendorID int
-	err := db.QueryRow("INSERT INTO nc.vendors (name) VALUES ($1) RETURNING id", *request.Name).Scan(&vendorID)
+	err := db.QueryRow("INSERT INTO nc.vendors (name, quarantine_days) VALUES ($1, $2) RETURNING id", *request.Name, request.QuarantineDays).Scan(&vendorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to insert new vendor", "error": err.Error()})
 		return
@@ -100,8 +107,14 @@ func VendorEdit(db *sqlx.DB, c *gin.Context) {
 	if request.Name == nil {
 		request.Name = data.Name
 	}
+	if request.QuarantineDays == nil {
+		request.QuarantineDays = data.QuarantineDays
+	} else if *request.QuarantineDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Quarantine days must be positive"})
+		return
+	}
 
-	_, err = db.Exec("UPDATE nc.vendors SET name = $1 WHERE id = $2", request.Name, id)
+	_, err = db.Exec("UPDATE nc.vendors SET name = $1, quarantine_days = $2 WHERE id = $3", request.Name, request.QuarantineDays, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to update vendor", "error": err.Error()})
 		return
@@ -147,3 +160,115 @@ func VendorDelete(db *sqlx.DB, c *gin.Context) {
 	// Отправляем JSON-ответ
 	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Vendor successfully deleted"})
 }
+
+// Vendor failover godoc
+// @Summary      Vendor failover
+// @Description  Called by VC when team switched to another vendor: activates the vendor pool and pushes a fresh number to its Webitel resources
+// @Tags         Vendors
+// @Accept       json
+// @Produce      json
+// @Param data body model.VendorFailoverRequest true "Team and new actual vendor, by ID or by name"
+// @Success      200  {object}   model.SwaggerVendorFailover
+// @Router       /vendors/failover [post]
+// @Security ApiKeyAuth
+func VendorFailover(db *sqlx.DB, c *gin.Context) {
+	var request model.VendorFailoverRequest
+
+	// Чтение данных из тела запроса
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Invalid request data", "error": err.Error()})
+		return
+	}
+
+	// Команда и вендор по ID или по имени, как их передаёт VC
+	var teamID, vendorID int
+	var err error
+	switch {
+	case request.TeamID != nil:
+		err = db.Get(&teamID, "SELECT id FROM nc.teams WHERE id=$1", *request.TeamID)
+	case request.Team != nil:
+		err = db.Get(&teamID, "SELECT id FROM nc.teams WHERE name=$1", *request.Team)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team or team_id is required"})
+		return
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Team not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get team", "error": err.Error()})
+		return
+	}
+
+	switch {
+	case request.VendorID != nil:
+		err = db.Get(&vendorID, "SELECT id FROM nc.vendors WHERE id=$1", *request.VendorID)
+	case request.Vendor != nil:
+		vendorID, err = getVendorIDByName(db, *request.Vendor)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Vendor or vendor_id is required"})
+		return
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Vendor not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get vendor", "error": err.Error()})
+		return
+	}
+
+	failover, err := switchActualVendor(db, teamID, vendorID, failoverWebhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Vendor failover failed", "error": err.Error(), "data": failover})
+		return
+	}
+	if failover == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Vendor is already actual for team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Vendor failover completed", "data": failover})
+}
+
+// Vendor failovers list godoc
+// @Summary      Vendor failovers
+// @Description  History of vendor failovers
+// @Tags         Vendors
+// @Accept       json
+// @Produce      json
+// @Param team_id query int false "Team ID"
+// @Param limit query int false "Records" default(100)
+// @Success      200  {object}   model.SwaggerVendorFailoverList
+// @Router       /vendors/failover/list [get]
+// @Security ApiKeyAuth
+func VendorFailoverList(db *sqlx.DB, c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	query := "SELECT * FROM nc.vendor_failovers WHERE 1=1"
+	var args []interface{}
+	if teamStr := c.Query("team_id"); teamStr != "" {
+		teamID, err := strconv.Atoi(teamStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Team ID must be a number", "error": err.Error()})
+			return
+		}
+		addCondition(&query, "team_id", 1, &args, teamID)
+	}
+	query += " ORDER BY created_at DESC LIMIT " + strconv.Itoa(limit)
+
+	failovers := []model.VendorFailover{}
+	err := db.Select(&failovers, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get vendor failovers", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": failovers})
+}
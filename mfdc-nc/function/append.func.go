@@ -0,0 +1,95 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+)
+
+// Заполненность сабпула пула
+type subPoolFill struct {
+	ID    int `db:"id"`
+	Index int `db:"index"`
+	Count int `db:"num_count"`
+}
+
+// Дозагрузка номеров в существующий пул: сначала добиваем неполные сабпулы до subpool_block,
+// остаток раскладываем по новым неактивным сабпулам, которые ротация подхватит сама
+func appendNumbersToPool(db dbExecutor, poolID int, values []string) error {
+	// Блокируем пул, чтобы параллельные дозагрузки не разложили номера в одни и те же сабпулы
+	var pool model.Pool
+	err := db.Get(&pool, "SELECT * FROM nc.pools WHERE id=$1 FOR UPDATE", poolID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("pool %v not found", poolID)
+		}
+		return fmt.Errorf("failed to get pool %v: %w", poolID, err)
+	}
+	if pool.SubPoolBlock == nil || *pool.SubPoolBlock <= 0 {
+		return fmt.Errorf("pool %v has no subpool block", poolID)
+	}
+	if pool.VendorID == nil || pool.TeamID == nil {
+		return fmt.Errorf("pool %v has no vendor or team", poolID)
+	}
+	block := *pool.SubPoolBlock
+
+	var subPools []subPoolFill
+	err = db.Select(&subPools, `SELECT sp.id, sp.index, COUNT(n.id) AS num_count
+		FROM nc.subpools AS sp
+		LEFT JOIN nc.numbers AS n ON n.subpool_id = sp.id
+		WHERE sp.pool_id = $1
+		GROUP BY sp.id, sp.index
+		ORDER BY sp.index, sp.id`, poolID)
+	if err != nil {
+		return fmt.Errorf("failed to get subpools for pool %v: %w", poolID, err)
+	}
+
+	nextIndex := 0
+	for _, subPool := range subPools {
+		if subPool.Index >= nextIndex {
+			nextIndex = subPool.Index + 1
+		}
+
+		// Добиваем неполный сабпул
+		for subPool.Count < block && len(values) > 0 {
+			_, err = createNumber(db, values[0], false, poolID, subPool.ID, *pool.VendorID, *pool.TeamID)
+			if err != nil {
+				return err
+			}
+			values = values[1:]
+			subPool.Count++
+		}
+	}
+
+	// Остаток номеров раскладываем по новым сабпулам
+	for len(values) > 0 {
+		subPoolID, err := createSubPool(db, poolID, nextIndex)
+		if err != nil {
+			return err
+		}
+		nextIndex++
+
+		end := block
+		if end > len(values) {
+			end = len(values)
+		}
+		for _, value := range values[:end] {
+			_, err = createNumber(db, value, false, poolID, subPoolID, *pool.VendorID, *pool.TeamID)
+			if err != nil {
+				return err
+			}
+		}
+		values = values[end:]
+	}
+
+	// Пересчитываем счётчики пула
+	_, err = db.Exec(`UPDATE nc.pools SET
+		num_count = (SELECT COUNT(id) FROM nc.numbers WHERE pool_id = $1),
+		subpool_count = (SELECT COUNT(id) FROM nc.subpools WHERE pool_id = $1)
+		WHERE id = $1`, poolID)
+	if err != nil {
+		return fmt.Errorf("failed to update pool %v counters: %w", poolID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Бэкенды доставки номера в АТС
+const (
+	backendWebitel = "webitel" // Ресурсы Webitel через API (по умолчанию)
+	backendDB      = "db"      // Таблица nc.caller_ids, которую читает Asterisk/FreeSWITCH (realtime, ODBC)
+	backendFile    = "file"    // Файл <dialer.file_dir>/<resource_id> с номером для диалплана
+	backendMemory  = "memory"  // Хранение в памяти процесса, для проверки ротации без АТС
+)
+
+// Бэкенд АТС, в который ротация отправляет номер. ID ресурсов берутся из nc.teams.webitel_res_ids
+type DialerBackend interface {
+	Name() string
+	SetCallerID(resourceID int, number string) error // Установить номер в ресурс вместо текущего
+	GetCallerIDs(resourceID int) ([]string, error)   // Прочитать номера, установленные в ресурсе
+}
+
+// Webitel: номер выставляется как display ресурса call_center
+type webitelBackend struct{}
+
+func (webitelBackend) Name() string {
+	return backendWebitel
+}
+
+func (webitelBackend) SetCallerID(resourceID int, number string) error {
+	return putToWebitel(resourceID, number)
+}
+
+func (webitelBackend) GetCallerIDs(resourceID int) ([]string, error) {
+	return getWebitelDisplays(resourceID)
+}
+
+// Asterisk/FreeSWITCH через БД: по одной строке на ресурс
+type dbBackend struct {
+	db *sqlx.DB
+}
+
+func (dbBackend) Name() string {
+	return backendDB
+}
+
+func (b dbBackend) SetCallerID(resourceID int, number string) error {
+	_, err := b.db.Exec(`INSERT INTO nc.caller_ids (resource_id, "number", updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (resource_id) DO UPDATE SET "number" = EXCLUDED."number", updated_at = EXCLUDED.updated_at`, resourceID, number, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set caller ID for resource %v: %w", resourceID, err)
+	}
+	return nil
+}
+
+func (b dbBackend) GetCallerIDs(resourceID int) ([]string, error) {
+	var number string
+	err := b.db.Get(&number, `SELECT "number" FROM nc.caller_ids WHERE resource_id = $1`, resourceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get caller ID for resource %v: %w", resourceID, err)
+	}
+	return []string{number}, nil
+}
+
+// Asterisk/FreeSWITCH через файл: номер пишется во временный файл и атомарно подменяет старый
+type fileBackend struct {
+	dir string
+}
+
+func (fileBackend) Name() string {
+	return backendFile
+}
+
+func (b fileBackend) path(resourceID int) string {
+	return filepath.Join(b.dir, strconv.Itoa(resourceID))
+}
+
+func (b fileBackend) SetCallerID(resourceID int, number string) error {
+	if b.dir == "" {
+		return fmt.Errorf("dialer file_dir is not configured")
+	}
+
+	tmp := b.path(resourceID) + ".tmp"
+	err := os.WriteFile(tmp, []byte(number+"\n"), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write caller ID for resource %v: %w", resourceID, err)
+	}
+
+	err = os.Rename(tmp, b.path(resourceID))
+	if err != nil {
+		return fmt.Errorf("failed to replace caller ID file for resource %v: %w", resourceID, err)
+	}
+	return nil
+}
+
+func (b fileBackend) GetCallerIDs(resourceID int) ([]string, error) {
+	data, err := os.ReadFile(b.path(resourceID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read caller ID for resource %v: %w", resourceID, err)
+	}
+
+	number := strings.TrimSpace(string(data))
+	if number == "" {
+		return []string{}, nil
+	}
+	return []string{number}, nil
+}
+
+// Бэкенд в памяти: запоминает установленные номера и историю отправок
+type MemoryBackend struct {
+	mu      sync.Mutex
+	numbers map[int]string
+	history []string
+	fail    map[int]error
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{numbers: make(map[int]string), fail: make(map[int]error)}
+}
+
+func (b *MemoryBackend) Name() string {
+	return backendMemory
+}
+
+func (b *MemoryBackend) SetCallerID(resourceID int, number string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.fail[resourceID]; err != nil {
+		return err
+	}
+	b.numbers[resourceID] = number
+	b.history = append(b.history, fmt.Sprintf("%d:%s", resourceID, number))
+	return nil
+}
+
+func (b *MemoryBackend) GetCallerIDs(resourceID int) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if number, ok := b.numbers[resourceID]; ok {
+		return []string{number}, nil
+	}
+	return []string{}, nil
+}
+
+// Ошибка, которую вернёт отправка в ресурс (nil - снять ошибку), для проверки компенсации
+func (b *MemoryBackend) FailResource(resourceID int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		delete(b.fail, resourceID)
+		return
+	}
+	b.fail[resourceID] = err
+}
+
+// История отправок в формате resource_id:number
+func (b *MemoryBackend) History() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]string(nil), b.history...)
+}
+
+// Общий экземпляр бэкенда в памяти для команд с backend = memory
+var memoryBackend = NewMemoryBackend()
+
+// Бэкенд в памяти, через который проходят номера команд с backend = memory
+func GetMemoryBackend() *MemoryBackend {
+	return memoryBackend
+}
+
+// Создание бэкенда по имени, пустое имя - Webitel
+func newDialerBackend(db *sqlx.DB, name string) (DialerBackend, error) {
+	switch name {
+	case "", backendWebitel:
+		return webitelBackend{}, nil
+	case backendDB:
+		return dbBackend{db: db}, nil
+	case backendFile:
+		return fileBackend{dir: config.Dialer.FileDir}, nil
+	case backendMemory:
+		return memoryBackend, nil
+	}
+	return nil, fmt.Errorf("unknown dialer backend %q, available: webitel, db, file, memory", name)
+}
+
+// Проверка бэкенда из запроса команды. memory доступен только при api.debug_mode: номера из него не доходят до АТС
+func validateTeamBackend(name string) error {
+	if name == backendMemory && !config.API.DebugMode {
+		return fmt.Errorf("dialer backend %q is available only with api.debug_mode", name)
+	}
+	_, err := newDialerBackend(nil, name)
+	return err
+}
+
+// Бэкенд, выбранный для команды в nc.teams.backend
+func getTeamBackend(db *sqlx.DB, teamID int) (DialerBackend, error) {
+	var name sql.NullString
+	err := db.Get(&name, "SELECT backend FROM nc.teams WHERE id = $1", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backend for team %v: %w", teamID, err)
+	}
+	return newDialerBackend(db, name.String)
+}
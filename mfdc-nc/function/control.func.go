@@ -0,0 +1,430 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Действия супервизора в nc.logs.event
+const (
+	logEventPause  = "pause"  // Ротация команды приостановлена
+	logEventResume = "resume" // Ротация команды возобновлена
+	logEventPin    = "pin"    // Номер закреплён за командой
+	logEventUnpin  = "unpin"  // Номер откреплён
+)
+
+var teamActionComments = map[string]string{
+	logEventPause:  "Rotation paused",
+	logEventResume: "Rotation resumed",
+	logEventPin:    "Number pinned",
+	logEventUnpin:  "Number unpinned",
+}
+
+// Пауза и закреплённый номер команды, без записи - ротация идёт по расписанию
+func getTeamControl(db dbExecutor, teamID int) (model.TeamControl, error) {
+	var control model.TeamControl
+	err := db.Get(&control, `SELECT c.*, n.value AS pinned_number FROM nc.team_controls AS c
+		LEFT JOIN nc.numbers AS n ON n.id = c.pinned_number_id WHERE c.team_id = $1`, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.TeamControl{TeamID: teamID}, nil
+		}
+		return control, fmt.Errorf("failed to get team %v control: %w", teamID, err)
+	}
+	return control, nil
+}
+
+// Запись действия супервизора в nc.logs, email пустой для снятия по истечении срока
+func logTeamAction(db dbExecutor, teamID int, numberID *int, event string, reason string, email *string) error {
+	var err error
+	now := time.Now()
+	if numberID != nil {
+		_, err = db.Exec(`INSERT INTO nc.logs (number_id, subpool_id, pool_id, vendor_id, team_id, start_at, end_at, comment, event, reason, email)
+			SELECT id, subpool_id, pool_id, vendor_id, $2, $3, $3, $4, $5, $6, $7 FROM nc.numbers WHERE id = $1`,
+			*numberID, teamID, now, teamActionComments[event], event, reason, email)
+	} else {
+		_, err = db.Exec(`INSERT INTO nc.logs (team_id, start_at, end_at, comment, event, reason, email) VALUES ($1, $2, $2, $3, $4, $5, $6)`,
+			teamID, now, teamActionComments[event], event, reason, email)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to log %s for team %v: %w", event, teamID, err)
+	}
+	return nil
+}
+
+// Причина, по которой ротация команды не выполняется, пустая строка - ротировать можно.
+// Истёкшие пауза и закрепление снимаются здесь же
+func checkTeamHold(db *sqlx.DB, teamID int) (string, error) {
+	control, err := getTeamControl(db, teamID)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+
+	if control.PausedAt != nil {
+		if control.PausedUntil == nil || control.PausedUntil.After(now) {
+			return "rotation paused" + holdUntil(control.PausedUntil), nil
+		}
+		_, err = clearTeamPause(db, teamID, true, "pause expired", nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if control.PinnedNumberID != nil {
+		if control.PinnedUntil == nil || control.PinnedUntil.After(now) {
+			number := fmt.Sprint(*control.PinnedNumberID)
+			if control.PinnedNumber != nil {
+				number = *control.PinnedNumber
+			}
+			return "number " + number + " pinned" + holdUntil(control.PinnedUntil), nil
+		}
+		_, err = clearTeamPin(db, teamID, true, "pin expired", nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+func holdUntil(until *time.Time) string {
+	if until == nil {
+		return " until further notice"
+	}
+	return " until " + until.Format(time.RFC3339)
+}
+
+// Приостановка ротации команды до until
+func pauseTeam(db *sqlx.DB, teamID int, until *time.Time, reason string, email *string) (model.TeamControl, error) {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return model.TeamControl{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO nc.team_controls (team_id, paused_at, paused_until, paused_by, pause_reason) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (team_id) DO UPDATE SET paused_at = EXCLUDED.paused_at, paused_until = EXCLUDED.paused_until,
+			paused_by = EXCLUDED.paused_by, pause_reason = EXCLUDED.pause_reason`,
+		teamID, time.Now(), until, email, reason)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return model.TeamControl{}, fmt.Errorf("failed to pause team %v: %w", teamID, err)
+	}
+
+	err = logTeamAction(tx, teamID, nil, logEventPause, reason+holdUntil(until), email)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return model.TeamControl{}, err
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return model.TeamControl{}, fmt.Errorf("failed to commit pause: %w", err)
+	}
+
+	OutLog.Printf("Rotation for TeamID %v paused%s", teamID, holdUntil(until))
+	return getTeamControl(db, teamID)
+}
+
+// Снятие паузы, expiredOnly - только если срок паузы истёк. Возвращает false, если команда не на паузе
+func clearTeamPause(db *sqlx.DB, teamID int, expiredOnly bool, reason string, email *string) (bool, error) {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	query := "UPDATE nc.team_controls SET paused_at = NULL, paused_until = NULL, paused_by = NULL, pause_reason = NULL WHERE team_id = $1 AND paused_at IS NOT NULL"
+	if expiredOnly {
+		query += " AND paused_until <= now()"
+	}
+	result, err := tx.Exec(query, teamID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return false, fmt.Errorf("failed to resume team %v: %w", teamID, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		// Паузу уже сняли, например другой запрос или экземпляр
+		tx.Rollback()
+		return false, err
+	}
+
+	err = logTeamAction(tx, teamID, nil, logEventResume, reason, email)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return false, err
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit resume: %w", err)
+	}
+
+	OutLog.Printf("Rotation for TeamID %v resumed: %s", teamID, reason)
+	return true, nil
+}
+
+// Закрепление номера за командой: номер сразу отправляется в ресурсы, ротация не меняет его до until
+func pinTeamNumber(db *sqlx.DB, teamID int, numberID int, until *time.Time, reason string, email *string) (model.TeamControl, error) {
+	var target struct {
+		TeamID   *int   `db:"team_id"`
+		VendorID *int   `db:"vendor_id"`
+		State    string `db:"state"`
+	}
+	err := db.Get(&target, `SELECT p.team_id, p.vendor_id, n.state FROM nc.numbers AS n
+		INNER JOIN nc.pools AS p ON n.pool_id = p.id WHERE n.id = $1`, numberID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.TeamControl{}, fmt.Errorf("number %v not found", numberID)
+		}
+		return model.TeamControl{}, fmt.Errorf("failed to get number %v: %w", numberID, err)
+	}
+	if target.TeamID == nil || *target.TeamID != teamID || target.VendorID == nil {
+		return model.TeamControl{}, fmt.Errorf("number %v is not in a pool of team %v", numberID, teamID)
+	}
+	if target.State != stateActive {
+		return model.TeamControl{}, fmt.Errorf("number %v is %s and can not be pinned", numberID, target.State)
+	}
+
+	// Закрепление и отправка номера выполняются под блокировкой ротации команды,
+	// поэтому шаг ротации увидит закрепление сразу после отправки номера
+	err = withTeamRotationLock(db, teamID, func() error {
+		// Запись закрепления, выполняется в одной транзакции со сменой активного номера
+		pin := func(tx *sqlx.Tx) error {
+			_, err := tx.Exec(`INSERT INTO nc.team_controls (team_id, pinned_number_id, pinned_at, pinned_until, pinned_by, pin_reason) VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (team_id) DO UPDATE SET pinned_number_id = EXCLUDED.pinned_number_id, pinned_at = EXCLUDED.pinned_at,
+					pinned_until = EXCLUDED.pinned_until, pinned_by = EXCLUDED.pinned_by, pin_reason = EXCLUDED.pin_reason`,
+				teamID, numberID, time.Now(), until, email, reason)
+			if err != nil {
+				return fmt.Errorf("failed to pin number %v for team %v: %w", numberID, teamID, err)
+			}
+			return logTeamAction(tx, teamID, &numberID, logEventPin, reason+holdUntil(until), email)
+		}
+
+		// Активность перечитываем под блокировкой: до неё номер мог смениться
+		var active bool
+		err := db.Get(&active, "SELECT COALESCE(active, false) FROM nc.numbers WHERE id = $1", numberID)
+		if err != nil {
+			return fmt.Errorf("failed to get number %v: %w", numberID, err)
+		}
+
+		// Номер, который уже стоит в ресурсах, повторно не отправляем
+		if active {
+			// Начинаем транзакцию
+			tx, err := db.Beginx()
+			if err != nil {
+				return fmt.Errorf("failed to begin transaction: %w", err)
+			}
+			err = pin(tx)
+			if err != nil {
+				tx.Rollback() // Откатываем транзакцию при ошибке
+				return err
+			}
+			// Подтверждаем транзакцию
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("failed to commit pin: %w", err)
+			}
+			return nil
+		}
+
+		number, err := getRotationNumber(db, numberID)
+		if err != nil {
+			return err
+		}
+
+		resourceIDS, err := getVendorResourceIDS(db, teamID, *target.VendorID)
+		if err != nil {
+			return err
+		}
+
+		err = pushPinnedNumber(db, teamID, *target.VendorID, number, resourceIDS, pin)
+		if err != nil {
+			return fmt.Errorf("failed to push pinned number %v: %w", numberID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.TeamControl{}, err
+	}
+
+	OutLog.Printf("Number %v pinned for TeamID %v%s", numberID, teamID, holdUntil(until))
+	return getTeamControl(db, teamID)
+}
+
+// Отправка закреплённого номера в ресурсы команды, вызывается под блокировкой ротации команды.
+// Номер может быть из сабпула, который сейчас не ротируется, поэтому предыдущие номера снимаются
+// по команде и вендору, а не по индексу сабпула. Закрепление pin записывается в той же транзакции
+func pushPinnedNumber(db *sqlx.DB, teamID int, vendorID int, number *model.Number, resourceIDS []int, pin func(tx *sqlx.Tx) error) error {
+	backend, err := getTeamBackend(db, teamID)
+	if err != nil {
+		return err
+	}
+
+	// Номер, который сейчас стоит в ресурсах вендора, нужен для компенсации
+	var prevNumber *string
+	var value string
+	err = db.Get(&value, `SELECT n.value FROM nc.numbers AS n INNER JOIN nc.pools AS p ON n.pool_id = p.id
+		WHERE p.team_id=$1 AND p.vendor_id=$2 AND n.active=$3 ORDER BY n.activated_at DESC NULLS LAST LIMIT 1`, teamID, vendorID, true)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get active number for team %v and vendor %v: %w", teamID, vendorID, err)
+	}
+	if err == nil {
+		prevNumber = &value
+	}
+
+	outboxID, err := createRotationOutbox(db, model.RotationOutbox{
+		TeamID:         teamID,
+		PoolID:         number.PoolID,
+		SubPoolID:      number.SubPoolID,
+		VendorID:       vendorID,
+		NumberID:       number.ID,
+		Number:         number.Value,
+		PreviousNumber: prevNumber,
+		ResourceIDS:    IntArr2PgIntArr(resourceIDS),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Отправляем номер в бэкенд АТС, при ошибке закрепление не записывается
+	touched, pushErr := pushNumberToResources(backend, resourceIDS, number.Value)
+	if pushErr != nil {
+		compensateErr := compensateRotation(db, backend, outboxID, prevNumber, touched, pushErr)
+		if compensateErr != nil {
+			ErrLog.Printf("Rotation %v: %s", outboxID, compensateErr)
+		}
+		return pushErr
+	}
+
+	err = setRotationOutboxStatus(db, outboxID, outboxPushed, nil)
+	if err != nil {
+		return err
+	}
+
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Снимаем активность с номеров команды у вендора и закрываем их записи в логе
+	var previousIDs []int
+	err = tx.Select(&previousIDs, `UPDATE nc.numbers SET active=$1 WHERE active=$2 AND id<>$3
+		AND pool_id IN (SELECT id FROM nc.pools WHERE team_id=$4 AND vendor_id=$5) RETURNING id`, false, true, number.ID, teamID, vendorID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to deactivate numbers of team %v: %w", teamID, err)
+	}
+	for _, previousID := range previousIDs {
+		_, err = tx.Exec("UPDATE nc.logs SET end_at=$1 WHERE number_id=$2 AND end_at IS NULL", time.Now(), previousID)
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			return fmt.Errorf("failed to update end log: %w", err)
+		}
+
+		// После интенсивного использования отправляем номер на охлаждение
+		err = checkNumberCooldown(tx, previousID)
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			return err
+		}
+	}
+
+	err = activateNumber(tx, number)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to activate number %v: %s", number.ID, err)
+	}
+
+	err = addLastNumberID(tx, number)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to add last number ID %v: %s", number.ID, err)
+	}
+
+	err = startLog(tx, model.Logs{
+		NumberID:  number.ID,
+		SubPoolID: number.SubPoolID,
+		PoolID:    number.PoolID,
+		VendorID:  vendorID,
+		TeamID:    teamID,
+		StartAt:   time.Now(),
+		EndAt:     nil,
+		Comment:   "Used",
+	})
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to log activation for number %v: %s", number.ID, err)
+	}
+
+	err = pin(tx)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return err
+	}
+
+	err = setRotationOutboxStatus(tx, outboxID, outboxDone, nil)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return err
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pin: %w", err)
+	}
+
+	OutLog.Printf("Pinned number %s sent to %s resources of TeamID %v", number.Value, backend.Name(), teamID)
+	return nil
+}
+
+// Открепление номера, expiredOnly - только если срок закрепления истёк. Возвращает false, если номер не закреплён
+func clearTeamPin(db *sqlx.DB, teamID int, expiredOnly bool, reason string, email *string) (bool, error) {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	query := `UPDATE nc.team_controls AS c SET pinned_number_id = NULL, pinned_at = NULL, pinned_until = NULL, pinned_by = NULL, pin_reason = NULL
+		FROM (SELECT team_id, pinned_number_id FROM nc.team_controls WHERE team_id = $1 FOR UPDATE) AS old
+		WHERE c.team_id = old.team_id AND c.pinned_at IS NOT NULL`
+	if expiredOnly {
+		query += " AND c.pinned_until <= now()"
+	}
+	var numberID sql.NullInt64
+	err = tx.Get(&numberID, query+" RETURNING old.pinned_number_id", teamID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		if err == sql.ErrNoRows {
+			// Номер уже открепили, например другой запрос или экземпляр
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to unpin number for team %v: %w", teamID, err)
+	}
+
+	var pinnedID *int
+	if numberID.Valid {
+		id := int(numberID.Int64)
+		pinnedID = &id
+	}
+	err = logTeamAction(tx, teamID, pinnedID, logEventUnpin, reason, email)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return false, err
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit unpin: %w", err)
+	}
+
+	OutLog.Printf("Number unpinned for TeamID %v: %s", teamID, reason)
+	return true, nil
+}
@@ -11,12 +11,14 @@ const (
 		"name" varchar NULL,
 		webitel_res_ids jsonb NULL,
 		actual_vendor_id int4 NULL,
+		backend varchar(20) DEFAULT 'webitel' NULL,
 		CONSTRAINT teams_pk PRIMARY KEY (id)
 		);`
 
 	createVendorsTableSQL = `CREATE TABLE IF NOT EXISTS nc.vendors (
 		id serial4 NOT NULL,
 		"name" varchar NULL,
+		quarantine_days int4 NULL,
 		CONSTRAINT vendors_pk PRIMARY KEY (id)
 		);`
 
@@ -34,6 +36,7 @@ const (
 		finish bool DEFAULT false NULL,
 		finish_at timestamptz NULL,
 		sub_activate bool DEFAULT false NULL,
+		strategy varchar(20) DEFAULT 'sequential' NULL,
 		CONSTRAINT pools_pkey PRIMARY KEY (id),
 		CONSTRAINT pools_teams_fk FOREIGN KEY (team_id) REFERENCES nc.teams(id),
 		CONSTRAINT pools_vendors_fk FOREIGN KEY (vendor_id) REFERENCES nc.vendors(id)
@@ -64,6 +67,12 @@ const (
 		used bool DEFAULT false NULL,
 		active bool DEFAULT false NULL,
 		"label" bool DEFAULT false NULL,
+		state varchar(20) DEFAULT 'active' NOT NULL,
+		state_at timestamptz NULL,
+		state_until timestamptz NULL,
+		state_reason varchar NULL,
+		area_code varchar(15) NULL,
+		region varchar NULL,
 		CONSTRAINT numbers_pkey PRIMARY KEY (id),
 		CONSTRAINT numbers_pools_fk FOREIGN KEY (pool_id) REFERENCES nc.pools(id) ON DELETE CASCADE ON UPDATE CASCADE,
 		CONSTRAINT numbers_subpools_fk FOREIGN KEY (subpool_id) REFERENCES nc.subpools(id),
@@ -73,7 +82,7 @@ const (
 
 	createLogsTableSQL = `CREATE TABLE IF NOT EXISTS nc.logs (
 		id bigserial NOT NULL,
-		number_id int8 NOT NULL,
+		number_id int8 NULL,
 		start_at timestamptz NULL,
 		end_at timestamptz NULL,
 		subpool_id int4 NULL,
@@ -81,6 +90,11 @@ const (
 		team_id int4 NULL,
 		vendor_id int4 NULL,
 		"comment" varchar NULL,
+		event varchar(20) DEFAULT 'activation' NOT NULL,
+		state_from varchar(20) NULL,
+		state_to varchar(20) NULL,
+		reason varchar NULL,
+		email varchar NULL,
 		CONSTRAINT logs_pkey PRIMARY KEY (id),
 		CONSTRAINT logs_numbers_fk FOREIGN KEY (number_id) REFERENCES nc.numbers(id),
 		CONSTRAINT logs_pools_fk FOREIGN KEY (pool_id) REFERENCES nc.pools(id) ON DELETE CASCADE,
@@ -96,9 +110,180 @@ const (
 		team_id int4 NULL,
 		"name" varchar NULL,
 		running bool DEFAULT false NULL,
+		cron varchar NULL,
+		windows jsonb NULL,
+		exceptions jsonb NULL,
 		CONSTRAINT scheduler_pk PRIMARY KEY (id),
 		CONSTRAINT scheduler_teams_fk FOREIGN KEY (team_id) REFERENCES nc.teams(id) DEFERRABLE
 		);`
+
+	createRotationOutboxTableSQL = `CREATE TABLE IF NOT EXISTS nc.rotation_outbox (
+		id bigserial NOT NULL,
+		team_id int4 NOT NULL,
+		pool_id int4 NOT NULL,
+		subpool_id int8 NOT NULL,
+		vendor_id int4 NOT NULL,
+		number_id int8 NOT NULL,
+		"number" varchar(50) NOT NULL,
+		prev_number varchar(50) NULL,
+		resource_ids int4[] NULL,
+		status varchar DEFAULT 'pending' NOT NULL,
+		"error" varchar NULL,
+		created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		updated_at timestamptz NULL,
+		CONSTRAINT rotation_outbox_pkey PRIMARY KEY (id),
+		CONSTRAINT rotation_outbox_pools_fk FOREIGN KEY (pool_id) REFERENCES nc.pools(id) ON DELETE CASCADE
+		);`
+
+	createNumberHealthTableSQL = `CREATE TABLE IF NOT EXISTS nc.number_health (
+		id bigserial NOT NULL,
+		number_id int8 NOT NULL,
+		calls int4 DEFAULT 0 NOT NULL,
+		answered int4 DEFAULT 0 NOT NULL,
+		short_calls int4 DEFAULT 0 NOT NULL,
+		rejected int4 DEFAULT 0 NOT NULL,
+		answer_rate float8 DEFAULT 0 NOT NULL,
+		short_call_ratio float8 DEFAULT 0 NOT NULL,
+		reject_ratio float8 DEFAULT 0 NOT NULL,
+		score float8 NULL,
+		calc_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		CONSTRAINT number_health_pkey PRIMARY KEY (id),
+		CONSTRAINT number_health_numbers_fk FOREIGN KEY (number_id) REFERENCES nc.numbers(id) ON DELETE CASCADE
+		);`
+
+	createImportsTableSQL = `CREATE TABLE IF NOT EXISTS nc.imports (
+		id bigserial NOT NULL,
+		pool_name varchar(255) NOT NULL,
+		team_id int4 NOT NULL,
+		vendor_id int4 NOT NULL,
+		subpool_block int4 NOT NULL,
+		format varchar(10) NOT NULL,
+		mode varchar(10) DEFAULT 'create' NOT NULL,
+		status varchar DEFAULT 'pending' NOT NULL,
+		total int4 DEFAULT 0 NOT NULL,
+		accepted_count int4 DEFAULT 0 NOT NULL,
+		rejected_count int4 DEFAULT 0 NOT NULL,
+		"rows" jsonb NULL,
+		email varchar NULL,
+		pool_id int4 NULL,
+		created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		expires_at timestamptz NOT NULL,
+		confirmed_at timestamptz NULL,
+		CONSTRAINT imports_pkey PRIMARY KEY (id)
+		);`
+
+	createPoolAlertsTableSQL = `CREATE TABLE IF NOT EXISTS nc.pool_alerts (
+		id bigserial NOT NULL,
+		pool_id int4 NOT NULL,
+		kind varchar(20) NOT NULL,
+		days_left float8 NULL,
+		message varchar NULL,
+		sent_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		CONSTRAINT pool_alerts_pkey PRIMARY KEY (id),
+		CONSTRAINT pool_alerts_pools_fk FOREIGN KEY (pool_id) REFERENCES nc.pools(id) ON DELETE CASCADE
+		);`
+
+	// Номера для Asterisk/FreeSWITCH, которые читают их из БД (backend = db)
+	createCallerIDsTableSQL = `CREATE TABLE IF NOT EXISTS nc.caller_ids (
+		resource_id int4 NOT NULL,
+		"number" varchar(50) NOT NULL,
+		updated_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		CONSTRAINT caller_ids_pkey PRIMARY KEY (resource_id)
+		);`
+
+	createLeaderLeaseTableSQL = `CREATE TABLE IF NOT EXISTS nc.leader_lease (
+		"name" varchar(50) NOT NULL,
+		instance varchar(255) NOT NULL,
+		acquired_at timestamptz NOT NULL,
+		renewed_at timestamptz NOT NULL,
+		expires_at timestamptz NOT NULL,
+		CONSTRAINT leader_lease_pkey PRIMARY KEY ("name")
+		);`
+
+	createTeamWorkersTableSQL = `CREATE TABLE IF NOT EXISTS nc.team_workers (
+		team_id int4 NOT NULL,
+		state varchar(20) NOT NULL,
+		schedule_id int4 NULL,
+		periodic_sec int4 NULL,
+		instance varchar(255) NULL,
+		started_at timestamptz NULL,
+		last_run_at timestamptz NULL,
+		last_duration_sec float8 NULL,
+		last_error varchar NULL,
+		next_run_at timestamptz NULL,
+		runs int4 DEFAULT 0 NOT NULL,
+		restarts int4 DEFAULT 0 NOT NULL,
+		panics int4 DEFAULT 0 NOT NULL,
+		updated_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		CONSTRAINT team_workers_pkey PRIMARY KEY (team_id)
+		);`
+
+	createVendorFailoversTableSQL = `CREATE TABLE IF NOT EXISTS nc.vendor_failovers (
+		id bigserial NOT NULL,
+		team_id int4 NOT NULL,
+		from_vendor_id int4 NULL,
+		to_vendor_id int4 NOT NULL,
+		pool_id int4 NULL,
+		"number" varchar(50) NULL,
+		source varchar(20) NOT NULL,
+		"error" varchar NULL,
+		created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		CONSTRAINT vendor_failovers_pkey PRIMARY KEY (id)
+		);`
+
+	createNumsMoveBatchesTableSQL = `CREATE TABLE IF NOT EXISTS nc.numsmove_batches (
+		id serial4 NOT NULL,
+		src_pool_id int4 NOT NULL,
+		dst_pool_id int4 NOT NULL,
+		dst_team_id int4 NULL,
+		vendor_id int4 NULL,
+		numbers_count int4 DEFAULT 0 NOT NULL,
+		src_subpool_block int4 NULL,
+		src_subpool_count int4 NULL,
+		dst_subpool_block int4 NULL,
+		dst_subpool_count int4 NULL,
+		created_subpools jsonb DEFAULT '[]'::jsonb NOT NULL,
+		email varchar NULL,
+		created_at timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+		rolled_back_at timestamptz NULL,
+		rolled_back_by varchar NULL,
+		CONSTRAINT numsmove_batches_pkey PRIMARY KEY (id),
+		CONSTRAINT numsmove_batches_src_pools_fk FOREIGN KEY (src_pool_id) REFERENCES nc.pools(id) ON DELETE CASCADE,
+		CONSTRAINT numsmove_batches_dst_pools_fk FOREIGN KEY (dst_pool_id) REFERENCES nc.pools(id) ON DELETE CASCADE
+		);`
+
+	createNumsMoveNumbersTableSQL = `CREATE TABLE IF NOT EXISTS nc.numsmove_numbers (
+		batch_id int4 NOT NULL,
+		number_id int8 NOT NULL,
+		src_pool_id int4 NULL,
+		src_subpool_id int8 NULL,
+		src_team_id int4 NULL,
+		src_vendor_id int4 NULL,
+		src_label bool NULL,
+		src_activated_at timestamptz NULL,
+		src_moved_at timestamptz NULL,
+		dst_subpool_id int8 NULL,
+		CONSTRAINT numsmove_numbers_pkey PRIMARY KEY (batch_id, number_id),
+		CONSTRAINT numsmove_numbers_batches_fk FOREIGN KEY (batch_id) REFERENCES nc.numsmove_batches(id) ON DELETE CASCADE,
+		CONSTRAINT numsmove_numbers_numbers_fk FOREIGN KEY (number_id) REFERENCES nc.numbers(id) ON DELETE CASCADE
+		);`
+
+	// Пауза ротации и закреплённый номер команды
+	createTeamControlsTableSQL = `CREATE TABLE IF NOT EXISTS nc.team_controls (
+		team_id int4 NOT NULL,
+		paused_at timestamptz NULL,
+		paused_until timestamptz NULL,
+		paused_by varchar NULL,
+		pause_reason varchar NULL,
+		pinned_number_id int8 NULL,
+		pinned_at timestamptz NULL,
+		pinned_until timestamptz NULL,
+		pinned_by varchar NULL,
+		pin_reason varchar NULL,
+		CONSTRAINT team_controls_pkey PRIMARY KEY (team_id),
+		CONSTRAINT team_controls_teams_fk FOREIGN KEY (team_id) REFERENCES nc.teams(id) ON DELETE CASCADE,
+		CONSTRAINT team_controls_numbers_fk FOREIGN KEY (pinned_number_id) REFERENCES nc.numbers(id) ON DELETE SET NULL
+		);`
 )
 
 func CreateTables(db *sqlx.DB) error {
@@ -137,6 +322,93 @@ func CreateTables(db *sqlx.DB) error {
 		return err
 	}
 
+	_, err = db.Exec(createRotationOutboxTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createNumberHealthTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createImportsTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createPoolAlertsTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createCallerIDsTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createLeaderLeaseTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createTeamWorkersTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createVendorFailoversTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createNumsMoveBatchesTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createNumsMoveNumbersTableSQL)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(createTeamControlsTableSQL)
+	if err != nil {
+		return err
+	}
+
+	// SQL-запросы для добавления колонок в уже существующие таблицы
+	alterSQLs := []string{
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS health_score float8 NULL;",
+		"ALTER TABLE nc.pools ADD COLUMN IF NOT EXISTS strategy varchar(20) DEFAULT 'sequential' NULL;",
+		"ALTER TABLE nc.scheduler ADD COLUMN IF NOT EXISTS cron varchar NULL;",
+		"ALTER TABLE nc.scheduler ADD COLUMN IF NOT EXISTS windows jsonb NULL;",
+		"ALTER TABLE nc.scheduler ADD COLUMN IF NOT EXISTS exceptions jsonb NULL;",
+		"ALTER TABLE nc.imports ADD COLUMN IF NOT EXISTS mode varchar(10) DEFAULT 'create' NOT NULL;",
+		"ALTER TABLE nc.teams ADD COLUMN IF NOT EXISTS backend varchar(20) DEFAULT 'webitel' NULL;",
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS state varchar(20) DEFAULT 'active' NOT NULL;",
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS state_at timestamptz NULL;",
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS state_until timestamptz NULL;",
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS state_reason varchar NULL;",
+		"ALTER TABLE nc.logs ADD COLUMN IF NOT EXISTS event varchar(20) DEFAULT 'activation' NOT NULL;",
+		"ALTER TABLE nc.logs ADD COLUMN IF NOT EXISTS state_from varchar(20) NULL;",
+		"ALTER TABLE nc.logs ADD COLUMN IF NOT EXISTS state_to varchar(20) NULL;",
+		"ALTER TABLE nc.logs ADD COLUMN IF NOT EXISTS reason varchar NULL;",
+		"ALTER TABLE nc.vendors ADD COLUMN IF NOT EXISTS quarantine_days int4 NULL;",
+		"ALTER TABLE nc.logs ADD COLUMN IF NOT EXISTS email varchar NULL;",
+		"ALTER TABLE nc.logs ALTER COLUMN number_id DROP NOT NULL;",
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS area_code varchar(15) NULL;",
+		"ALTER TABLE nc.numbers ADD COLUMN IF NOT EXISTS region varchar NULL;",
+		"ALTER TABLE nc.team_workers ADD COLUMN IF NOT EXISTS panics int4 DEFAULT 0 NOT NULL;",
+	}
+
+	// Выполнение запросов на добавление колонок
+	for _, alterSQL := range alterSQLs {
+		if _, err := db.Exec(alterSQL); err != nil {
+			return err
+		}
+	}
+
 	// SQL-запросы для создания индексов
 	indexSQLs := []string{
 		"CREATE INDEX IF NOT EXISTS numbers_pool_id_idx ON nc.numbers USING btree (pool_id);",
@@ -147,6 +419,15 @@ func CreateTables(db *sqlx.DB) error {
 		"CREATE INDEX IF NOT EXISTS subpools_last_nid_idx ON nc.subpools USING btree (last_nid);",
 		"CREATE INDEX IF NOT EXISTS subpools_pool_id_idx ON nc.subpools USING btree (pool_id);",
 		"CREATE INDEX IF NOT EXISTS subpools_status_idx ON nc.subpools USING btree (status);",
+		"CREATE INDEX IF NOT EXISTS rotation_outbox_status_idx ON nc.rotation_outbox USING btree (status);",
+		"CREATE INDEX IF NOT EXISTS number_health_number_id_idx ON nc.number_health USING btree (number_id, calc_at);",
+		"CREATE INDEX IF NOT EXISTS logs_pool_id_idx ON nc.logs USING btree (pool_id, start_at);",
+		"CREATE INDEX IF NOT EXISTS logs_team_id_idx ON nc.logs USING btree (team_id, start_at);",
+		"CREATE INDEX IF NOT EXISTS vendor_failovers_team_id_idx ON nc.vendor_failovers USING btree (team_id, created_at);",
+		"CREATE INDEX IF NOT EXISTS numbers_area_code_idx ON nc.numbers USING btree (team_id, area_code);",
+		"CREATE INDEX IF NOT EXISTS numbers_state_idx ON nc.numbers USING btree (state, state_until);",
+		"CREATE INDEX IF NOT EXISTS numsmove_numbers_number_id_idx ON nc.numsmove_numbers USING btree (number_id);",
+		"CREATE INDEX IF NOT EXISTS pool_alerts_pool_id_idx ON nc.pool_alerts USING btree (pool_id, kind, sent_at);",
 	}
 
 	// Выполнение запросов на создание индексов
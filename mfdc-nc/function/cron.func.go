@@ -0,0 +1,118 @@
+package function
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Разобранное cron-выражение из 5 полей: минута час день месяц день_недели
+type cronExpr struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// Разбор cron-выражения, поддерживаются *, списки, диапазоны и шаги (*/15, 1-5, 9-12,14-18)
+func parseCron(spec string) (*cronExpr, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	var expr cronExpr
+	var err error
+	if expr.minute, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("failed to parse cron minute: %w", err)
+	}
+	if expr.hour, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("failed to parse cron hour: %w", err)
+	}
+	if expr.dom, expr.domAny, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("failed to parse cron day of month: %w", err)
+	}
+	if expr.month, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("failed to parse cron month: %w", err)
+	}
+	if expr.dow, expr.dowAny, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("failed to parse cron day of week: %w", err)
+	}
+
+	// 7 - тоже воскресенье
+	if expr.dow&(1<<7) != 0 {
+		expr.dow |= 1
+	}
+
+	return &expr, nil
+}
+
+// Разбор одного поля cron в битовую маску, wildcard - поле задано как *
+func parseCronField(field string, min int, max int) (bits uint64, wildcard bool, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = min, max
+			wildcard = wildcard || step == 1
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			lo, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid range in %q", part)
+			}
+			hi, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid range in %q", part)
+			}
+		default:
+			lo, err = strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			// Запись вида 5/15 означает "с 5 до конца с шагом 15"
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, false, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, wildcard, nil
+}
+
+// Проверка, соответствует ли минута времени at выражению
+func (expr *cronExpr) match(at time.Time) bool {
+	if expr.minute&(1<<uint(at.Minute())) == 0 || expr.hour&(1<<uint(at.Hour())) == 0 || expr.month&(1<<uint(at.Month())) == 0 {
+		return false
+	}
+
+	domMatch := expr.dom&(1<<uint(at.Day())) != 0
+	dowMatch := expr.dow&(1<<uint(at.Weekday())) != 0
+
+	// Как в cron: если заданы и день месяца, и день недели, достаточно совпадения одного из них
+	switch {
+	case expr.domAny && expr.dowAny:
+		return true
+	case expr.domAny:
+		return dowMatch
+	case expr.dowAny:
+		return domMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,117 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Источники переключения вендора
+const (
+	failoverWebhook = "webhook" // Вызов VC в /vendors/failover
+	failoverSync    = "sync"    // Изменение, найденное в StartVCSync()
+)
+
+// Смена актуального вендора команды. Если вендор сменился, активируем пул нового вендора и сразу отправляем в его ресурсы свежий номер.
+// Возвращает nil, если вендор уже был актуальным или назначается команде впервые
+func switchActualVendor(db *sqlx.DB, teamID int, vendorID int, source string) (*model.VendorFailover, error) {
+	// Меняем вендора и получаем предыдущего одним запросом, чтобы вебхук и синк не выполнили переключение дважды
+	var previous sql.NullInt64
+	err := db.Get(&previous, `UPDATE nc.teams AS t SET actual_vendor_id = $1::int4
+		FROM (SELECT id, actual_vendor_id FROM nc.teams WHERE id = $2 FOR UPDATE) AS old
+		WHERE t.id = old.id AND old.actual_vendor_id IS DISTINCT FROM $1::int4
+		RETURNING old.actual_vendor_id`, vendorID, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Вендор уже актуальный
+		}
+		return nil, fmt.Errorf("failed to update actual VendorID for team ID '%d': %w", teamID, err)
+	}
+
+	// Пока актуальный вендор не был известен, ротировались пулы всех вендоров
+	if !previous.Valid {
+		return nil, nil
+	}
+
+	fromVendorID := int(previous.Int64)
+	failover := model.VendorFailover{
+		TeamID:       teamID,
+		FromVendorID: &fromVendorID,
+		ToVendorID:   vendorID,
+		Source:       source,
+		CreatedAt:    time.Now(),
+	}
+	OutLog.Printf("Vendor failover for team %v: vendor %v > %v (%s)", teamID, fromVendorID, vendorID, source)
+
+	err = failoverPool(db, &failover)
+	if err != nil {
+		message := err.Error()
+		failover.Error = &message
+		ErrLog.Printf("Vendor failover for team %v failed: %s", teamID, err)
+	}
+
+	saveErr := saveVendorFailover(db, &failover)
+	if saveErr != nil {
+		ErrLog.Printf("Failed to save vendor failover for team %v: %s", teamID, saveErr)
+	}
+
+	return &failover, err
+}
+
+// Активация пула нового вендора и отправка номера в его ресурсы
+func failoverPool(db *sqlx.DB, failover *model.VendorFailover) error {
+	// Берём активный пул вендора, иначе последний созданный
+	var pool model.Pool
+	err := db.Get(&pool, "SELECT id, active FROM nc.pools WHERE team_id=$1 AND vendor_id=$2 ORDER BY active DESC NULLS LAST, id DESC LIMIT 1", failover.TeamID, failover.ToVendorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no pool found for team %v and vendor %v", failover.TeamID, failover.ToVendorID)
+		}
+		return fmt.Errorf("failed to get pool for team %v and vendor %v: %w", failover.TeamID, failover.ToVendorID, err)
+	}
+	failover.PoolID = pool.ID
+
+	if pool.Active == nil || !*pool.Active {
+		_, err = db.Exec("UPDATE nc.pools SET active=$1 WHERE id=$2", true, *pool.ID)
+		if err != nil {
+			return fmt.Errorf("failed to activate pool %v: %w", *pool.ID, err)
+		}
+		OutLog.Printf("Pool %v activated by vendor failover", *pool.ID)
+	}
+
+	// Пулы прежнего вендора теперь не актуальны, поэтому ротация команды затронет только ресурсы нового вендора.
+	// Ротация идёт под блокировкой команды, чтобы не пересечься с шагом воркера
+	err = rotateTeam(db, failover.TeamID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to push number for vendor %v: %w", failover.ToVendorID, err)
+	}
+
+	number, err := getExpectedNumber(db, failover.TeamID, failover.ToVendorID)
+	if err != nil {
+		return err
+	}
+	failover.Number = number
+
+	return nil
+}
+
+// Сохранение переключения вендора в nc.vendor_failovers
+func saveVendorFailover(db *sqlx.DB, failover *model.VendorFailover) error {
+	rows, err := db.NamedQuery(`INSERT INTO nc.vendor_failovers (team_id, from_vendor_id, to_vendor_id, pool_id, "number", source, "error", created_at)
+		VALUES (:team_id, :from_vendor_id, :to_vendor_id, :pool_id, :number, :source, :error, :created_at) RETURNING id`, failover)
+	if err != nil {
+		return fmt.Errorf("failed to save vendor failover: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.Scan(&failover.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get vendor failover ID: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package function
+
+import (
+	"fmt"
+	"math"
+	"nc/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Типы оповещений по пулу
+const (
+	alertLowDays = "low_days" // До исчерпания неиспользованных номеров осталось меньше порога
+	alertReuse   = "reuse"    // Все номера использованы, пул пошёл на повторный цикл
+)
+
+// Окно расчёта темпа ротации
+func forecastWindow() time.Duration {
+	hours := config.Forecast.WindowHours
+	if hours <= 0 {
+		hours = 7 * 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// Расчёт прогноза исчерпания по всем пулам, ключ - ID пула
+func calcPoolForecasts(db *sqlx.DB) (map[int]*model.PoolForecast, error) {
+	window := forecastWindow()
+	from := time.Now().Add(-window)
+
+	// Неиспользованными считаем номера, которые ещё ни разу не выдавались (used сбрасывается только при повторном цикле сабпула)
+	query := `SELECT p.id AS pool_id,
+			(SELECT COUNT(n.id) FROM nc.numbers AS n WHERE n.pool_id = p.id AND n.enabled = TRUE AND n.state <> $2 AND COALESCE(n.used, false) = FALSE) AS unused,
+			(SELECT COUNT(l.id) FROM nc.logs AS l WHERE l.pool_id = p.id AND l.event = $3 AND l.start_at >= $1) AS activations,
+			COALESCE(p.finish, false) AS reusing,
+			p.finish_at AS reusing_at
+		FROM nc.pools AS p`
+
+	var forecasts []*model.PoolForecast
+	err := db.Select(&forecasts, query, from, stateRetired, logEventActivation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool forecast data: %w", err)
+	}
+
+	result := make(map[int]*model.PoolForecast, len(forecasts))
+	for _, forecast := range forecasts {
+		forecast.RotationsPerDay = math.Round(float64(forecast.Activations)/window.Hours()*24*100) / 100
+
+		if forecast.Activations > 0 {
+			daysLeft := math.Round(float64(forecast.UnusedNumbers)/(float64(forecast.Activations)/window.Hours()*24)*100) / 100
+			forecast.DaysLeft = &daysLeft
+		}
+		result[forecast.PoolID] = forecast
+	}
+
+	return result, nil
+}
+
+// Проверка прогноза по активным пулам и отправка оповещений
+func CheckPoolForecasts(db *sqlx.DB) error {
+	forecasts, err := calcPoolForecasts(db)
+	if err != nil {
+		return err
+	}
+
+	var pools []model.Pool
+	err = db.Select(&pools, "SELECT * FROM nc.pools WHERE active = $1 ORDER BY id", true)
+	if err != nil {
+		return fmt.Errorf("failed to get active pools: %w", err)
+	}
+
+	repeat := time.Duration(config.Forecast.RepeatHours) * time.Hour
+	if repeat <= 0 {
+		repeat = 24 * time.Hour
+	}
+
+	for _, pool := range pools {
+		forecast, ok := forecasts[*pool.ID]
+		if !ok {
+			continue
+		}
+
+		// Пул ушёл на повторный цикл: оповещаем один раз на каждый новый finish_at
+		if forecast.Reusing && forecast.ReusingAt != nil {
+			sent, err := poolAlertSentAfter(db, *pool.ID, alertReuse, *forecast.ReusingAt)
+			if err != nil {
+				return err
+			}
+			if !sent {
+				message := fmt.Sprintf("Pool %s (ID %d) used all numbers and started re-using them at %s", *pool.Name, *pool.ID, forecast.ReusingAt.Format(time.RFC3339))
+				sendPoolAlert(db, pool, forecast, alertReuse, message)
+			}
+			continue
+		}
+
+		// Мало дней до исчерпания неиспользованных номеров
+		if forecast.DaysLeft != nil && *forecast.DaysLeft < config.Forecast.ThresholdDays {
+			sent, err := poolAlertSentAfter(db, *pool.ID, alertLowDays, time.Now().Add(-repeat))
+			if err != nil {
+				return err
+			}
+			if !sent {
+				message := fmt.Sprintf("Pool %s (ID %d) will run out of unused numbers in %.2f days: %d left, %.2f rotations per day", *pool.Name, *pool.ID, *forecast.DaysLeft, forecast.UnusedNumbers, forecast.RotationsPerDay)
+				sendPoolAlert(db, pool, forecast, alertLowDays, message)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Проверка, отправлялось ли оповещение такого типа по пулу после момента after
+func poolAlertSentAfter(db *sqlx.DB, poolID int, kind string, after time.Time) (bool, error) {
+	var sent bool
+	err := db.Get(&sent, "SELECT EXISTS (SELECT 1 FROM nc.pool_alerts WHERE pool_id=$1 AND kind=$2 AND sent_at >= $3)", poolID, kind, after)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pool alerts: %w", err)
+	}
+	return sent, nil
+}
+
+// Отправка оповещения в вебхук и на почту, факт отправки сохраняем в nc.pool_alerts
+func sendPoolAlert(db *sqlx.DB, pool model.Pool, forecast *model.PoolForecast, kind string, message string) {
+	OutLog.Println(message)
+
+	payload := model.PoolAlertWebhook{
+		Event:    kind,
+		PoolID:   *pool.ID,
+		PoolName: *pool.Name,
+		TeamID:   pool.TeamID,
+		Message:  message,
+		Forecast: forecast,
+		At:       time.Now(),
+	}
+	err := sendNotification(config.Forecast.WebhookURL, config.Forecast.EMail, "MFDC NC: pool "+*pool.Name, message, payload)
+	if err != nil {
+		// Оповещение не сохраняем, чтобы повторить его на следующей проверке
+		ErrLog.Printf("Failed to send pool %v alert: %s", *pool.ID, err)
+		return
+	}
+
+	_, err = db.Exec("INSERT INTO nc.pool_alerts (pool_id, kind, days_left, message, sent_at) VALUES ($1, $2, $3, $4, $5)", *pool.ID, kind, forecast.DaysLeft, message, time.Now())
+	if err != nil {
+		ErrLog.Printf("Failed to save pool %v alert: %s", *pool.ID, err)
+	}
+}
@@ -0,0 +1,168 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"strings"
+
+	"github.com/jackc/pgtype"
+	"github.com/jmoiron/sqlx"
+)
+
+// Как подобран номер в /numbers/callerid
+const (
+	callerIDMatchArea    = "area"    // Номер с тем же кодом региона, что и у вызываемого
+	callerIDMatchDefault = "default" // Текущий номер команды в ресурсах
+)
+
+// Код региона номера (префикс с кодом страны) и название региона из geo.regions.
+// Сначала ищется самый длинный префикс из geo.regions, затем код берётся по upload.country_code и geo.area_code_length
+func numberGeo(value string) (*string, *string) {
+	var digits strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+
+	var areaCode, region string
+	for prefix, name := range config.Geo.Regions {
+		if len(prefix) > len(areaCode) && strings.HasPrefix(number, prefix) {
+			areaCode = prefix
+			region = name
+		}
+	}
+
+	if areaCode == "" {
+		country := config.Upload.CountryCode
+		length := config.Geo.AreaCodeLength
+		if length <= 0 {
+			length = 3
+		}
+		if country == "" || !strings.HasPrefix(number, country) || len(number) < len(country)+length {
+			return nil, nil
+		}
+		areaCode = number[:len(country)+length]
+	}
+
+	if region == "" {
+		return &areaCode, nil
+	}
+	return &areaCode, &region
+}
+
+// Заполнение кода региона у номеров, загруженных до появления geo
+func UpdateNumbersGeo(db *sqlx.DB) (int, error) {
+	var numbers []struct {
+		ID    int    `db:"id"`
+		Value string `db:"value"`
+	}
+	err := db.Select(&numbers, "SELECT id, value FROM nc.numbers WHERE area_code IS NULL")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get numbers without area code: %w", err)
+	}
+
+	ids := make([]int, 0, len(numbers))
+	areaCodes := make([]string, 0, len(numbers))
+	regions := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		areaCode, region := numberGeo(number.Value)
+		if areaCode == nil {
+			continue
+		}
+		ids = append(ids, number.ID)
+		areaCodes = append(areaCodes, *areaCode)
+		if region != nil {
+			regions = append(regions, *region)
+		} else {
+			regions = append(regions, "")
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var areaArr, regionArr pgtype.TextArray
+	if err := areaArr.Set(areaCodes); err != nil {
+		return 0, fmt.Errorf("failed to prepare area codes: %w", err)
+	}
+	if err := regionArr.Set(regions); err != nil {
+		return 0, fmt.Errorf("failed to prepare regions: %w", err)
+	}
+
+	// Одним запросом, чтобы не обновлять каждый номер отдельно
+	_, err = db.Exec(`UPDATE nc.numbers AS n SET area_code = g.area_code, region = NULLIF(g.region, '')
+		FROM unnest($1::int4[], $2::text[], $3::text[]) AS g(id, area_code, region)
+		WHERE n.id = g.id`, IntArr2PgIntArr(ids), areaArr, regionArr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update numbers area code: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// Подбор номера для звонка на callee: активный номер команды с тем же кодом региона, иначе текущий номер команды
+func lookupCallerID(db *sqlx.DB, teamID int, callee string) (*model.CallerIDLookup, error) {
+	lookup := model.CallerIDLookup{
+		Callee: callee,
+		TeamID: teamID,
+	}
+	lookup.CalleeAreaCode, lookup.CalleeRegion = numberGeo(callee)
+
+	// При известном актуальном вендоре номера других вендоров не отдаём, как и в ротации
+	var actualVendorID sql.NullInt64
+	err := db.Get(&actualVendorID, "SELECT actual_vendor_id FROM nc.teams WHERE id = $1", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team %v: %w", teamID, err)
+	}
+	var vendorID *int
+	if actualVendorID.Valid {
+		id := int(actualVendorID.Int64)
+		vendorID = &id
+	}
+
+	var number struct {
+		Value    string  `db:"value"`
+		AreaCode *string `db:"area_code"`
+		Region   *string `db:"region"`
+	}
+
+	if lookup.CalleeAreaCode != nil {
+		// Номер, который уже стоит в ресурсах, в приоритете, затем номера с лучшим здоровьем
+		err = db.Get(&number, `SELECT n.value, n.area_code, n.region FROM nc.numbers AS n
+			INNER JOIN nc.pools AS p ON n.pool_id = p.id
+			WHERE p.team_id = $1 AND p.active = TRUE AND ($2::int4 IS NULL OR p.vendor_id = $2)
+			  AND n.area_code = $3 AND n.state = $4 AND n.enabled = TRUE
+			ORDER BY n.active DESC NULLS LAST, n.health_score DESC NULLS LAST, n.activated_at DESC NULLS LAST
+			LIMIT 1`, teamID, vendorID, *lookup.CalleeAreaCode, stateActive)
+		if err == nil {
+			lookup.Number = number.Value
+			lookup.AreaCode = number.AreaCode
+			lookup.Region = number.Region
+			lookup.Match = callerIDMatchArea
+			return &lookup, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get local number for team %v: %w", teamID, err)
+		}
+	}
+
+	err = db.Get(&number, `SELECT n.value, n.area_code, n.region FROM nc.numbers AS n
+		INNER JOIN nc.pools AS p ON n.pool_id = p.id
+		WHERE p.team_id = $1 AND p.active = TRUE AND ($2::int4 IS NULL OR p.vendor_id = $2) AND n.active = TRUE
+		ORDER BY n.activated_at DESC NULLS LAST LIMIT 1`, teamID, vendorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active number for team %v: %w", teamID, err)
+	}
+
+	lookup.Number = number.Value
+	lookup.AreaCode = number.AreaCode
+	lookup.Region = number.Region
+	lookup.Match = callerIDMatchDefault
+	return &lookup, nil
+}
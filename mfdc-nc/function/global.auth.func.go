@@ -221,3 +221,11 @@ func CheckUserAuth() gin.HandlerFunc {
 		}
 	}
 }
+
+// Email пользователя из JWT, nil для запросов без авторизации
+func userEmail(c *gin.Context) *string {
+	if email := c.GetString("email"); email != "" {
+		return &email
+	}
+	return nil
+}
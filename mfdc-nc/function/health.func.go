@@ -0,0 +1,122 @@
+package function
+
+import (
+	"fmt"
+	"math"
+	"nc/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Режимы учёта оценки номера в ротации
+const (
+	healthModeSkip         = "skip"
+	healthModeDeprioritize = "deprioritize"
+)
+
+// Проверка оценки номера: low - оценка ниже порога
+func isLowHealth(score *float64) bool {
+	return config.Health.Enabled && score != nil && *score < config.Health.Threshold
+}
+
+// Условие и префикс сортировки для выборки следующего номера с учётом оценки (paramIndex - номер параметра порога)
+func healthQueryParts(paramIndex int) (condition string, orderPrefix string, args []interface{}) {
+	if !config.Health.Enabled {
+		return "", "", nil
+	}
+
+	if config.Health.Mode == healthModeSkip {
+		return fmt.Sprintf(" AND (n.health_score IS NULL OR n.health_score >= $%d)", paramIndex), "", []interface{}{config.Health.Threshold}
+	}
+
+	// По умолчанию плохие номера выдаются в последнюю очередь
+	return "", fmt.Sprintf("(n.health_score IS NOT NULL AND n.health_score < $%d), ", paramIndex), []interface{}{config.Health.Threshold}
+}
+
+// Расчёт итоговой оценки номера по статистике звонков
+func calcHealthScore(health *model.NumberHealth) {
+	if health.Calls > 0 {
+		health.AnswerRate = float64(health.Answered) / float64(health.Calls)
+		health.RejectRatio = float64(health.Rejected) / float64(health.Calls)
+	}
+	if health.Answered > 0 {
+		health.ShortCallRatio = float64(health.ShortCalls) / float64(health.Answered)
+	}
+
+	// Если звонков мало, оценку не ставим, чтобы не штрафовать номер на случайной выборке
+	if health.Calls < config.Health.MinCalls || health.Calls == 0 {
+		health.Score = nil
+		return
+	}
+
+	score := 100 * (0.5*health.AnswerRate + 0.2*(1-health.ShortCallRatio) + 0.3*(1-health.RejectRatio))
+	score = math.Round(score*100) / 100
+	health.Score = &score
+}
+
+// Пересчёт оценок номеров по исходящим звонкам из cdr.calls
+func CalcNumbersHealth(db *sqlx.DB) (int, error) {
+	windowHours := config.Health.WindowHours
+	if windowHours <= 0 {
+		windowHours = 24
+	}
+	from := time.Now().Add(-time.Duration(windowHours) * time.Hour)
+
+	// Номер Webitel подставляет как from_number для исходящих звонков
+	query := `SELECT n.id AS number_id,
+			COUNT(c.id) AS calls,
+			COUNT(c.answered_at) AS answered,
+			COUNT(c.id) FILTER (WHERE c.answered_at IS NOT NULL AND COALESCE(c.talk_sec, 0) < $1) AS short_calls,
+			COUNT(c.id) FILTER (WHERE c.sip_code IN (403, 603)) AS rejected
+		FROM nc.numbers AS n
+		INNER JOIN cdr.calls AS c ON c.from_number = n.value
+		WHERE c.direction = $2 AND c.created_at >= $3
+		GROUP BY n.id`
+
+	var healths []model.NumberHealth
+	err := db.Select(&healths, query, config.Health.ShortCallSec, "outbound", from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get calls statistic: %w", err)
+	}
+
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Номера без звонков в окне теряют оценку и снова получают шанс в ротации
+	_, err = tx.Exec("UPDATE nc.numbers SET health_score = NULL WHERE health_score IS NOT NULL")
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return 0, fmt.Errorf("failed to reset health scores: %w", err)
+	}
+
+	calcAt := time.Now()
+	for i := range healths {
+		health := &healths[i]
+		calcHealthScore(health)
+		health.CalcAt = calcAt
+
+		_, err = tx.NamedExec(`INSERT INTO nc.number_health (number_id, calls, answered, short_calls, rejected, answer_rate, short_call_ratio, reject_ratio, score, calc_at)
+			VALUES (:number_id, :calls, :answered, :short_calls, :rejected, :answer_rate, :short_call_ratio, :reject_ratio, :score, :calc_at)`, health)
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			return 0, fmt.Errorf("failed to save health history for number %v: %w", health.NumberID, err)
+		}
+
+		_, err = tx.Exec("UPDATE nc.numbers SET health_score = $1 WHERE id = $2", health.Score, health.NumberID)
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			return 0, fmt.Errorf("failed to update health score for number %v: %w", health.NumberID, err)
+		}
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit health scores: %w", err)
+	}
+
+	return len(healths), nil
+}
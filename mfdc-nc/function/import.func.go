@@ -0,0 +1,476 @@
+package function
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"nc/model"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jmoiron/sqlx"
+	"github.com/xuri/excelize/v2"
+)
+
+// Статусы загрузки номеров
+const (
+	importPending   = "pending"
+	importConfirmed = "confirmed"
+)
+
+// Режимы загрузки: новый пул или дозагрузка в существующий
+const (
+	importModeCreate = "create"
+	importModeAppend = "append"
+)
+
+// Статусы строк отчёта
+const (
+	importRowAccepted = "accepted"
+	importRowRejected = "rejected"
+)
+
+// Поддерживаемые форматы файла с номерами
+const (
+	importFormatCSV  = "csv"
+	importFormatXLSX = "xlsx"
+	importFormatJSON = "json"
+)
+
+// Определение формата по явному параметру или расширению файла
+func detectImportFormat(format string, filename string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+
+	switch strings.ToLower(format) {
+	case importFormatCSV, "txt", "":
+		return importFormatCSV, nil
+	case importFormatXLSX:
+		return importFormatXLSX, nil
+	case importFormatJSON:
+		return importFormatJSON, nil
+	}
+	return "", fmt.Errorf("unsupported file format %q, expected csv, xlsx or json", format)
+}
+
+// Чтение значений из файла, индекс в срезе соответствует строке файла
+func readImportValues(src io.Reader, format string) ([]string, error) {
+	switch format {
+	case importFormatXLSX:
+		return readXLSXValues(src)
+	case importFormatJSON:
+		return readJSONValues(src)
+	}
+	return readCSVValues(src)
+}
+
+// Номер берётся из первой колонки CSV
+func readCSVValues(src io.Reader) ([]string, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1 // Количество колонок в строках может отличаться
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	values := make([]string, len(records))
+	for i, record := range records {
+		if len(record) > 0 {
+			values[i] = record[0]
+		}
+	}
+	return values, nil
+}
+
+// Номер берётся из первой колонки первого листа
+func readXLSXValues(src io.Reader) ([]string, error) {
+	file, err := excelize.OpenReader(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(file.GetSheetName(0), excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX: %w", err)
+	}
+
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		value := row[0]
+		// Длинные номера в числовых ячейках Excel хранит в экспоненциальной записи
+		if strings.ContainsAny(value, "eE") {
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				value = strconv.FormatFloat(f, 'f', 0, 64)
+			}
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// JSON: массив строк или чисел, либо массив объектов с полем number
+func readJSONValues(src io.Reader) ([]string, error) {
+	var items []json.RawMessage
+	decoder := json.NewDecoder(src)
+	decoder.UseNumber()
+	if err := decoder.Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to read JSON, expected array: %w", err)
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		var text string
+		if err := json.Unmarshal(item, &text); err == nil {
+			values[i] = text
+			continue
+		}
+
+		var number json.Number
+		if err := json.Unmarshal(item, &number); err == nil {
+			values[i] = number.String()
+			continue
+		}
+
+		var object struct {
+			Number json.RawMessage `json:"number"`
+		}
+		if err := json.Unmarshal(item, &object); err == nil && len(object.Number) > 0 {
+			if err := json.Unmarshal(object.Number, &text); err == nil {
+				values[i] = text
+				continue
+			}
+			values[i] = string(object.Number)
+			continue
+		}
+
+		// Значение попадёт в отчёт как отклонённое
+		values[i] = string(item)
+	}
+	return values, nil
+}
+
+// Нормализация номера в E.164 (только цифры, без +)
+func normalizeNumber(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", fmt.Errorf("empty value")
+	}
+
+	international := false
+	if strings.HasPrefix(value, "+") {
+		international = true
+		value = value[1:]
+	} else if strings.HasPrefix(value, "00") {
+		international = true
+		value = value[2:]
+	}
+
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+			// Разделители просто отбрасываем
+		default:
+			return "", fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	number := digits.String()
+
+	// Национальный формат приводим к международному
+	upload := config.Upload
+	if !international && upload.CountryCode != "" && upload.NationalLength > 0 {
+		if upload.TrunkPrefix != "" && len(number) == len(upload.TrunkPrefix)+upload.NationalLength && strings.HasPrefix(number, upload.TrunkPrefix) {
+			number = upload.CountryCode + number[len(upload.TrunkPrefix):]
+		} else if len(number) == upload.NationalLength {
+			number = upload.CountryCode + number
+		}
+	}
+
+	if len(number) < 8 || len(number) > 15 {
+		return "", fmt.Errorf("number must contain 8 to 15 digits, got %d", len(number))
+	}
+	if number[0] == '0' {
+		return "", fmt.Errorf("country code cannot start with 0")
+	}
+
+	return number, nil
+}
+
+// Значение номера в том виде, в котором он хранится в nc.numbers
+func storedNumberValue(number string) string {
+	if config.Upload.PlusPrefix {
+		return "+" + number
+	}
+	return number
+}
+
+// Поиск номеров, которые уже есть в других пулах, ключ - номер без лишних символов
+func findExistingNumbers(db dbExecutor, numbers []string) (map[string]string, error) {
+	existing := make(map[string]string)
+	if len(numbers) == 0 {
+		return existing, nil
+	}
+
+	var arr pgtype.TextArray
+	if err := arr.Set(numbers); err != nil {
+		return nil, fmt.Errorf("failed to prepare numbers: %w", err)
+	}
+
+	var rows []struct {
+		Digits string  `db:"digits"`
+		PoolID *int    `db:"pool_id"`
+		Pool   *string `db:"pool"`
+		Team   *string `db:"team"`
+	}
+	query := `SELECT regexp_replace(n.value, '\D', '', 'g') AS digits, n.pool_id, p.name AS pool, t.name AS team
+		FROM nc.numbers AS n
+		LEFT JOIN nc.pools AS p ON n.pool_id = p.id
+		LEFT JOIN nc.teams AS t ON n.team_id = t.id
+		WHERE regexp_replace(n.value, '\D', '', 'g') = ANY($1)`
+	err := db.Select(&rows, query, arr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing numbers: %w", err)
+	}
+
+	for _, row := range rows {
+		reason := "already exists"
+		if row.PoolID != nil && row.Pool != nil {
+			reason = fmt.Sprintf("already in pool %s (ID %d)", *row.Pool, *row.PoolID)
+		}
+		if row.Team != nil {
+			reason += fmt.Sprintf(", team %s", *row.Team)
+		}
+		existing[row.Digits] = reason
+	}
+	return existing, nil
+}
+
+// Построение отчёта: нормализация, дубли в файле и номера из других пулов
+func buildImportReport(db dbExecutor, values []string) (model.ImportRows, error) {
+	report := make(model.ImportRows, 0, len(values))
+	firstRow := make(map[string]int)
+	var numbers []string
+
+	for i, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue // Пустые строки не учитываем
+		}
+		if i == 0 && !strings.ContainsAny(value, "0123456789") {
+			continue // Первая строка без цифр - заголовок колонки
+		}
+
+		row := model.ImportRow{Row: i + 1, Value: value, Status: importRowAccepted}
+
+		number, err := normalizeNumber(value)
+		if err != nil {
+			row.Status = importRowRejected
+			row.Reason = err.Error()
+			report = append(report, row)
+			continue
+		}
+		row.Number = storedNumberValue(number)
+
+		if first, ok := firstRow[number]; ok {
+			row.Status = importRowRejected
+			row.Reason = fmt.Sprintf("duplicate of row %d", first)
+			report = append(report, row)
+			continue
+		}
+		firstRow[number] = row.Row
+		numbers = append(numbers, number)
+		report = append(report, row)
+	}
+
+	existing, err := findExistingNumbers(db, numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report {
+		row := &report[i]
+		if row.Status != importRowAccepted {
+			continue
+		}
+		if reason, ok := existing[strings.TrimPrefix(row.Number, "+")]; ok {
+			row.Status = importRowRejected
+			row.Reason = reason
+		}
+	}
+
+	return report, nil
+}
+
+// Сохранение проверенной загрузки до подтверждения
+func createNumbersImport(db *sqlx.DB, entry *model.NumbersImport) error {
+	ttl := config.Upload.TTLMinute
+	if ttl <= 0 {
+		ttl = 60
+	}
+	entry.Status = importPending
+	entry.CreatedAt = time.Now()
+	entry.ExpiresAt = entry.CreatedAt.Add(time.Duration(ttl) * time.Minute)
+
+	entry.Total = len(entry.Rows)
+	entry.AcceptedCount, entry.RejectedCount = 0, 0
+	for _, row := range entry.Rows {
+		if row.Status == importRowAccepted {
+			entry.AcceptedCount++
+		} else {
+			entry.RejectedCount++
+		}
+	}
+
+	if entry.Mode == "" {
+		entry.Mode = importModeCreate
+	}
+
+	query := `INSERT INTO nc.imports (pool_name, team_id, vendor_id, subpool_block, format, mode, status, total, accepted_count, rejected_count, "rows", email, pool_id, created_at, expires_at)
+		VALUES (:pool_name, :team_id, :vendor_id, :subpool_block, :format, :mode, :status, :total, :accepted_count, :rejected_count, :rows, :email, :pool_id, :created_at, :expires_at) RETURNING id`
+	rows, err := db.NamedQuery(query, entry)
+	if err != nil {
+		return fmt.Errorf("failed to save import: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&entry.ID); err != nil {
+			return fmt.Errorf("failed to get import ID: %w", err)
+		}
+	}
+	return nil
+}
+
+// Подтверждение загрузки: пул, сабпулы и номера создаются одной транзакцией
+func confirmNumbersImport(db *sqlx.DB, importID int) (*model.NumbersImport, error) {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Блокируем запись, чтобы одну загрузку нельзя было подтвердить дважды
+	var entry model.NumbersImport
+	err = tx.Get(&entry, "SELECT * FROM nc.imports WHERE id=$1 FOR UPDATE", importID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("import %v not found", importID)
+		}
+		return nil, fmt.Errorf("failed to get import %v: %w", importID, err)
+	}
+
+	if entry.Status != importPending {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("import %v is already %s", importID, entry.Status)
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("import %v expired at %s, upload the file again", importID, entry.ExpiresAt.Format(time.RFC3339))
+	}
+
+	var values, numbers []string
+	for _, row := range entry.Rows {
+		if row.Status == importRowAccepted {
+			values = append(values, row.Number)
+			numbers = append(numbers, strings.TrimPrefix(row.Number, "+"))
+		}
+	}
+	if len(values) == 0 {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("import %v has no accepted numbers", importID)
+	}
+
+	// Пока загрузка ждала подтверждения, номера могли появиться в других пулах
+	existing, err := findExistingNumbers(tx, numbers)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, err
+	}
+	if len(existing) > 0 {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		conflicts := make([]string, 0, len(existing))
+		for number, reason := range existing {
+			conflicts = append(conflicts, number+": "+reason)
+		}
+		return nil, fmt.Errorf("numbers were added to other pools after validation, upload the file again: %s", strings.Join(conflicts, "; "))
+	}
+
+	var poolID int
+	poolName := entry.PoolName
+	if entry.Mode == importModeAppend && entry.PoolID != nil {
+		// Дозагрузка номеров в существующий пул
+		poolID = *entry.PoolID
+		err = appendNumbersToPool(tx, poolID, values)
+	} else {
+		poolName, poolID, err = createPoolWithNumbers(tx, entry, values)
+	}
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, err
+	}
+
+	confirmedAt := time.Now()
+	_, err = tx.Exec("UPDATE nc.imports SET status=$1, pool_id=$2, pool_name=$3, confirmed_at=$4 WHERE id=$5", importConfirmed, poolID, poolName, confirmedAt, importID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to update import %v: %w", importID, err)
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	entry.Status = importConfirmed
+	entry.PoolID = &poolID
+	entry.PoolName = poolName
+	entry.ConfirmedAt = &confirmedAt
+	return &entry, nil
+}
+
+// Создание нового пула с сабпулами по subpool_block
+func createPoolWithNumbers(db dbExecutor, entry model.NumbersImport, values []string) (string, int, error) {
+	var subpools [][]string
+	for i := 0; i < len(values); i += entry.SubPoolBlock {
+		end := i + entry.SubPoolBlock
+		if end > len(values) {
+			end = len(values)
+		}
+		subpools = append(subpools, values[i:end])
+	}
+
+	poolName, poolID, err := createPool(db, entry.PoolName, entry.SubPoolBlock, entry.VendorID, entry.TeamID, len(values), len(subpools))
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Перебираем сабпулы
+	for i, subpool := range subpools {
+		subPoolID, err := createSubPool(db, poolID, i)
+		if err != nil {
+			return "", 0, err
+		}
+
+		for _, value := range subpool {
+			_, err := createNumber(db, value, false, poolID, subPoolID, entry.VendorID, entry.TeamID)
+			if err != nil {
+				return "", 0, err
+			}
+		}
+	}
+
+	return poolName, poolID, nil
+}
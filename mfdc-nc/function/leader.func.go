@@ -0,0 +1,224 @@
+package function
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	leaderLeaseName       = "background" // Имя аренды фоновых задач в nc.leader_lease
+	defaultLeaderLockKey  = 0x6d666463   // Ключ pg_advisory_lock, если lock_key не задан
+	defaultLeaderLeaseSec = 15
+)
+
+// Фоновая задача, которую выполняет только лидер. Задача должна завершаться по ctx.Done()
+type LeaderJob func(ctx context.Context, db *sqlx.DB)
+
+var (
+	leaderMu sync.RWMutex
+	isLeader bool // Текущий экземпляр держит advisory lock и выполняет фоновые задачи
+)
+
+func IsLeader() bool {
+	leaderMu.RLock()
+	defer leaderMu.RUnlock()
+	return isLeader
+}
+
+func setLeader(value bool) {
+	leaderMu.Lock()
+	defer leaderMu.Unlock()
+	isLeader = value
+}
+
+// Имя экземпляра nc-api, по умолчанию hostname:pid
+func leaderInstance() string {
+	if config.Leader.Instance != "" {
+		return config.Leader.Instance
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+func leaderLockKey() int64 {
+	if config.Leader.LockKey != 0 {
+		return config.Leader.LockKey
+	}
+	return defaultLeaderLockKey
+}
+
+func leaderLease() time.Duration {
+	seconds := config.Leader.LeaseSecond
+	if seconds <= 0 {
+		seconds = defaultLeaderLeaseSec
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Запуск фоновых задач с выборами лидера: задачи выполняет только экземпляр, захвативший advisory lock,
+// остальные экземпляры обслуживают только HTTP API и периодически пытаются стать лидером
+func RunLeaderJobs(ctx context.Context, db *sqlx.DB, jobs []LeaderJob) {
+	if !config.Leader.Enabled {
+		// Без выборов задачи выполняет каждый экземпляр, как при единственном nc-api
+		setLeader(true)
+		runLeaderJobs(ctx, db, jobs)
+		return
+	}
+
+	for {
+		err := leadOnce(ctx, db, jobs)
+		if err != nil {
+			ErrLog.Printf("Leader election error: %s", err)
+		}
+
+		select {
+		case <-time.After(leaderLease()):
+		case <-ctx.Done():
+			OutLog.Println("Stopping leader election...")
+			return
+		}
+	}
+}
+
+// Выполнение задач до отмены контекста, возврат после завершения всех задач
+func runLeaderJobs(ctx context.Context, db *sqlx.DB, jobs []LeaderJob) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job LeaderJob) {
+			defer wg.Done()
+			job(ctx, db)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// Попытка стать лидером и выполнение задач, пока блокировка удерживается
+func leadOnce(ctx context.Context, db *sqlx.DB, jobs []LeaderJob) error {
+	// Advisory lock принадлежит сессии, поэтому держим для него отдельное соединение из пула
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get leader connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	err = conn.GetContext(ctx, &acquired, "SELECT pg_try_advisory_lock($1)", leaderLockKey())
+	if err != nil {
+		return fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		return nil // Лидер уже есть
+	}
+
+	instance := leaderInstance()
+	defer releaseLeader(conn, instance)
+
+	now := time.Now()
+	_, err = conn.ExecContext(ctx, `INSERT INTO nc.leader_lease ("name", instance, acquired_at, renewed_at, expires_at) VALUES ($1, $2, $3, $3, $4)
+		ON CONFLICT ("name") DO UPDATE SET instance = EXCLUDED.instance, acquired_at = EXCLUDED.acquired_at, renewed_at = EXCLUDED.renewed_at, expires_at = EXCLUDED.expires_at`,
+		leaderLeaseName, instance, now, now.Add(leaderLease()))
+	if err != nil {
+		return fmt.Errorf("failed to save leader lease: %w", err)
+	}
+
+	OutLog.Printf("Instance %s is leader, starting background jobs", instance)
+	setLeader(true)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		runLeaderJobs(jobCtx, db, jobs)
+		close(done)
+	}()
+
+	// Продление аренды через то же соединение: если сессия оборвалась, блокировка уже потеряна
+	ticker := time.NewTicker(leaderLease() / 3)
+	defer ticker.Stop()
+
+	for err == nil {
+		select {
+		case <-ticker.C:
+			err = renewLeaderLease(ctx, conn, instance)
+		case <-ctx.Done():
+			cancel()
+			<-done
+			return nil
+		}
+	}
+
+	// Блокировка потеряна: останавливаем задачи до того, как их подхватит новый лидер
+	ErrLog.Printf("Instance %s lost leadership, stopping background jobs", instance)
+	cancel()
+	<-done
+	return err
+}
+
+// Продление аренды лидера
+func renewLeaderLease(ctx context.Context, conn *sqlx.Conn, instance string) error {
+	now := time.Now()
+	result, err := conn.ExecContext(ctx, `UPDATE nc.leader_lease SET renewed_at = $1, expires_at = $2 WHERE "name" = $3 AND instance = $4`,
+		now, now.Add(leaderLease()), leaderLeaseName, instance)
+	if err != nil {
+		return fmt.Errorf("failed to renew leader lease: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check leader lease: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("leader lease was taken by another instance")
+	}
+	return nil
+}
+
+// Снятие лидерства: закрываем аренду и освобождаем блокировку, иначе соединение вернётся в пул с ней
+func releaseLeader(conn *sqlx.Conn, instance string) {
+	setLeader(false)
+
+	// Контекст фоновых задач к этому моменту может быть отменён
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := conn.ExecContext(ctx, `UPDATE nc.leader_lease SET expires_at = $1 WHERE "name" = $2 AND instance = $3`, time.Now(), leaderLeaseName, instance)
+	if err != nil {
+		ErrLog.Printf("Failed to close leader lease: %s", err)
+	}
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", leaderLockKey())
+	if err != nil {
+		ErrLog.Printf("Failed to release advisory lock: %s", err)
+	}
+	OutLog.Printf("Instance %s released leadership", instance)
+}
+
+// Статус лидерства текущего экземпляра и последняя аренда лидера
+func getLeaderStatus(db *sqlx.DB) (model.LeaderStatus, error) {
+	status := model.LeaderStatus{
+		Enabled:  config.Leader.Enabled,
+		Instance: leaderInstance(),
+		IsLeader: IsLeader(),
+	}
+
+	var lease model.LeaderLease
+	err := db.Get(&lease, `SELECT * FROM nc.leader_lease WHERE "name" = $1`, leaderLeaseName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return status, nil
+		}
+		return status, fmt.Errorf("failed to get leader lease: %w", err)
+	}
+	status.Leader = &lease
+	status.Expired = lease.ExpiresAt.Before(time.Now())
+
+	return status, nil
+}
@@ -0,0 +1,228 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Состояния номера в nc.numbers.state
+const (
+	stateActive      = "active"      // Номер участвует в ротации
+	stateCooldown    = "cooldown"    // Охлаждение после интенсивного использования до state_until
+	stateQuarantined = "quarantined" // Карантин после жалоб на спам до state_until
+	stateRetired     = "retired"     // Выведен из оборота навсегда
+)
+
+// События в nc.logs.event
+const (
+	logEventActivation = "activation" // Номер был активен в ресурсах с start_at по end_at
+	logEventState      = "state"      // Смена состояния номера
+)
+
+// Допустимые переходы между состояниями
+var stateTransitions = map[string][]string{
+	stateActive:      {stateCooldown, stateQuarantined, stateRetired},
+	stateCooldown:    {stateActive, stateQuarantined, stateRetired},
+	stateQuarantined: {stateActive, stateRetired},
+	stateRetired:     {},
+}
+
+// Данные номера для смены состояния
+type numberStateRow struct {
+	ID        int    `db:"id"`
+	State     string `db:"state"`
+	SubPoolID *int   `db:"subpool_id"`
+	PoolID    *int   `db:"pool_id"`
+	VendorID  *int   `db:"vendor_id"`
+	TeamID    *int   `db:"team_id"`
+	Value     string `db:"value"`
+}
+
+func checkStateTransition(from string, to string) error {
+	next, ok := stateTransitions[from]
+	if !ok {
+		return fmt.Errorf("unknown number state %q", from)
+	}
+	if _, ok := stateTransitions[to]; !ok {
+		return fmt.Errorf("unknown number state %q, available: active, cooldown, quarantined, retired", to)
+	}
+	for _, state := range next {
+		if state == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("number state can not be changed from %s to %s", from, to)
+}
+
+// Смена состояния номера с записью в nc.logs, until - окончание охлаждения или карантина
+func changeNumberState(db dbExecutor, numberID int, to string, until *time.Time, reason string) error {
+	var number numberStateRow
+	err := db.Get(&number, "SELECT id, state, subpool_id, pool_id, vendor_id, team_id, value FROM nc.numbers WHERE id=$1 FOR UPDATE", numberID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("number %v not found", numberID)
+		}
+		return fmt.Errorf("failed to get number %v: %w", numberID, err)
+	}
+
+	err = checkStateTransition(number.State, to)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = db.Exec("UPDATE nc.numbers SET state=$1, state_at=$2, state_until=$3, state_reason=$4 WHERE id=$5", to, now, until, reason, numberID)
+	if err != nil {
+		return fmt.Errorf("failed to update number %v state: %w", numberID, err)
+	}
+
+	// Смена состояния - событие без длительности, поэтому end_at = start_at и endLog() её не закроет
+	_, err = db.Exec(`INSERT INTO nc.logs (number_id, subpool_id, pool_id, vendor_id, team_id, start_at, end_at, comment, event, state_from, state_to, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7, $8, $9, $10, $11)`,
+		numberID, number.SubPoolID, number.PoolID, number.VendorID, number.TeamID, now,
+		fmt.Sprintf("State %s > %s", number.State, to), logEventState, number.State, to, reason)
+	if err != nil {
+		return fmt.Errorf("failed to save number %v state log: %w", numberID, err)
+	}
+
+	OutLog.Printf("Number %s state changed %s > %s: %s", number.Value, number.State, to, reason)
+	return nil
+}
+
+// Длительность карантина номера: quarantine_days вендора или lifecycle.quarantine_days
+func getQuarantineDays(db dbExecutor, numberID int) (int, error) {
+	var days sql.NullInt64
+	err := db.Get(&days, "SELECT v.quarantine_days FROM nc.numbers AS n LEFT JOIN nc.vendors AS v ON n.vendor_id = v.id WHERE n.id = $1", numberID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("number %v not found", numberID)
+		}
+		return 0, fmt.Errorf("failed to get quarantine days for number %v: %w", numberID, err)
+	}
+	if days.Valid && days.Int64 > 0 {
+		return int(days.Int64), nil
+	}
+	if config.Lifecycle.QuarantineDays > 0 {
+		return config.Lifecycle.QuarantineDays, nil
+	}
+	return 30, nil
+}
+
+// Карантин номера, на retire_after_quarantines по счёту карантине номер выводится из оборота
+func quarantineNumber(db dbExecutor, numberID int, days int, reason string) (string, *time.Time, error) {
+	if config.Lifecycle.RetireAfterQuarantines > 0 {
+		var count int
+		err := db.Get(&count, "SELECT COUNT(id) FROM nc.logs WHERE number_id=$1 AND event=$2 AND state_to=$3", numberID, logEventState, stateQuarantined)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to count quarantines of number %v: %w", numberID, err)
+		}
+		if count+1 >= config.Lifecycle.RetireAfterQuarantines {
+			reason = fmt.Sprintf("%s (quarantine %d of %d)", reason, count+1, config.Lifecycle.RetireAfterQuarantines)
+			return stateRetired, nil, changeNumberState(db, numberID, stateRetired, nil, reason)
+		}
+	}
+
+	if days <= 0 {
+		var err error
+		days, err = getQuarantineDays(db, numberID)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	until := time.Now().AddDate(0, 0, days)
+	return stateQuarantined, &until, changeNumberState(db, numberID, stateQuarantined, &until, reason)
+}
+
+// Охлаждение номера на days дней, по умолчанию lifecycle.cooldown_days
+func cooldownNumber(db dbExecutor, numberID int, days int, reason string) (*time.Time, error) {
+	if days <= 0 {
+		days = config.Lifecycle.CooldownDays
+	}
+	if days <= 0 {
+		days = 1
+	}
+
+	until := time.Now().AddDate(0, 0, days)
+	return &until, changeNumberState(db, numberID, stateCooldown, &until, reason)
+}
+
+// Проверка интенсивности использования номера после снятия с ресурсов, при превышении номер уходит на охлаждение
+func checkNumberCooldown(db dbExecutor, numberID int) error {
+	if config.Lifecycle.CooldownActivations <= 0 {
+		return nil
+	}
+
+	hours := config.Lifecycle.CooldownWindowHours
+	if hours <= 0 {
+		hours = 24
+	}
+
+	var state string
+	err := db.Get(&state, "SELECT state FROM nc.numbers WHERE id=$1", numberID)
+	if err != nil {
+		return fmt.Errorf("failed to get number %v state: %w", numberID, err)
+	}
+	if state != stateActive {
+		return nil
+	}
+
+	var activations int
+	err = db.Get(&activations, "SELECT COUNT(id) FROM nc.logs WHERE number_id=$1 AND event=$2 AND start_at >= $3", numberID, logEventActivation, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to count activations of number %v: %w", numberID, err)
+	}
+	if activations < config.Lifecycle.CooldownActivations {
+		return nil
+	}
+
+	_, err = cooldownNumber(db, numberID, 0, fmt.Sprintf("heavy use: %d activations in %d hours", activations, hours))
+	return err
+}
+
+// Возврат в ротацию номеров, у которых закончилось охлаждение или карантин
+func ReleaseNumberStates(db *sqlx.DB) (int, error) {
+	var numbers []struct {
+		ID    int    `db:"id"`
+		State string `db:"state"`
+	}
+	err := db.Select(&numbers, "SELECT id, state FROM nc.numbers WHERE state IN ($1, $2) AND state_until <= $3 ORDER BY id", stateCooldown, stateQuarantined, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get numbers with expired state: %w", err)
+	}
+
+	released := 0
+	for _, number := range numbers {
+		tx, err := db.Beginx()
+		if err != nil {
+			return released, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		// Состояние могли продлить, пока шла выборка
+		var until *time.Time
+		err = tx.Get(&until, "SELECT state_until FROM nc.numbers WHERE id=$1 FOR UPDATE", number.ID)
+		if err != nil || until == nil || until.After(time.Now()) {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			continue
+		}
+
+		err = changeNumberState(tx, number.ID, stateActive, nil, number.State+" finished")
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			ErrLog.Printf("Failed to release number %v: %s", number.ID, err)
+			continue
+		}
+
+		// Подтверждаем транзакцию
+		err = tx.Commit()
+		if err != nil {
+			return released, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		released++
+	}
+
+	return released, nil
+}
@@ -0,0 +1,89 @@
+package function
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+)
+
+// SendEmail отправляет электронное письмо через SMTP сервер с TLS авторизацией.
+func SendEmail(to []string, subject string, body string, contentType string) error {
+	// Параметры подключения к SMTP
+	smtpServer := config.MAIL.ServerAddr
+	port := config.MAIL.ServerPort
+	from := config.MAIL.AuthUser
+	username := config.MAIL.AuthUser
+	password := config.MAIL.AuthPassword
+
+	// Настройка TLS конфигурации
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: false, // Пропускать ли проверку подлинности сертификата сервера
+		ServerName:         smtpServer,
+	}
+
+	// Получаем адрес SMTP сервера
+	addr := fmt.Sprintf("%s:%s", smtpServer, port)
+
+	// Устанавливаем соединение с SMTP сервером
+	conn, err := tls.Dial("tcp", addr, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the SMTP server: %v", err)
+	}
+	defer conn.Close()
+
+	// Создаем новый SMTP клиент
+	client, err := smtp.NewClient(conn, smtpServer)
+	if err != nil {
+		return fmt.Errorf("failed to create SMTP client: %v", err)
+	}
+
+	// Аутентификация
+	auth := smtp.PlainAuth("", username, password, smtpServer)
+	if err := client.Auth(auth); err != nil {
+		return fmt.Errorf("failed to authenticate: %v", err)
+	}
+
+	// Устанавливаем отправителя
+	if err := client.Mail(username); err != nil {
+		return fmt.Errorf("failed to set sender: %v", err)
+	}
+
+	// Устанавливаем получателей
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %v", recipient, err)
+		}
+	}
+
+	// Получаем почтовый писатель
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to get writer: %v", err)
+	}
+	defer writer.Close()
+
+	// Формируем тело сообщения
+	var message []byte
+
+	if contentType == "html" {
+		message = []byte(fmt.Sprintf(
+			"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset='UTF-8'\r\n\r\n%s",
+			from,
+			to[0],
+			subject,
+			body,
+		))
+	} else {
+		message = []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", from, to[0], subject, body))
+	}
+
+	// Записываем сообщение в почтовый писатель
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %v", err)
+	}
+
+	// Завершаем соединение
+	client.Quit()
+
+	return nil
+}
@@ -2,6 +2,7 @@ package function
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -89,6 +90,24 @@ func UpdateConfig(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "message": "Reload successffuly"})
 }
 
+// Leader status godoc
+// @Summary      Background jobs leader
+// @Description  Current leader of background jobs (rotation, subpool activation, VC sync) and its lease
+// @Tags         Leader
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}   model.SwaggerLeaderStatus
+// @Router       /leader/status [get]
+// @Security ApiKeyAuth
+func LeaderStatus(db *sqlx.DB, c *gin.Context) {
+	status, err := getLeaderStatus(db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get leader status", "error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"status": "success", "data": status})
+}
+
 func MonitorConfigReload(ctx context.Context) {
 	for {
 		select {
@@ -172,6 +191,15 @@ func DBMiddleware() gin.HandlerFunc {
 	}
 }
 
+// Общий интерфейс для *sqlx.DB и *sqlx.Tx, чтобы одни и те же функции работали как с БД, так и внутри транзакции
+type dbExecutor interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // Функция конвертации из []int в pgtype.Int4Array
 func IntArr2PgIntArr(int_arr []int) (pg_arr pgtype.Int4Array) {
 	if len(int_arr) > 0 {
@@ -0,0 +1,33 @@
+package function
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Отправка оповещения в вебхук (payload в JSON) и на почту (message), пустые получатели пропускаются
+func sendNotification(webhookURL string, emails []string, subject string, message string, payload interface{}) error {
+	var sendErrors []string
+
+	if webhookURL != "" {
+		body, status, err := WebhookFetch(http.MethodPost, webhookURL, payload)
+		if err != nil {
+			sendErrors = append(sendErrors, fmt.Sprintf("failed to send webhook: %s", err))
+		} else if status < 200 || status >= 300 {
+			sendErrors = append(sendErrors, fmt.Sprintf("webhook returned status %d: %s", status, string(body)))
+		}
+	}
+
+	if len(emails) > 0 {
+		err := SendEmail(emails, subject, message, "text")
+		if err != nil {
+			sendErrors = append(sendErrors, err.Error())
+		}
+	}
+
+	if len(sendErrors) > 0 {
+		return fmt.Errorf("%s", strings.Join(sendErrors, "; "))
+	}
+	return nil
+}
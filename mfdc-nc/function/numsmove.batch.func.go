@@ -0,0 +1,223 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Параметры пула, которые меняет перераспределение
+type numsMovePoolInfo struct {
+	SubPoolBlock *int `db:"subpool_block"`
+	SubPoolCount *int `db:"subpool_count"`
+}
+
+// Запись перераспределения и исходного положения номеров до их перемещения
+func createNumsMoveBatch(db dbExecutor, email *string, srcPoolID *int, dstPoolID *int, dstTeamID *int, vendorID *int, movedNumbers []map[string]int) (int, error) {
+	var srcPool, dstPool numsMovePoolInfo
+	err := db.Get(&srcPool, "SELECT subpool_block, subpool_count FROM nc.pools WHERE id = $1", *srcPoolID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get source pool %v: %w", *srcPoolID, err)
+	}
+	err = db.Get(&dstPool, "SELECT subpool_block, subpool_count FROM nc.pools WHERE id = $1", *dstPoolID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get destination pool %v: %w", *dstPoolID, err)
+	}
+
+	var batchID int
+	err = db.QueryRow(`INSERT INTO nc.numsmove_batches (src_pool_id, dst_pool_id, dst_team_id, vendor_id, numbers_count, src_subpool_block, src_subpool_count, dst_subpool_block, dst_subpool_count, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		*srcPoolID, *dstPoolID, dstTeamID, vendorID, len(movedNumbers), srcPool.SubPoolBlock, srcPool.SubPoolCount, dstPool.SubPoolBlock, dstPool.SubPoolCount, email, time.Now()).Scan(&batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create redistribution batch: %w", err)
+	}
+
+	numberIDs := make([]int, 0, len(movedNumbers))
+	for _, number := range movedNumbers {
+		numberIDs = append(numberIDs, number["number_id"])
+	}
+
+	_, err = db.Exec(`INSERT INTO nc.numsmove_numbers (batch_id, number_id, src_pool_id, src_subpool_id, src_team_id, src_vendor_id, src_label, src_activated_at, src_moved_at)
+		SELECT $1, id, pool_id, subpool_id, team_id, vendor_id, label, activated_at, moved_at FROM nc.numbers WHERE id = ANY($2)`,
+		batchID, IntArr2PgIntArr(numberIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to save moved numbers: %w", err)
+	}
+
+	return batchID, nil
+}
+
+// Сохранение положения номеров после перемещения и созданных сабпулов
+func finishNumsMoveBatch(db dbExecutor, batchID int, createdSubPools []int) error {
+	_, err := db.Exec(`UPDATE nc.numsmove_numbers AS m SET dst_subpool_id = n.subpool_id
+		FROM nc.numbers AS n WHERE n.id = m.number_id AND m.batch_id = $1`, batchID)
+	if err != nil {
+		return fmt.Errorf("failed to save destination subpools: %w", err)
+	}
+
+	_, err = db.Exec("UPDATE nc.numsmove_batches SET created_subpools = $1 WHERE id = $2", model.SubPoolIDs(createdSubPools), batchID)
+	if err != nil {
+		return fmt.Errorf("failed to save created subpools: %w", err)
+	}
+
+	return nil
+}
+
+// Список перераспределений, poolID - исходный пул или пул назначения
+func getNumsMoveBatches(db *sqlx.DB, poolID *int, limit int) ([]model.NumsMoveBatch, error) {
+	query := "SELECT * FROM nc.numsmove_batches"
+	var args []interface{}
+	if poolID != nil {
+		query += " WHERE src_pool_id = $1 OR dst_pool_id = $1"
+		args = append(args, *poolID)
+	}
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT %d", limit)
+
+	batches := []model.NumsMoveBatch{}
+	err := db.Select(&batches, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redistributions: %w", err)
+	}
+	return batches, nil
+}
+
+// Перераспределение с перемещёнными номерами
+func getNumsMoveBatch(db *sqlx.DB, batchID int) (*model.NumsMoveBatchInfo, error) {
+	var batch model.NumsMoveBatchInfo
+	err := db.Get(&batch.NumsMoveBatch, "SELECT * FROM nc.numsmove_batches WHERE id = $1", batchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("redistribution %v not found", batchID)
+		}
+		return nil, fmt.Errorf("failed to get redistribution %v: %w", batchID, err)
+	}
+
+	batch.Numbers = []model.NumsMoveNumber{}
+	err = db.Select(&batch.Numbers, `SELECT m.*, n.value FROM nc.numsmove_numbers AS m
+		LEFT JOIN nc.numbers AS n ON n.id = m.number_id WHERE m.batch_id = $1 ORDER BY m.number_id`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moved numbers of redistribution %v: %w", batchID, err)
+	}
+
+	return &batch, nil
+}
+
+// Откат перераспределения одной транзакцией: номера возвращаются в исходные сабпулы, параметры пулов восстанавливаются.
+// Откат невозможен, если после перераспределения номер ротировался или был перемещён ещё раз
+func rollbackNumsMoveBatch(db *sqlx.DB, batchID int, email *string) (*model.NumsMoveBatch, error) {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Блокируем запись, чтобы одно перераспределение нельзя было откатить дважды
+	var batch model.NumsMoveBatch
+	err = tx.Get(&batch, "SELECT * FROM nc.numsmove_batches WHERE id = $1 FOR UPDATE", batchID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("redistribution %v not found", batchID)
+		}
+		return nil, fmt.Errorf("failed to get redistribution %v: %w", batchID, err)
+	}
+	if batch.RolledBackAt != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("redistribution %v is already rolled back at %s", batchID, batch.RolledBackAt.Format(time.RFC3339))
+	}
+
+	// Параметры пулов восстанавливаются целиком, поэтому более поздние перераспределения этих пулов откатываются первыми
+	var later []int
+	err = tx.Select(&later, `SELECT id FROM nc.numsmove_batches WHERE id > $1 AND rolled_back_at IS NULL
+		AND (src_pool_id IN ($2, $3) OR dst_pool_id IN ($2, $3)) ORDER BY id`, batchID, batch.SrcPoolID, batch.DstPoolID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to check later redistributions: %w", err)
+	}
+	if len(later) > 0 {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("pools of redistribution %v were redistributed later, roll back redistributions %v first", batchID, later)
+	}
+
+	// Блокируем номера до конца отката, чтобы ротация не активировала их в процессе
+	var numberIDs []int
+	err = tx.Select(&numberIDs, `SELECT id FROM nc.numbers WHERE id IN (SELECT number_id FROM nc.numsmove_numbers WHERE batch_id = $1) ORDER BY id FOR UPDATE`, batchID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to lock moved numbers: %w", err)
+	}
+	if len(numberIDs) != batch.NumbersCount {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("%d of %d moved numbers were deleted, redistribution %v can not be rolled back", batch.NumbersCount-len(numberIDs), batch.NumbersCount, batchID)
+	}
+
+	// Номер ротировался, если он был активирован или перемещён после перераспределения
+	var rotated []string
+	err = tx.Select(&rotated, `SELECT n.value FROM nc.numsmove_numbers AS m
+		JOIN nc.numbers AS n ON n.id = m.number_id
+		WHERE m.batch_id = $1 AND (n.pool_id IS DISTINCT FROM $2 OR n.subpool_id IS DISTINCT FROM m.dst_subpool_id
+			OR n.active IS TRUE OR n.activated_at IS NOT NULL
+			OR EXISTS (SELECT 1 FROM nc.logs AS l WHERE l.number_id = n.id AND l.event = $3 AND l.start_at >= $4))
+		ORDER BY n.value`, batchID, batch.DstPoolID, logEventActivation, batch.CreatedAt)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to check rotated numbers: %w", err)
+	}
+	if len(rotated) > 0 {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("numbers were rotated after redistribution %v: %s", batchID, strings.Join(rotated, ", "))
+	}
+
+	// Активный номер исходного пула за это время мог смениться, поэтому номера возвращаются неактивными
+	_, err = tx.Exec(`UPDATE nc.numbers AS n SET pool_id = m.src_pool_id, subpool_id = m.src_subpool_id, team_id = m.src_team_id, vendor_id = m.src_vendor_id,
+		label = m.src_label, active = false, activated_at = m.src_activated_at, moved_at = m.src_moved_at
+		FROM nc.numsmove_numbers AS m WHERE m.number_id = n.id AND m.batch_id = $1`, batchID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to restore moved numbers: %w", err)
+	}
+
+	_, err = tx.Exec("UPDATE nc.pools SET subpool_block = $1, subpool_count = $2, num_count = num_count + $3 WHERE id = $4",
+		batch.SrcSubPoolBlock, batch.SrcSubPoolCount, batch.NumbersCount, batch.SrcPoolID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to restore source pool info: %w", err)
+	}
+	_, err = tx.Exec("UPDATE nc.pools SET subpool_block = $1, subpool_count = $2, num_count = num_count - $3 WHERE id = $4",
+		batch.DstSubPoolBlock, batch.DstSubPoolCount, batch.NumbersCount, batch.DstPoolID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to restore destination pool info: %w", err)
+	}
+
+	// Сабпулы, созданные перераспределением, после возврата номеров пустые
+	if len(batch.CreatedSubPools) > 0 {
+		_, err = tx.Exec(`DELETE FROM nc.subpools AS s WHERE s.id = ANY($1) AND s.pool_id = $2
+			AND NOT EXISTS (SELECT 1 FROM nc.numbers AS n WHERE n.subpool_id = s.id)`, IntArr2PgIntArr(batch.CreatedSubPools), batch.DstPoolID)
+		if err != nil {
+			tx.Rollback() // Откатываем транзакцию при ошибке
+			return nil, fmt.Errorf("failed to delete created subpools: %w", err)
+		}
+	}
+
+	rolledBackAt := time.Now()
+	_, err = tx.Exec("UPDATE nc.numsmove_batches SET rolled_back_at = $1, rolled_back_by = $2 WHERE id = $3", rolledBackAt, email, batchID)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return nil, fmt.Errorf("failed to update redistribution %v: %w", batchID, err)
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit rollback: %w", err)
+	}
+
+	batch.RolledBackAt = &rolledBackAt
+	batch.RolledBackBy = email
+	OutLog.Printf("Redistribution %v rolled back: %d numbers returned from pool %v to pool %v", batchID, batch.NumbersCount, batch.DstPoolID, batch.SrcPoolID)
+	return &batch, nil
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // Получение количества номеров в сабпуле для пула
-func getNumbersCountBySubpool(db *sqlx.DB, poolID *int) (int, error) {
+func getNumbersCountBySubpool(db dbExecutor, poolID *int) (int, error) {
 	if poolID == nil {
 		return 0, fmt.Errorf("pool ID is empty")
 	}
@@ -28,7 +28,7 @@ func getNumbersCountBySubpool(db *sqlx.DB, poolID *int) (int, error) {
 }
 
 // Получения среза карт с номерами из исходного пула
-func getSrcNumbers(db *sqlx.DB, countNumbers *int, srcPoolID *int) (movedNumbers []map[string]int, err error) {
+func getSrcNumbers(db dbExecutor, countNumbers *int, srcPoolID *int) (movedNumbers []map[string]int, err error) {
 	// Создаем срез карт
 	movedNumbers = make([]map[string]int, 0)
 
@@ -69,7 +69,7 @@ func getSrcNumbers(db *sqlx.DB, countNumbers *int, srcPoolID *int) (movedNumbers
 }
 
 // Получение среза ID сабпулов для пула назначения
-func getSubpoolsIDSByPool(db *sqlx.DB, dstPoolID *int) ([]int, error) {
+func getSubpoolsIDSByPool(db dbExecutor, dstPoolID *int) ([]int, error) {
 	// Проверяем, что указатель на идентификатор пула не равен nil
 	if dstPoolID == nil {
 		return nil, fmt.Errorf("destination pool ID is empty")
@@ -85,7 +85,7 @@ func getSubpoolsIDSByPool(db *sqlx.DB, dstPoolID *int) ([]int, error) {
 	return dstSubpools, nil
 }
 
-func updateNumber(db *sqlx.DB, numberID int, newSubpoolID int, newPoolID *int, dstTeamID *int) error {
+func updateNumber(db dbExecutor, numberID int, newSubpoolID int, newPoolID *int, dstTeamID *int) error {
 	// Проверка входных параметров
 	if newPoolID == nil {
 		return fmt.Errorf("pool ID is empty")
@@ -102,7 +102,7 @@ func updateNumber(db *sqlx.DB, numberID int, newSubpoolID int, newPoolID *int, d
 }
 
 // Обновление инфо в пулах
-func updatePoolInfo(db *sqlx.DB, srcPoolInfo model.SrcPoolInfo, dstPoolInfo model.DstPoolInfo) error {
+func updatePoolInfo(db dbExecutor, srcPoolInfo model.SrcPoolInfo, dstPoolInfo model.DstPoolInfo) error {
 	// Обновление исходного пула
 	_, err := db.Exec(
 		"UPDATE nc.pools SET subpool_block = $1, num_count = num_count - $2, subpool_count = $3 WHERE id = $4",
@@ -131,7 +131,7 @@ func updatePoolInfo(db *sqlx.DB, srcPoolInfo model.SrcPoolInfo, dstPoolInfo mode
 }
 
 // Получение максимального значения индекса сабпула в пуле
-func getMaxIndexValueInSubpool(db *sqlx.DB, poolID *int) (int, error) {
+func getMaxIndexValueInSubpool(db dbExecutor, poolID *int) (int, error) {
 	if poolID == nil {
 		return 0, fmt.Errorf("pool ID is empty")
 	}
@@ -145,7 +145,7 @@ func getMaxIndexValueInSubpool(db *sqlx.DB, poolID *int) (int, error) {
 	return maxIndexValue, nil
 }
 
-func getTeamIDInPool(db *sqlx.DB, poolID *int) (int, error) {
+func getTeamIDInPool(db dbExecutor, poolID *int) (int, error) {
 	if poolID == nil {
 		return 0, fmt.Errorf("pool ID is empty")
 	}
@@ -159,7 +159,7 @@ func getTeamIDInPool(db *sqlx.DB, poolID *int) (int, error) {
 	return teamID, nil
 }
 
-func moveNumbers(db *sqlx.DB, dstTeamID *int, poolID *int, subPoolsIDS []int, moveCount int, movedNumbers []map[string]int) (int, error) {
+func moveNumbers(db dbExecutor, dstTeamID *int, poolID *int, subPoolsIDS []int, moveCount int, movedNumbers []map[string]int) (int, error) {
 	var numFinish bool
 	addedCount := 0
 
@@ -202,28 +202,50 @@ func moveNumbers(db *sqlx.DB, dstTeamID *int, poolID *int, subPoolsIDS []int, mo
 	return addedCount, nil
 }
 
-func NumbersMoveByPool(db *sqlx.DB, countNumbers *int, srcPoolID *int, dstPoolID *int, dstTeamID *int, srcVendorID *int, dstVendorID *int, SrcSubPoolsCount *int, DstSubPoolsCount *int) error {
+// Перераспределение номеров одной транзакцией с записью в историю, возвращает ID перераспределения для отката
+func NumbersMoveByPool(db *sqlx.DB, email *string, countNumbers *int, srcPoolID *int, dstPoolID *int, dstTeamID *int, srcVendorID *int, dstVendorID *int, SrcSubPoolsCount *int, DstSubPoolsCount *int) (int, error) {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	batchID, err := numbersMoveByPool(tx, email, countNumbers, srcPoolID, dstPoolID, dstTeamID, srcVendorID, dstVendorID, SrcSubPoolsCount, DstSubPoolsCount)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return 0, err
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit redistribution: %w", err)
+	}
+
+	return batchID, nil
+}
+
+func numbersMoveByPool(db dbExecutor, email *string, countNumbers *int, srcPoolID *int, dstPoolID *int, dstTeamID *int, srcVendorID *int, dstVendorID *int, SrcSubPoolsCount *int, DstSubPoolsCount *int) (int, error) {
 	if srcPoolID == nil || dstPoolID == nil {
-		return fmt.Errorf("Source and destination pool ID cannot be nil")
+		return 0, fmt.Errorf("Source and destination pool ID cannot be nil")
 	}
 
 	if *srcPoolID == *dstPoolID {
-		return fmt.Errorf("Source and destination pool ID must be different")
+		return 0, fmt.Errorf("Source and destination pool ID must be different")
 	}
 	// Проверяем чтобы vendor_id у исходного и пула назначения совподал
 	if srcVendorID == nil || dstVendorID == nil {
-		return fmt.Errorf("Source and destination vendor cannot be nil")
+		return 0, fmt.Errorf("Source and destination vendor cannot be nil")
 	}
 	if *srcVendorID != *dstVendorID {
-		return fmt.Errorf("Source and destination vendor mismatch")
+		return 0, fmt.Errorf("Source and destination vendor mismatch")
 	}
 
 	teamIDInDstPool, err := getTeamIDInPool(db, dstPoolID)
 	if err != nil {
-		return fmt.Errorf("Failed to get team ID in pool %w", err)
+		return 0, fmt.Errorf("Failed to get team ID in pool %w", err)
 	}
 	if dstTeamID != nil && *dstTeamID != teamIDInDstPool {
-		return fmt.Errorf("Destination team ID must be associated destination pool")
+		return 0, fmt.Errorf("Destination team ID must be associated destination pool")
 	}
 
 	/*
@@ -231,11 +253,11 @@ func NumbersMoveByPool(db *sqlx.DB, countNumbers *int, srcPoolID *int, dstPoolID
 		которые должны быть в новых сабпулах для пула назначения - newNumbersCountInSubpool
 	*/
 	if SrcSubPoolsCount == nil || DstSubPoolsCount == nil {
-		return fmt.Errorf("Source and destination subpools count cannot be nil")
+		return 0, fmt.Errorf("Source and destination subpools count cannot be nil")
 	}
 
 	if countNumbers == nil {
-		return fmt.Errorf("Count numbers to move cannot be nil")
+		return 0, fmt.Errorf("Count numbers to move cannot be nil")
 	}
 
 	var newSrcNumbersCountInSubpool, newNumbersCountInSubpool int
@@ -263,21 +285,28 @@ func NumbersMoveByPool(db *sqlx.DB, countNumbers *int, srcPoolID *int, dstPoolID
 	// Циклом проходимся по сабпулам пула источника и берём id n-номеров (countNumbers) из каждого сабпула в срез movedNumbers
 	movedNumbers, err := getSrcNumbers(db, countNumbers, srcPoolID)
 	if err != nil {
-		return fmt.Errorf("Failed to get numbers source pool: %s", err)
+		return 0, fmt.Errorf("Failed to get numbers source pool: %s", err)
+	}
+
+	// Запоминаем положение номеров и параметры пулов до перемещения для отката
+	batchID, err := createNumsMoveBatch(db, email, srcPoolID, dstPoolID, dstTeamID, srcVendorID, movedNumbers)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to save redistribution: %w", err)
 	}
 
 	// Проходимся по сабпулам назначения и берём их subpool_id в срез dstSubpools
 	dstSubpools, err := getSubpoolsIDSByPool(db, dstPoolID)
 	if err != nil {
-		return fmt.Errorf("Failed to get destination subpools IDs: %s", err)
+		return 0, fmt.Errorf("Failed to get destination subpools IDs: %s", err)
 	}
 
 	// Операции перемещения
 	addedCount, err := moveNumbers(db, dstTeamID, dstPoolID, dstSubpools, *countNumbers, movedNumbers)
 	if err != nil {
-		return fmt.Errorf("Failed to move numbers: %s", err)
+		return 0, fmt.Errorf("Failed to move numbers: %s", err)
 	}
 
+	var newSubpoolIDS []int
 	// Если кол-во перемещённых номеров меньше чем кол-во номеров в срезе карт movedNumbers, то создаём доп. сабпулы в пуле назначения
 	if addedCount < len(movedNumbers) {
 		// Вычисляем сколько осталось не перемещённых номеров
@@ -295,16 +324,15 @@ func NumbersMoveByPool(db *sqlx.DB, countNumbers *int, srcPoolID *int, dstPoolID
 
 		maxIndexValue, err := getMaxIndexValueInSubpool(db, dstPoolID)
 		if err != nil {
-			return fmt.Errorf("Failed to get max index subpool in pool: %s", err)
+			return 0, fmt.Errorf("Failed to get max index subpool in pool: %s", err)
 		}
 		newSubpoolIndex := maxIndexValue + 1
 
-		var newSubpoolIDS []int
 		for i := 0; i < newSubPoolsCount; i++ {
 			// Создаём новый сабпул в пуле назначения
 			newSubPoolID, err := createSubPool(db, *dstPoolID, newSubpoolIndex)
 			if err != nil {
-				return fmt.Errorf("Failed to create new subpool: %s", err)
+				return 0, fmt.Errorf("Failed to create new subpool: %s", err)
 			}
 			// Увеличиваем индекс нового сабпула
 			newSubpoolIndex++
@@ -315,7 +343,7 @@ func NumbersMoveByPool(db *sqlx.DB, countNumbers *int, srcPoolID *int, dstPoolID
 		// Перемещаем оставшиеся номера в созданные сабпулы
 		_, err = moveNumbers(db, dstTeamID, dstPoolID, newSubpoolIDS, newNumbersCountInSubpool, movedNumbers)
 		if err != nil {
-			return fmt.Errorf("Failed to move numbers: %s", err)
+			return 0, fmt.Errorf("Failed to move numbers: %s", err)
 		}
 
 	}
@@ -336,8 +364,13 @@ func NumbersMoveByPool(db *sqlx.DB, countNumbers *int, srcPoolID *int, dstPoolID
 	}
 	err = updatePoolInfo(db, srcPoolInfo, dstPoolInfo)
 	if err != nil {
-		return fmt.Errorf("Failed to update pool: %w", err)
+		return 0, fmt.Errorf("Failed to update pool: %w", err)
 	}
 
-	return nil
+	err = finishNumsMoveBatch(db, batchID, newSubpoolIDS)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to save redistribution: %w", err)
+	}
+
+	return batchID, nil
 }
@@ -0,0 +1,317 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Статусы записей outbox ротации
+const (
+	outboxPending     = "pending"     // Запись создана, номер ещё не отправлен в Webitel
+	outboxPushed      = "pushed"      // Номер отправлен в Webitel, изменения в БД ещё не зафиксированы
+	outboxDone        = "done"        // Шаг ротации полностью выполнен
+	outboxCompensated = "compensated" // Отправка не удалась, в ресурсы возвращён предыдущий номер
+	outboxFailed      = "failed"      // Отправка и компенсация не удались, требуется повтор компенсации
+	outboxSkipped     = "skipped"     // Запись устарела, пул уже ротирован более поздним шагом
+)
+
+// Создание записи outbox перед отправкой номера в Webitel
+func createRotationOutbox(db *sqlx.DB, entry model.RotationOutbox) (int, error) {
+	var outboxID int
+	query := `INSERT INTO nc.rotation_outbox (team_id, pool_id, subpool_id, vendor_id, number_id, "number", prev_number, resource_ids, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	err := db.QueryRow(query, entry.TeamID, entry.PoolID, entry.SubPoolID, entry.VendorID, entry.NumberID, entry.Number, entry.PreviousNumber, entry.ResourceIDS, outboxPending, time.Now()).Scan(&outboxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create rotation outbox: %w", err)
+	}
+	return outboxID, nil
+}
+
+// Обновление статуса записи outbox
+func setRotationOutboxStatus(db dbExecutor, outboxID int, status string, statusErr error) error {
+	var errText *string
+	if statusErr != nil {
+		text := statusErr.Error()
+		errText = &text
+	}
+
+	_, err := db.Exec(`UPDATE nc.rotation_outbox SET status=$1, "error"=$2, updated_at=$3 WHERE id=$4`, status, errText, time.Now(), outboxID)
+	if err != nil {
+		return fmt.Errorf("failed to update rotation outbox %v: %w", outboxID, err)
+	}
+	return nil
+}
+
+// Получение номера, который сейчас активен в пуле (нужен для компенсации)
+func getActiveNumberValue(db *sqlx.DB, poolID int) (*string, error) {
+	var value string
+	err := db.Get(&value, "SELECT value FROM nc.numbers WHERE pool_id=$1 AND active=$2 ORDER BY activated_at DESC NULLS LAST LIMIT 1", poolID, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active number for pool %v: %w", poolID, err)
+	}
+	return &value, nil
+}
+
+// Получение номера по ID в том же виде, в котором его возвращает getNextAvailableNumber()
+func getRotationNumber(db dbExecutor, numberID int) (*model.Number, error) {
+	var number model.Number
+	err := db.Get(&number, "SELECT id, subpool_id, pool_id, value, used, label, activated_at, spin FROM nc.numbers WHERE id=$1", numberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number %v: %w", numberID, err)
+	}
+
+	if number.ActivatedAt == nil {
+		now := time.Now()
+		number.ActivatedAt = &now
+	}
+	if number.Label == nil {
+		label := true
+		number.Label = &label
+	}
+	if number.Used == nil {
+		used := true
+		number.Used = &used
+	}
+
+	return &number, nil
+}
+
+// Отправка номера во все ресурсы бэкенда команды, возвращает ресурсы, которые были затронуты
+func pushNumberToResources(backend DialerBackend, resourceIDS []int, number string) (touched []int, err error) {
+	for _, resourceID := range resourceIDS {
+		// Ресурс считается затронутым даже при ошибке, так как очистка могла успеть выполниться
+		touched = append(touched, resourceID)
+
+		err = backend.SetCallerID(resourceID, number)
+		if err != nil {
+			return touched, fmt.Errorf("failed to update %s resource %v: %w", backend.Name(), resourceID, err)
+		}
+		OutLog.Printf("Sent number %s to %s resource ID %v", number, backend.Name(), resourceID)
+	}
+	return touched, nil
+}
+
+// Компенсация неудачной отправки: возвращаем в затронутые ресурсы предыдущий номер
+func compensateRotation(db dbExecutor, backend DialerBackend, outboxID int, prevNumber *string, touched []int, cause error) error {
+	if prevNumber == nil || len(touched) == 0 {
+		// Восстанавливать нечего, фиксируем причину
+		return setRotationOutboxStatus(db, outboxID, outboxCompensated, cause)
+	}
+
+	_, err := pushNumberToResources(backend, touched, *prevNumber)
+	if err != nil {
+		statusErr := setRotationOutboxStatus(db, outboxID, outboxFailed, fmt.Errorf("%s; compensation: %s", cause, err))
+		if statusErr != nil {
+			return statusErr
+		}
+		return fmt.Errorf("failed to compensate rotation %v: %w", outboxID, err)
+	}
+
+	OutLog.Printf("Rotation %v compensated, number %s restored in %s", outboxID, *prevNumber, backend.Name())
+	return setRotationOutboxStatus(db, outboxID, outboxCompensated, cause)
+}
+
+// Фиксация шага ротации в БД одной транзакцией
+func applyRotation(db *sqlx.DB, outboxID int, subPool model.SubPool, number *model.Number, logEntry model.Logs) error {
+	// Начинаем транзакцию
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	err = activateNumber(tx, number)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to activate number %v: %s", number.ID, err)
+	}
+
+	// Пишем лог окончания
+	err = endLog(tx, subPool)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to write end log %v: %s", number.ID, err)
+	}
+
+	// Пишем текущий ID номера в сабпул addLastNumberID() обязательно должна быть после endLog()
+	err = addLastNumberID(tx, number)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to add last number ID %v: %s", number.ID, err)
+	}
+
+	// Пишем лог старта
+	err = startLog(tx, logEntry)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return fmt.Errorf("failed to log activation for number %v: %s", number.ID, err)
+	}
+
+	err = setRotationOutboxStatus(tx, outboxID, outboxDone, nil)
+	if err != nil {
+		tx.Rollback() // Откатываем транзакцию при ошибке
+		return err
+	}
+
+	// Подтверждаем транзакцию
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rotation: %w", err)
+	}
+
+	return nil
+}
+
+// Шаг ротации сабпула: outbox > бэкенд АТС > транзакция в БД
+func rotateNumber(db *sqlx.DB, pool model.Pool, subPool model.SubPool, number *model.Number, resourceIDS []int) error {
+	backend, err := getTeamBackend(db, *pool.TeamID)
+	if err != nil {
+		return err
+	}
+
+	prevNumber, err := getActiveNumberValue(db, *pool.ID)
+	if err != nil {
+		return err
+	}
+
+	outboxID, err := createRotationOutbox(db, model.RotationOutbox{
+		TeamID:         *pool.TeamID,
+		PoolID:         *pool.ID,
+		SubPoolID:      subPool.ID,
+		VendorID:       *pool.VendorID,
+		NumberID:       number.ID,
+		Number:         number.Value,
+		PreviousNumber: prevNumber,
+		ResourceIDS:    IntArr2PgIntArr(resourceIDS),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Отправляем номер в бэкенд АТС
+	touched, pushErr := pushNumberToResources(backend, resourceIDS, number.Value)
+	if pushErr != nil {
+		compensateErr := compensateRotation(db, backend, outboxID, prevNumber, touched, pushErr)
+		if compensateErr != nil {
+			ErrLog.Printf("Rotation %v: %s", outboxID, compensateErr)
+		}
+		return pushErr
+	}
+
+	// Если дальше что-то пойдёт не так, запись в статусе pushed будет доведена reconciler'ом
+	err = setRotationOutboxStatus(db, outboxID, outboxPushed, nil)
+	if err != nil {
+		return err
+	}
+
+	logEntry := model.Logs{
+		NumberID:  number.ID,
+		SubPoolID: subPool.ID,
+		PoolID:    *pool.ID,
+		VendorID:  *pool.VendorID,
+		TeamID:    *pool.TeamID,
+		StartAt:   time.Now(),
+		EndAt:     nil,
+		Comment:   "Used",
+	}
+
+	return applyRotation(db, outboxID, subPool, number, logEntry)
+}
+
+// Поиск и восстановление незавершённых шагов ротации, запускается при старте сервиса
+func ReconcileRotations(db *sqlx.DB) error {
+	var entries []model.RotationOutbox
+	err := db.Select(&entries, "SELECT * FROM nc.rotation_outbox WHERE status IN ($1, $2, $3) ORDER BY id", outboxPending, outboxPushed, outboxFailed)
+	if err != nil {
+		return fmt.Errorf("failed to get unfinished rotations: %w", err)
+	}
+
+	for _, entry := range entries {
+		err := reconcileRotation(db, entry)
+		if err != nil {
+			ErrLog.Printf("Failed to reconcile rotation %v: %s", entry.ID, err)
+			continue
+		}
+		OutLog.Printf("Rotation %v for team %v reconciled", entry.ID, entry.TeamID)
+	}
+
+	return nil
+}
+
+func reconcileRotation(db *sqlx.DB, entry model.RotationOutbox) error {
+	resourceIDS := PgIntArr2IntArr(entry.ResourceIDS)
+
+	backend, err := getTeamBackend(db, entry.TeamID)
+	if err != nil {
+		return err
+	}
+
+	// Повторяем неудавшуюся компенсацию
+	if entry.Status == outboxFailed {
+		var cause error
+		if entry.Error != nil {
+			cause = fmt.Errorf("%s", *entry.Error)
+		}
+		return compensateRotation(db, backend, entry.ID, entry.PreviousNumber, resourceIDS, cause)
+	}
+
+	// Если по пулу или ресурсам команды уже прошёл более поздний шаг ротации, незавершённая запись неактуальна.
+	// Сабпул для сравнения не подходит: активный сабпул меняется каждый день
+	var superseded bool
+	err = db.Get(&superseded, `SELECT EXISTS (SELECT 1 FROM nc.rotation_outbox
+		WHERE team_id=$1 AND (pool_id=$2 OR resource_ids && $3::int4[]) AND id>$4 AND status=$5)`,
+		entry.TeamID, entry.PoolID, entry.ResourceIDS, entry.ID, outboxDone)
+	if err != nil {
+		return fmt.Errorf("failed to check newer rotations: %w", err)
+	}
+	if superseded {
+		return setRotationOutboxStatus(db, entry.ID, outboxSkipped, fmt.Errorf("superseded by newer rotation"))
+	}
+
+	// Номер мог не дойти до АТС, отправляем повторно
+	if entry.Status == outboxPending {
+		touched, pushErr := pushNumberToResources(backend, resourceIDS, entry.Number)
+		if pushErr != nil {
+			compensateErr := compensateRotation(db, backend, entry.ID, entry.PreviousNumber, touched, pushErr)
+			if compensateErr != nil {
+				return compensateErr
+			}
+			return pushErr
+		}
+
+		err = setRotationOutboxStatus(db, entry.ID, outboxPushed, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var subPool model.SubPool
+	err = db.Get(&subPool, "SELECT * FROM nc.subpools WHERE id=$1", entry.SubPoolID)
+	if err != nil {
+		return fmt.Errorf("failed to get subpool %v: %w", entry.SubPoolID, err)
+	}
+
+	number, err := getRotationNumber(db, entry.NumberID)
+	if err != nil {
+		return err
+	}
+
+	logEntry := model.Logs{
+		NumberID:  entry.NumberID,
+		SubPoolID: entry.SubPoolID,
+		PoolID:    entry.PoolID,
+		VendorID:  entry.VendorID,
+		TeamID:    entry.TeamID,
+		StartAt:   time.Now(),
+		EndAt:     nil,
+		Comment:   "Used",
+	}
+
+	return applyRotation(db, entry.ID, subPool, number, logEntry)
+}
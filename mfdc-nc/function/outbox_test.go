@@ -0,0 +1,162 @@
+package function
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Исполнитель запросов, который запоминает статусы записей outbox
+type outboxStatusRecorder struct {
+	dbExecutor
+	statuses map[int]string
+}
+
+func (r *outboxStatusRecorder) Exec(query string, args ...interface{}) (sql.Result, error) {
+	r.statuses[args[3].(int)] = args[0].(string)
+	return nil, nil
+}
+
+func newOutboxStatusRecorder() *outboxStatusRecorder {
+	return &outboxStatusRecorder{statuses: make(map[int]string)}
+}
+
+func assertCallerIDs(t *testing.T, backend *MemoryBackend, expected map[int]string) {
+	t.Helper()
+	for resourceID, number := range expected {
+		numbers, err := backend.GetCallerIDs(resourceID)
+		if err != nil {
+			t.Fatalf("GetCallerIDs(%d): %v", resourceID, err)
+		}
+		want := []string{}
+		if number != "" {
+			want = []string{number}
+		}
+		if !reflect.DeepEqual(numbers, want) {
+			t.Fatalf("resource %d: got %v, want %v", resourceID, numbers, want)
+		}
+	}
+}
+
+func TestPushNumberToResources(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	touched, err := pushNumberToResources(backend, []int{1, 2, 3}, "74950000001")
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if !reflect.DeepEqual(touched, []int{1, 2, 3}) {
+		t.Fatalf("touched: got %v", touched)
+	}
+	assertCallerIDs(t, backend, map[int]string{1: "74950000001", 2: "74950000001", 3: "74950000001"})
+
+	history := []string{"1:74950000001", "2:74950000001", "3:74950000001"}
+	if !reflect.DeepEqual(backend.History(), history) {
+		t.Fatalf("history: got %v, want %v", backend.History(), history)
+	}
+}
+
+func TestRotationCompensatedOnPushFailure(t *testing.T) {
+	backend := NewMemoryBackend()
+	prevNumber := "74950000001"
+	if _, err := pushNumberToResources(backend, []int{1, 2, 3}, prevNumber); err != nil {
+		t.Fatalf("initial push: %v", err)
+	}
+
+	// Второй ресурс недоступен: отправка останавливается на нём
+	backend.FailResource(2, errors.New("resource unavailable"))
+	touched, pushErr := pushNumberToResources(backend, []int{1, 2, 3}, "74950000002")
+	if pushErr == nil {
+		t.Fatal("push: expected error")
+	}
+	if !reflect.DeepEqual(touched, []int{1, 2}) {
+		t.Fatalf("touched: got %v, want [1 2]", touched)
+	}
+	assertCallerIDs(t, backend, map[int]string{1: "74950000002", 2: prevNumber, 3: prevNumber})
+
+	// Ресурс снова доступен к моменту компенсации: во все затронутые ресурсы возвращается прежний номер
+	backend.FailResource(2, nil)
+	recorder := newOutboxStatusRecorder()
+	if err := compensateRotation(recorder, backend, 10, &prevNumber, touched, pushErr); err != nil {
+		t.Fatalf("compensate: %v", err)
+	}
+	if recorder.statuses[10] != outboxCompensated {
+		t.Fatalf("status: got %q, want %q", recorder.statuses[10], outboxCompensated)
+	}
+	assertCallerIDs(t, backend, map[int]string{1: prevNumber, 2: prevNumber, 3: prevNumber})
+}
+
+func TestRotationCompensationRetried(t *testing.T) {
+	backend := NewMemoryBackend()
+	prevNumber := "74950000001"
+	backend.FailResource(1, errors.New("resource unavailable"))
+
+	// Компенсация не удалась: запись остаётся в failed для повтора reconciler'ом
+	recorder := newOutboxStatusRecorder()
+	cause := errors.New("push failed")
+	if err := compensateRotation(recorder, backend, 20, &prevNumber, []int{1}, cause); err == nil {
+		t.Fatal("compensate: expected error")
+	}
+	if recorder.statuses[20] != outboxFailed {
+		t.Fatalf("status: got %q, want %q", recorder.statuses[20], outboxFailed)
+	}
+
+	// Повтор после восстановления ресурса
+	backend.FailResource(1, nil)
+	if err := compensateRotation(recorder, backend, 20, &prevNumber, []int{1}, cause); err != nil {
+		t.Fatalf("compensate retry: %v", err)
+	}
+	if recorder.statuses[20] != outboxCompensated {
+		t.Fatalf("status: got %q, want %q", recorder.statuses[20], outboxCompensated)
+	}
+	assertCallerIDs(t, backend, map[int]string{1: prevNumber})
+}
+
+func TestRotationWithoutPreviousNumber(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	// Восстанавливать нечего: бэкенд не трогаем, фиксируем причину
+	recorder := newOutboxStatusRecorder()
+	if err := compensateRotation(recorder, backend, 30, nil, []int{1}, errors.New("push failed")); err != nil {
+		t.Fatalf("compensate: %v", err)
+	}
+	if recorder.statuses[30] != outboxCompensated {
+		t.Fatalf("status: got %q, want %q", recorder.statuses[30], outboxCompensated)
+	}
+	if len(backend.History()) != 0 {
+		t.Fatalf("history: got %v, want empty", backend.History())
+	}
+}
+
+func TestValidateTeamBackend(t *testing.T) {
+	debugMode := config.API.DebugMode
+	defer func() { config.API.DebugMode = debugMode }()
+
+	config.API.DebugMode = false
+	for _, name := range []string{backendWebitel, backendDB, backendFile} {
+		if err := validateTeamBackend(name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if err := validateTeamBackend(backendMemory); err == nil {
+		t.Fatal("memory: expected error without debug mode")
+	}
+	if err := validateTeamBackend("unknown"); err == nil {
+		t.Fatal("unknown: expected error")
+	}
+
+	config.API.DebugMode = true
+	if err := validateTeamBackend(backendMemory); err != nil {
+		t.Fatalf("memory in debug mode: %v", err)
+	}
+
+	// Команды с backend = memory работают через общий экземпляр
+	backend, err := newDialerBackend(nil, backendMemory)
+	if err != nil {
+		t.Fatalf("newDialerBackend: %v", err)
+	}
+	if backend != DialerBackend(GetMemoryBackend()) {
+		t.Fatal("memory backend is not the shared instance")
+	}
+}
@@ -136,6 +136,8 @@ func reactivateUsedSubPools(db *sqlx.DB, poolID int) error {
 	if err != nil {
 		return fmt.Errorf("failed to update pool in DB: %s", err)
 	}
+	// Оповещение о повторном цикле отправит CheckPoolForecasts() по finish_at
+	OutLog.Printf("Pool ID %v used all subpools and started re-using numbers", poolID)
 	setSubPoolDeactivate(db, poolID, false)
 	return nil
 }
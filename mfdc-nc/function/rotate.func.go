@@ -48,6 +48,32 @@ func getWebitelResourcesIDS(db *sqlx.DB, teamID int) ([]model.Resource, error) {
 	return resourcesList, nil
 }
 
+// ID ресурсов команды, в которые отправляются номера вендора
+func getVendorResourceIDS(db *sqlx.DB, teamID int, vendorID int) ([]int, error) {
+	WebitelResourceIDS, err := getWebitelResourcesIDS(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Флаг для проверки наличия ресурсов
+	vendorFound := false
+	var resourceIDS []int
+
+	// Перебираем вендоров и ищем нужный vendor_id
+	for _, resource := range WebitelResourceIDS {
+		if resource.VendorID == vendorID {
+			vendorFound = true
+			resourceIDS = append(resourceIDS, resource.Resources...)
+		}
+	}
+
+	if !vendorFound {
+		return nil, fmt.Errorf("webitel resource_id is empty for team %v", teamID)
+	}
+
+	return resourceIDS, nil
+}
+
 // Ежедневная ротация
 func DailyRotation(db *sqlx.DB, teamID int, stopCh chan struct{}) error {
 	//OutLog.Println("Run DailyRotation func inside")
@@ -62,9 +88,19 @@ func DailyRotation(db *sqlx.DB, teamID int, stopCh chan struct{}) error {
 		OutLog.Printf("DailyRotation function %v stopped", teamID)
 		return nil
 	default:
+		// Пауза и закреплённый номер, заданные супервизором, важнее расписания
+		hold, err := checkTeamHold(db, teamID)
+		if err != nil {
+			return err
+		}
+		if hold != "" {
+			OutLog.Printf("Rotation for TeamID %v skipped: %s", teamID, hold)
+			return nil
+		}
+
 		var pools []model.Pool
 
-		err := db.Select(&pools, "SELECT * FROM nc.pools WHERE team_id=$1 AND active=$2", teamID, true)
+		err = db.Select(&pools, "SELECT * FROM nc.pools WHERE team_id=$1 AND active=$2", teamID, true)
 		if err != nil {
 			return fmt.Errorf("failed to get active pools from DB: %s", err)
 		}
@@ -140,82 +176,26 @@ func DailyRotation(db *sqlx.DB, teamID int, stopCh chan struct{}) error {
 						subPool.ActivatedAt = &ActivatedAt
 					}
 
-					number, numberErr := getNextAvailableNumber(db, *pool.ID, &subPool)
+					// Следующий номер выбирает стратегия ротации пула
+					number, numberErr := getRotationStrategy(pool.Strategy).NextNumber(db, *pool.ID, &subPool)
 					if numberErr != nil {
 						errChan <- fmt.Errorf("failed to get next available number for subpool %v: %s", subPool.ID, numberErr)
 						continue
 					}
 
-					WebitelResourceIDS, WebitelResourceIDSErr := getWebitelResourcesIDS(db, *pool.TeamID)
-					if WebitelResourceIDSErr != nil {
-						errChan <- fmt.Errorf("failed to get Webitel resources for number ID %v: %s", number.ID, WebitelResourceIDSErr)
+					resourceIDS, resourceErr := getVendorResourceIDS(db, *pool.TeamID, *pool.VendorID)
+					if resourceErr != nil {
+						errChan <- fmt.Errorf("failed to get Webitel resources for number ID %v: %s", number.ID, resourceErr)
 						return
 					}
 
-					vendorID := *pool.VendorID
-
-					// Флаг для проверки наличия ресурсов
-					vendorFound := false
-
-					// Перебираем вендоров и ищем нужный vendor_id
-					for _, resource := range WebitelResourceIDS {
-						if resource.VendorID == vendorID {
-							vendorFound = true
-							// Перебираем ресурсы текущего vendor_id
-							for _, resourceID := range resource.Resources {
-								// Отправляем номер в Webitel
-								numUpdateErr := putToWebitel(resourceID, number.Value)
-								if numUpdateErr != nil {
-									errChan <- fmt.Errorf("failed to update in Webitel number ID %v: %s", number.ID, numUpdateErr)
-									return
-								}
-								OutLog.Printf("Sent number %s to Webitel resource ID %v", number.Value, resourceID)
-							}
-						}
-					}
-
-					if !vendorFound {
-						errChan <- fmt.Errorf("webitel resource_id is empty for team %v", *pool.TeamID)
+					// Отправка в Webitel и запись в БД выполняются через outbox, чтобы шаг ротации не остался наполовину выполненным
+					rotationErr := rotateNumber(db, pool, subPool, number, resourceIDS)
+					if rotationErr != nil {
+						errChan <- fmt.Errorf("failed to rotate number %v: %s", number.ID, rotationErr)
 						return
 					}
 
-					activationErr := activateNumber(db, number)
-					if activationErr != nil {
-						errChan <- fmt.Errorf("failed to activate number %v: %s", number.ID, activationErr)
-						continue
-					}
-
-					//OutLog.Printf("Pool: %v, SubPool: %v", *pool.ID, number.SubPoolID)
-					// Пишем лог окончания
-					logEndErr := endLog(db, subPool)
-					if logEndErr != nil {
-						errChan <- fmt.Errorf("failed to write end log %v: %s", number.ID, logEndErr)
-						continue
-					}
-
-					// Пишем текущий ID номера в сабпул addLastNumberID() обязательно должна быть после endLog()
-					addLastIDErr := addLastNumberID(db, number)
-					if addLastIDErr != nil {
-						errChan <- fmt.Errorf("failed to add last number ID %v: %s", number.ID, addLastIDErr)
-						continue
-					}
-
-					// Пишем лог старта
-					logEntry := model.Logs{
-						NumberID:  number.ID,
-						SubPoolID: subPool.ID,
-						PoolID:    *pool.ID,
-						VendorID:  *pool.VendorID,
-						TeamID:    *pool.TeamID,
-						StartAt:   time.Now(),
-						EndAt:     nil,
-						Comment:   "Used",
-					}
-					logStartErr := startLog(db, logEntry)
-					if logStartErr != nil {
-						errChan <- fmt.Errorf("failed to log activation for number %v: %s", number.ID, logStartErr)
-					}
-
 					OutLog.Printf("Got number: %v, Pool: %v, SubPoolID: %v", number.Value, *pool.Name, subPool.ID)
 					break // Переход к следующему сабпулу после активации номера
 				}
@@ -240,8 +220,13 @@ func DailyRotation(db *sqlx.DB, teamID int, stopCh chan struct{}) error {
 	}
 }
 
-func getNextNumberQuery(db *sqlx.DB, subPoolID int) (*model.Number, error) {
+// Выборка следующего номера сабпула, order - сортировка, которую задаёт стратегия ротации пула
+func getNextNumberQuery(db dbExecutor, subPoolID int, order string) (*model.Number, error) {
 	var number model.Number
+
+	// Учитываем оценку здоровья номера (порог передаётся шестым параметром)
+	healthCondition, healthOrder, healthArgs := healthQueryParts(6)
+
 	// Делаем выборку только тех номеров у которых активные и пул и сабпул
 	query :=
 		`SELECT n.id, n.subpool_id, n.pool_id, n.value, n.used, n.label, n.activated_at, n.spin 
@@ -251,18 +236,20 @@ func getNextNumberQuery(db *sqlx.DB, subPoolID int) (*model.Number, error) {
 	  WHERE sp.status = $1 
 	  AND n.subpool_id = $2 
 	  AND n.label = $3
-	  AND n.enabled = $4 
-	  ORDER BY n.id 
+	  AND n.enabled = $4
+	  AND n.state = $5` + healthCondition + `
+	  ORDER BY ` + healthOrder + order + ` 
 	  LIMIT 1;`
 
-	err := db.Get(&number, query, "active", subPoolID, false, true)
+	args := append([]interface{}{"active", subPoolID, false, true, stateActive}, healthArgs...)
+	err := db.Get(&number, query, args...)
 	return &number, err
 }
 
 // Получение следующего доступного номера
-func getNextAvailableNumber(db *sqlx.DB, poolID int, subPool *model.SubPool) (*model.Number, error) {
+func getNextAvailableNumber(db dbExecutor, poolID int, subPool *model.SubPool, order string) (*model.Number, error) {
 	// Запрос активного номера
-	number, err := getNextNumberQuery(db, subPool.ID)
+	number, err := getNextNumberQuery(db, subPool.ID, order)
 
 	if err != nil {
 		// Обработка случая, когда нет доступных номеров
@@ -287,7 +274,7 @@ func getNextAvailableNumber(db *sqlx.DB, poolID int, subPool *model.SubPool) (*m
 				}
 
 				// Запрос активного номера
-				number, err = getNextNumberQuery(db, subPool.ID)
+				number, err = getNextNumberQuery(db, subPool.ID, order)
 				if err != nil {
 					if err == sql.ErrNoRows {
 						return nil, fmt.Errorf("cycle number not found in subpool: %v", subPool.ID)
@@ -322,7 +309,7 @@ func getNextAvailableNumber(db *sqlx.DB, poolID int, subPool *model.SubPool) (*m
 	return number, nil
 }
 
-func addLastNumberID(db *sqlx.DB, number *model.Number) error {
+func addLastNumberID(db dbExecutor, number *model.Number) error {
 	_, err := db.NamedExec("UPDATE nc.subpools SET last_nid=:id WHERE id=:subpool_id", number)
 	if err != nil {
 		return fmt.Errorf("failed to update subpool: %s", err)
@@ -332,7 +319,7 @@ func addLastNumberID(db *sqlx.DB, number *model.Number) error {
 }
 
 // Активация номера
-func activateNumber(db *sqlx.DB, number *model.Number) error {
+func activateNumber(db dbExecutor, number *model.Number) error {
 	if number.Label != nil {
 		Label := true
 		number.Label = &Label
@@ -366,7 +353,7 @@ func activateNumber(db *sqlx.DB, number *model.Number) error {
 }
 
 // Логирование активации номера
-func startLog(db *sqlx.DB, logEntry model.Logs) error {
+func startLog(db dbExecutor, logEntry model.Logs) error {
 	_, err := db.NamedExec("INSERT INTO nc.logs (number_id, subpool_id, pool_id, vendor_id, team_id, start_at, end_at, comment) VALUES (:number_id, :subpool_id, :pool_id, :vendor_id, :team_id, :start_at, :end_at, :comment)", logEntry)
 	if err != nil {
 		return fmt.Errorf("failed to save log to DB: %s", err)
@@ -375,7 +362,7 @@ func startLog(db *sqlx.DB, logEntry model.Logs) error {
 }
 
 // Функция получения количества сабпулов в пуле
-func countSubPools(db *sqlx.DB, PoolId int) (subpool_count int, err error) {
+func countSubPools(db dbExecutor, PoolId int) (subpool_count int, err error) {
 	var subpoolCount int
 	err = db.Get(&subpoolCount, "SELECT COUNT(id) FROM nc.subpools WHERE pool_id=$1", PoolId)
 	if err != nil {
@@ -385,7 +372,7 @@ func countSubPools(db *sqlx.DB, PoolId int) (subpool_count int, err error) {
 }
 
 // endLog записывает стоп-время номера по id из сабпула
-func endLog(db *sqlx.DB, subPool model.SubPool) error {
+func endLog(db dbExecutor, subPool model.SubPool) error {
 	var subpool_exist bool
 
 	err := db.Get(&subpool_exist, "SELECT EXISTS(SELECT 1 FROM nc.subpools WHERE pool_id=$1 AND last_changed=$2)", subPool.PoolID, true)
@@ -436,5 +423,11 @@ func endLog(db *sqlx.DB, subPool model.SubPool) error {
 		return fmt.Errorf("failed to update end log: %s", err)
 	}
 
+	// После интенсивного использования отправляем номер на охлаждение
+	err = checkNumberCooldown(db, previousNumberID)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -14,19 +14,149 @@ func CheckActiveTime(startTime time.Time, stopTime time.Time) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to load locations: %s", err)
 	}
-	// Получаем текущее время в нужной временной зоне
-	now := time.Now().In(loc)
+
+	return checkActiveTimeAt(time.Now(), startTime, stopTime, loc), nil
+}
+
+// Проверка попадания момента времени в окно расписания, используется и для симуляции
+func checkActiveTimeAt(at time.Time, startTime time.Time, stopTime time.Time, loc *time.Location) bool {
+	// Получаем время в нужной временной зоне
+	now := at.In(loc)
 
 	// Устанавливаем дату для startTime и stopTime на текущую дату
 	startTime = time.Date(now.Year(), now.Month(), now.Day(), startTime.Hour(), startTime.Minute(), startTime.Second(), 0, loc)
 	stopTime = time.Date(now.Year(), now.Month(), now.Day(), stopTime.Hour(), stopTime.Minute(), stopTime.Second(), 0, loc)
 
+	// Окно через полночь: активны вечер текущего дня и утро следующего. При start_time = stop_time окно пустое
+	if stopTime.Before(startTime) {
+		return now.After(startTime) || now.Before(stopTime)
+	}
+
 	// Сравниваем текущее время с диапазоном
-	if now.After(startTime) && now.Before(stopTime) { // Текущее время находится в диапазоне
-		return true, nil
-	} else {
+	return now.After(startTime) && now.Before(stopTime)
+}
+
+const (
+	scheduleDateLayout = "2006-01-02"
+	scheduleTimeLayout = "15:04"
+)
+
+// Проверка расписания в момент времени: исключения > cron > недельные окна > start_time/stop_time
+func scheduleActiveAt(schedule model.Scheduler, at time.Time, loc *time.Location) (bool, error) {
+	now := at.In(loc)
+
+	// В даты исключений ротация не запускается
+	today := now.Format(scheduleDateLayout)
+	for _, date := range schedule.Exceptions {
+		if date == today {
+			return false, nil
+		}
+	}
+
+	if schedule.Cron != nil && *schedule.Cron != "" {
+		expr, err := parseCron(*schedule.Cron)
+		if err != nil {
+			return false, err
+		}
+		return expr.match(now), nil
+	}
+
+	if len(schedule.Windows) > 0 {
+		for _, window := range schedule.Windows {
+			active, err := windowActiveAt(window, now)
+			if err != nil {
+				return false, err
+			}
+			if active {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if schedule.StartTime == nil || schedule.StopTime == nil {
 		return false, nil
 	}
+	return checkActiveTimeAt(now, schedule.StartTime.Time, schedule.StopTime.Time, loc), nil
+}
+
+// Проверка недельного окна, now уже в часовом поясе ротации
+func windowActiveAt(window model.ScheduleWindow, now time.Time) (bool, error) {
+	start, err := time.Parse(scheduleTimeLayout, window.Start)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse window start %q: %w", window.Start, err)
+	}
+	stop, err := time.Parse(scheduleTimeLayout, window.Stop)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse window stop %q: %w", window.Stop, err)
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	stopMinute := stop.Hour()*60 + stop.Minute()
+
+	if startMinute < stopMinute {
+		return windowDay(window, now.Weekday()) && minute >= startMinute && minute < stopMinute, nil
+	}
+
+	// Окно через полночь: вечер дня из списка или утро следующего за ним дня
+	if minute >= startMinute && windowDay(window, now.Weekday()) {
+		return true, nil
+	}
+	return minute < stopMinute && windowDay(window, (now.Weekday()+6)%7), nil
+}
+
+// Проверка дня недели окна, пустой список - каждый день
+func windowDay(window model.ScheduleWindow, day time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, d := range window.Days {
+		if d%7 == int(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// Проверка параметров расписания перед сохранением
+func validateSchedule(schedule model.Scheduler) error {
+	hasCron := schedule.Cron != nil && *schedule.Cron != ""
+	hasTime := schedule.StartTime != nil && schedule.StopTime != nil
+	if !hasCron && len(schedule.Windows) == 0 && !hasTime {
+		return fmt.Errorf("schedule requires cron, windows or start_time/stop_time")
+	}
+
+	if hasCron {
+		if _, err := parseCron(*schedule.Cron); err != nil {
+			return err
+		}
+	}
+
+	for i, window := range schedule.Windows {
+		if _, err := time.Parse(scheduleTimeLayout, window.Start); err != nil {
+			return fmt.Errorf("window %d: start must be in HH:MM format", i)
+		}
+		if _, err := time.Parse(scheduleTimeLayout, window.Stop); err != nil {
+			return fmt.Errorf("window %d: stop must be in HH:MM format", i)
+		}
+		if window.Start == window.Stop {
+			return fmt.Errorf("window %d: start and stop must differ", i)
+		}
+		for _, day := range window.Days {
+			if day < 0 || day > 7 {
+				return fmt.Errorf("window %d: day %d out of range 0-7", i, day)
+			}
+		}
+	}
+
+	for _, date := range schedule.Exceptions {
+		if _, err := time.Parse(scheduleDateLayout, date); err != nil {
+			return fmt.Errorf("exception date %q must be in YYYY-MM-DD format", date)
+		}
+	}
+
+	return nil
 }
 
 func GetSchedulerSlice(db *sqlx.DB) (data []model.Scheduler, err error) {
@@ -44,11 +174,19 @@ func ScheduleRotate(db *sqlx.DB) error {
 		return fmt.Errorf("failed to get schedulers: %s", err)
 	}
 
+	// Устанавливаем временную зону
+	loc, err := time.LoadLocation(config.Rotate.TimeZone)
+	if err != nil {
+		return fmt.Errorf("failed to load locations: %s", err)
+	}
+
 	// Перебираем расписания
 	for _, schedule := range data {
-		activeTime, err := CheckActiveTime(schedule.StartTime.Time, schedule.StopTime.Time)
+		// Ошибка в одном расписании не должна останавливать остальные
+		activeTime, err := scheduleActiveAt(schedule, time.Now(), loc)
 		if err != nil {
-			return fmt.Errorf("failed to check time: %s", err)
+			ErrLog.Printf("Failed to check time for schedule %v: %s", *schedule.ID, err)
+			continue
 		}
 
 		var Running bool
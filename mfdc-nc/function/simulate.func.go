@@ -0,0 +1,442 @@
+package function
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"nc/model"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Шаг симуляции совпадает с тикером StartPeriodicRotation()
+const simulateTick = 10 * time.Second
+
+// Снимок номера для симуляции
+type simNumber struct {
+	ID          int        `db:"id"`
+	SubPoolID   int        `db:"subpool_id"`
+	PoolID      int        `db:"pool_id"`
+	Value       string     `db:"value"`
+	Label       bool       `db:"label"`
+	Enabled     bool       `db:"enabled"`
+	Health      *float64   `db:"health_score"`
+	ActivatedAt *time.Time `db:"activated_at"`
+	Spin        int        `db:"spin"`
+	used        bool
+}
+
+// Снимок сабпула для симуляции
+type simSubPool struct {
+	ID      int    `db:"id"`
+	PoolID  int    `db:"pool_id"`
+	Index   int    `db:"index"`
+	Status  string `db:"status"`
+	numbers []*simNumber
+}
+
+// Снимок пула для симуляции
+type simPool struct {
+	pool        model.Pool
+	actual      bool
+	subActivate bool
+	subPools    []*simSubPool
+	dry         *model.SimulateDryPool
+}
+
+// Снимок команды с расписанием
+type simTeam struct {
+	result    *model.SimulateTeam
+	schedules []model.Scheduler
+	pools     []*simPool
+	lastRun   *time.Time
+}
+
+// Загрузка снимка пулов, сабпулов и номеров команды
+func loadSimulatePools(db *sqlx.DB, teamID int) ([]*simPool, error) {
+	var actualVendorID *int
+	err := db.Get(&actualVendorID, "SELECT actual_vendor_id FROM nc.teams WHERE id=$1", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team %v: %w", teamID, err)
+	}
+
+	var pools []model.Pool
+	err = db.Select(&pools, "SELECT * FROM nc.pools WHERE team_id=$1 AND active=$2 ORDER BY id", teamID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active pools for team %v: %w", teamID, err)
+	}
+
+	var result []*simPool
+	for _, pool := range pools {
+		sp := &simPool{
+			pool:        pool,
+			actual:      actualVendorID == nil || (pool.VendorID != nil && *actualVendorID == *pool.VendorID),
+			subActivate: pool.SubActivate != nil && *pool.SubActivate,
+		}
+
+		var subPools []*simSubPool
+		err = db.Select(&subPools, "SELECT id, pool_id, index, COALESCE(status, '') AS status FROM nc.subpools WHERE pool_id=$1 ORDER BY id", *pool.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subpools for pool %v: %w", *pool.ID, err)
+		}
+
+		var numbers []*simNumber
+		err = db.Select(&numbers, "SELECT id, subpool_id, pool_id, value, COALESCE(label, false) AS label, (COALESCE(enabled, false) AND state = 'active') AS enabled, health_score, activated_at, COALESCE(spin, 0) AS spin FROM nc.numbers WHERE pool_id=$1 ORDER BY id", *pool.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get numbers for pool %v: %w", *pool.ID, err)
+		}
+
+		bySubPool := make(map[int]*simSubPool, len(subPools))
+		for _, subPool := range subPools {
+			bySubPool[subPool.ID] = subPool
+		}
+		for _, number := range numbers {
+			if subPool, ok := bySubPool[number.SubPoolID]; ok {
+				subPool.numbers = append(subPool.numbers, number)
+			}
+		}
+
+		sp.subPools = subPools
+		result = append(result, sp)
+	}
+
+	return result, nil
+}
+
+// Отметка о том, что пул исчерпал номера (фиксируется только первое событие)
+func (p *simPool) markDry(at time.Time, reason string) {
+	if p.dry != nil {
+		return
+	}
+	p.dry = &model.SimulateDryPool{
+		PoolID:   *p.pool.ID,
+		PoolName: *p.pool.Name,
+		At:       at,
+		Reason:   reason,
+	}
+}
+
+func (p *simPool) countSubPools(status string) int {
+	count := 0
+	for _, subPool := range p.subPools {
+		if subPool.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Выбор номера из сабпула в порядке getNextNumberQuery() с учётом оценки здоровья и стратегии пула
+func (subPool *simSubPool) pickNumber(strategy string) *simNumber {
+	var candidates, low []*simNumber
+	for _, number := range subPool.numbers {
+		if number.Label || !number.Enabled {
+			continue
+		}
+		if isLowHealth(number.Health) {
+			if config.Health.Mode != healthModeSkip {
+				low = append(low, number)
+			}
+			continue
+		}
+		candidates = append(candidates, number)
+	}
+
+	if len(candidates) == 0 {
+		candidates = low
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	switch strategy {
+	case strategyRandom:
+		return candidates[rand.Intn(len(candidates))]
+	case strategyLRU:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i].ActivatedAt, candidates[j].ActivatedAt
+			if a == nil || b == nil {
+				return a == nil && b != nil
+			}
+			return a.Before(*b)
+		})
+	case strategyWeighted:
+		// Тот же экспоненциальный ключ, что и в запросе стратегии weighted
+		best, bestKey := candidates[0], math.Inf(1)
+		for _, number := range candidates {
+			key := rand.ExpFloat64() * float64(number.Spin+1)
+			if key < bestKey {
+				best, bestKey = number, key
+			}
+		}
+		return best
+	}
+
+	return candidates[0]
+}
+
+// Аналог getNextAvailableNumber() на снимке
+func (p *simPool) nextNumber(t *simTeam, at time.Time, subPool *simSubPool) *simNumber {
+	strategy := getRotationStrategy(p.pool.Strategy).Name()
+	if number := subPool.pickNumber(strategy); number != nil {
+		return number
+	}
+
+	if p.countSubPools("active") == 1 {
+		// Обнуляем номера в сабпуле для повторного цикла
+		for _, number := range subPool.numbers {
+			number.Label = false
+		}
+		t.addEvent(at, "subpool_cycle", p, subPool.ID, nil)
+		p.markDry(at, fmt.Sprintf("subpool %v ran out of numbers and started reusing them", subPool.ID))
+
+		return subPool.pickNumber(strategy)
+	}
+
+	subPool.Status = "used"
+	return nil
+}
+
+// Аналог DailyRotation() для одного пула
+func (p *simPool) rotate(t *simTeam, at time.Time) {
+	if !p.actual || p.subActivate {
+		return
+	}
+
+	for _, subPool := range p.subPools {
+		if subPool.Status != "active" {
+			continue
+		}
+
+		number := p.nextNumber(t, at, subPool)
+		if number == nil {
+			continue
+		}
+
+		number.Label = true
+		number.used = true
+		number.ActivatedAt = &at
+		number.Spin++
+		t.result.Rotations++
+		t.addEvent(at, "rotate", p, subPool.ID, number)
+		return
+	}
+
+	t.addEvent(at, "no_number", p, 0, nil)
+	p.markDry(at, "no available number in active subpools")
+}
+
+// Аналог activateNewSubPool() для одного пула
+func (p *simPool) activateNextSubPool(t *simTeam, at time.Time) {
+	if !p.actual {
+		return
+	}
+	p.subActivate = false
+
+	// Деактивируем старый сабпул
+	for _, subPool := range p.subPools {
+		if subPool.Status == "active" {
+			subPool.Status = "used"
+			break
+		}
+	}
+
+	// Активируем новый сабпул
+	for _, subPool := range p.subPools {
+		if subPool.Status == "inactive" {
+			subPool.Status = "active"
+			t.addEvent(at, "subpool_activate", p, subPool.ID, nil)
+			return
+		}
+	}
+
+	// Новых сабпулов нет, пул уходит на повторный цикл (reactivateUsedSubPools)
+	if p.countSubPools("used") > 0 {
+		for _, subPool := range p.subPools {
+			subPool.Status = "inactive"
+			if subPool.Index == 0 {
+				subPool.Status = "active"
+			}
+			for _, number := range subPool.numbers {
+				number.Label = false
+			}
+		}
+		t.addEvent(at, "pool_reuse", p, 0, nil)
+		p.markDry(at, "all subpools used, pool started reusing numbers")
+	}
+}
+
+func (t *simTeam) addEvent(at time.Time, event string, p *simPool, subPoolID int, number *simNumber) {
+	entry := model.SimulateEvent{
+		At:        at,
+		Event:     event,
+		PoolID:    *p.pool.ID,
+		PoolName:  *p.pool.Name,
+		SubPoolID: subPoolID,
+	}
+	if number != nil {
+		entry.NumberID = number.ID
+		entry.Number = number.Value
+	}
+	t.result.Timeline = append(t.result.Timeline, entry)
+}
+
+// Проверка, запущено ли хотя бы одно расписание команды в момент времени (аналог ScheduleRotate())
+func (t *simTeam) running(at time.Time, loc *time.Location) (bool, int) {
+	for _, schedule := range t.schedules {
+		if schedule.PeriodicSecond == nil {
+			continue
+		}
+		// Ошибки в расписании отсекаются на validateSchedule(), здесь такое расписание просто не активно
+		if active, _ := scheduleActiveAt(schedule, at, loc); active {
+			return true, *schedule.PeriodicSecond
+		}
+	}
+	return false, 0
+}
+
+// Симуляция ротации по расписаниям без обращения к Webitel и без изменений в БД
+func SimulateRotation(db *sqlx.DB, request model.SimulateRequest) (*model.SimulateResponse, error) {
+	loc, err := time.LoadLocation(config.Rotate.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load locations: %s", err)
+	}
+
+	hours := 24
+	if request.Hours != nil {
+		hours = *request.Hours
+	}
+	if hours < 1 || hours > 168 {
+		return nil, fmt.Errorf("hours must be between 1 and 168")
+	}
+
+	schedules, err := GetSchedulerSlice(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedulers: %s", err)
+	}
+
+	// Черновик расписания заменяет сохранённые расписания своей команды
+	if request.Schedule != nil {
+		if request.Schedule.TeamID == nil || request.Schedule.PeriodicSecond == nil {
+			return nil, fmt.Errorf("schedule draft requires team_id and periodic_sec")
+		}
+		if err := validateSchedule(*request.Schedule); err != nil {
+			return nil, fmt.Errorf("invalid schedule draft: %w", err)
+		}
+		filtered := schedules[:0]
+		for _, schedule := range schedules {
+			if schedule.TeamID == nil || *schedule.TeamID != *request.Schedule.TeamID {
+				filtered = append(filtered, schedule)
+			}
+		}
+		schedules = append(filtered, *request.Schedule)
+	}
+
+	teams := make(map[int]*simTeam)
+	var teamIDS []int
+	for _, schedule := range schedules {
+		if schedule.TeamID == nil || schedule.PeriodicSecond == nil || *schedule.PeriodicSecond <= 0 {
+			continue
+		}
+		if request.TeamID != nil && *request.TeamID != *schedule.TeamID {
+			continue
+		}
+
+		team, ok := teams[*schedule.TeamID]
+		if !ok {
+			team = &simTeam{result: &model.SimulateTeam{TeamID: *schedule.TeamID, Timeline: []model.SimulateEvent{}}}
+			teamName, err := getTeamByID(db, *schedule.TeamID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get team name, error: %v", err)
+			}
+			team.result.TeamName = teamName
+
+			team.pools, err = loadSimulatePools(db, *schedule.TeamID)
+			if err != nil {
+				return nil, err
+			}
+
+			// Время последней ротации берём по последнему активированному номеру команды
+			var lastRun *time.Time
+			err = db.Get(&lastRun, "SELECT MAX(activated_at) FROM nc.numbers WHERE team_id=$1 AND active=$2", *schedule.TeamID, true)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get last rotation for team %v: %w", *schedule.TeamID, err)
+			}
+			team.lastRun = lastRun
+
+			teams[*schedule.TeamID] = team
+			teamIDS = append(teamIDS, *schedule.TeamID)
+		}
+
+		if schedule.Name != nil {
+			team.result.Schedule = *schedule.Name
+		}
+		team.result.PeriodicSec = *schedule.PeriodicSecond
+		team.schedules = append(team.schedules, schedule)
+	}
+	sort.Ints(teamIDS)
+
+	from := time.Now().In(loc)
+	to := from.Add(time.Duration(hours) * time.Hour)
+
+	// Ближайший запуск ежедневной активации сабпулов (SubPoolActivate)
+	nextActivate := time.Date(from.Year(), from.Month(), from.Day(), config.Rotate.SubpoolActivateTimeHour, config.Rotate.SubpoolActivateTimeMinut, 0, 0, loc)
+	if from.After(nextActivate) {
+		nextActivate = nextActivate.Add(24 * time.Hour)
+	}
+
+	for at := from; at.Before(to); at = at.Add(simulateTick) {
+		if !at.Before(nextActivate) {
+			for _, teamID := range teamIDS {
+				for _, pool := range teams[teamID].pools {
+					pool.activateNextSubPool(teams[teamID], nextActivate)
+				}
+			}
+			nextActivate = nextActivate.Add(24 * time.Hour)
+		}
+
+		for _, teamID := range teamIDS {
+			team := teams[teamID]
+			running, periodic := team.running(at, loc)
+			if !running {
+				continue
+			}
+			if team.lastRun != nil && at.Sub(*team.lastRun) < time.Duration(periodic)*time.Second {
+				continue
+			}
+
+			for _, pool := range team.pools {
+				pool.rotate(team, at)
+			}
+			runAt := at
+			team.lastRun = &runAt
+		}
+	}
+
+	response := &model.SimulateResponse{From: from, To: to, Teams: []model.SimulateTeam{}}
+	for _, teamID := range teamIDS {
+		team := teams[teamID]
+		for _, pool := range team.pools {
+			if pool.dry != nil {
+				team.result.DryPools = append(team.result.DryPools, *pool.dry)
+			}
+
+			stat := model.SimulatePoolStat{PoolID: *pool.pool.ID, PoolName: *pool.pool.Name}
+			for _, subPool := range pool.subPools {
+				for _, number := range subPool.numbers {
+					if number.used {
+						stat.NumbersUsed++
+					} else if number.Enabled {
+						stat.NumbersUnused++
+					}
+				}
+			}
+			team.result.Pools = append(team.result.Pools, stat)
+		}
+		response.Teams = append(response.Teams, *team.result)
+	}
+
+	return response, nil
+}
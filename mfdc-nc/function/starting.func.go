@@ -32,6 +32,22 @@ func TestStartSubPoolActivateTask(ctx context.Context, db *sqlx.DB) {
 	}
 }
 
+// Запуск ротации: довод прерванных шагов, расписания, ротация номеров и ежедневная активация сабпулов
+func StartRotation(ctx context.Context, db *sqlx.DB) {
+	// Доводим или откатываем шаги ротации, прерванные при прошлом запуске или на прошлом лидере
+	err := ReconcileRotations(db)
+	if err != nil {
+		ErrLog.Printf("Error reconciling rotations: %v", err)
+	}
+
+	runLeaderJobs(ctx, db, []LeaderJob{
+		StartRotationSchedule, // Запуск ротации расписаний
+		StartPeriodicRotation, // Запуск ротации номеров пул > сабпул > номер
+		SubPoolActivate,       // Ежедневная активация сабпулов
+		StartNumberLifecycle,  // Возврат номеров из охлаждения и карантина
+	})
+}
+
 // Запуск перебора расписаний
 func StartRotationSchedule(ctx context.Context, db *sqlx.DB) {
 	ticker := time.NewTicker(9 * time.Second)
@@ -52,14 +68,12 @@ func StartRotationSchedule(ctx context.Context, db *sqlx.DB) {
 	}
 }
 
-// Запуск ротации расписания > пулов > сабпулов > номеров
+// Запуск ротации расписания > пулов > сабпулов > номеров: по воркеру на каждую команду с активным расписанием
 func StartPeriodicRotation(ctx context.Context, db *sqlx.DB) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
-	lastRunTimes := make(map[int]time.Time)
-	// Создаём канал для возможности остановки горутины с DailyRotation() когда текущий scheduler.Running == false
-	stopChans := make(map[int]chan struct{})
+	supervisor := newRotationSupervisor()
 
 	for {
 		select {
@@ -68,57 +82,13 @@ func StartPeriodicRotation(ctx context.Context, db *sqlx.DB) {
 			data, err := GetSchedulerSlice(db)
 			if err != nil {
 				ErrLog.Printf("Failed to get schedulers: %s", err)
-				return
-			}
-			// Перебираем расписания
-			for _, scheduler := range data {
-				if scheduler.Running {
-					//OutLog.Printf("Schedule %s worktime: %v", *scheduler.Name, scheduler.Running)
-					currentTime := time.Now()
-					lastRunTime, exists := lastRunTimes[*scheduler.TeamID]
-
-					// Проверяем, прошло ли достаточно времени с последнего запуска
-					if !exists || currentTime.Sub(lastRunTime) >= time.Duration(*scheduler.PeriodicSecond)*time.Second {
-						OutLog.Printf("Schedule is running %v, periodic: %v", *scheduler.Name, *scheduler.PeriodicSecond)
-
-						// Если уже существует горутина для этого teamID, то отправляем сигнал остановки
-						if ch, ok := stopChans[*scheduler.TeamID]; ok {
-							close(ch) // Закрываем канал, чтобы сигнализировать о завершении
-						}
-
-						// Создаем новый канал для остановки
-						stopCh := make(chan struct{})
-						stopChans[*scheduler.TeamID] = stopCh
-
-						// Запускаем DailyRotation
-						go func(teamID int, stopCh chan struct{}) {
-							OutLog.Printf("Run rotation for TeamID %v", teamID)
-							start := time.Now()                      // Начало выполнения
-							err := DailyRotation(db, teamID, stopCh) // Передаем канал в функцию
-							if err != nil {
-								ErrLog.Printf("Error during rotation: %s", err)
-							}
-							duration := time.Since(start) // Время выполнения
-							OutLog.Printf("TeamID %v rotation lasted %s", teamID, duration)
-
-							// Обновляем время последнего выполнения
-							lastRunTimes[teamID] = time.Now()
-						}(*scheduler.TeamID, stopCh)
-					}
-				} else {
-					// Если scheduler.Running == false, закрываем канал, если он существует
-					if ch, ok := stopChans[*scheduler.TeamID]; ok {
-						close(ch)
-						delete(stopChans, *scheduler.TeamID) // Удаляем канал из мапы
-					}
-				}
+				continue
 			}
+			supervisor.sync(db, data)
 		case <-ctx.Done():
 			OutLog.Println("Stopping periodic rotation...")
-			// Закрываем все каналы остановки перед завершением
-			for _, ch := range stopChans {
-				close(ch)
-			}
+			// Останавливаем воркеры и ждём завершения начатых шагов ротации
+			supervisor.stopAll()
 			return
 		}
 	}
@@ -183,3 +153,104 @@ func StartVCSync(ctx context.Context, db *sqlx.DB) {
 		}
 	}
 }
+
+// Периодический пересчёт оценок здоровья номеров по cdr.calls
+func StartHealthScoring(ctx context.Context, db *sqlx.DB) {
+	interval := time.Duration(config.Health.IntervalMinute) * time.Minute
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select { // Ожидание событий от нескольких каналов
+		case <-ticker.C: // Ожидаем данные из канала ticker с полем C (срабатывание таймера, сигнал. ticker тип time.Ticker)
+			count, err := CalcNumbersHealth(db)
+			if err != nil {
+				ErrLog.Printf("Health scoring error: %s", err)
+				continue
+			}
+			OutLog.Printf("Health scores updated for %v numbers", count)
+		case <-ctx.Done(): // Если контекст горутины завершает родительский процесс
+			OutLog.Println("Stopping health scoring...")
+			return // Завершаем выполнение функции
+		}
+	}
+}
+
+// Периодическая проверка прогноза исчерпания пулов и отправка оповещений
+func StartPoolForecast(ctx context.Context, db *sqlx.DB) {
+	interval := time.Duration(config.Forecast.IntervalMinute) * time.Minute
+	if interval <= 0 {
+		interval = 60 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select { // Ожидание событий от нескольких каналов
+		case <-ticker.C: // Ожидаем данные из канала ticker с полем C (срабатывание таймера, сигнал. ticker тип time.Ticker)
+			err := CheckPoolForecasts(db)
+			if err != nil {
+				ErrLog.Printf("Pool forecast error: %s", err)
+			}
+		case <-ctx.Done(): // Если контекст горутины завершает родительский процесс
+			OutLog.Println("Stopping pool forecast...")
+			return // Завершаем выполнение функции
+		}
+	}
+}
+
+// Периодическая сверка ресурсов Webitel с активными номерами
+func StartWebitelVerify(ctx context.Context, db *sqlx.DB) {
+	interval := time.Duration(config.Verify.IntervalMinute) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select { // Ожидание событий от нескольких каналов
+		case <-ticker.C: // Ожидаем данные из канала ticker с полем C (срабатывание таймера, сигнал. ticker тип time.Ticker)
+			err := VerifyWebitelResources(db)
+			if err != nil {
+				ErrLog.Printf("Webitel verify error: %s", err)
+			}
+		case <-ctx.Done(): // Если контекст горутины завершает родительский процесс
+			OutLog.Println("Stopping Webitel verify...")
+			return // Завершаем выполнение функции
+		}
+	}
+}
+
+// Периодический возврат в ротацию номеров, у которых закончилось охлаждение или карантин
+func StartNumberLifecycle(ctx context.Context, db *sqlx.DB) {
+	interval := time.Duration(config.Lifecycle.IntervalMinute) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select { // Ожидание событий от нескольких каналов
+		case <-ticker.C: // Ожидаем данные из канала ticker с полем C (срабатывание таймера, сигнал. ticker тип time.Ticker)
+			count, err := ReleaseNumberStates(db)
+			if err != nil {
+				ErrLog.Printf("Number lifecycle error: %s", err)
+			}
+			if count > 0 {
+				OutLog.Printf("%v numbers returned to rotation", count)
+			}
+		case <-ctx.Done(): // Если контекст горутины завершает родительский процесс
+			OutLog.Println("Stopping number lifecycle...")
+			return // Завершаем выполнение функции
+		}
+	}
+}
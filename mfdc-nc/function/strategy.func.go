@@ -0,0 +1,70 @@
+package function
+
+import (
+	"fmt"
+	"nc/model"
+	"sort"
+	"strings"
+)
+
+// Стратегии выбора следующего номера в сабпуле
+const (
+	strategySequential = "sequential" // По порядку ID (исходное поведение)
+	strategyRandom     = "random"     // Случайно, без повторов до конца цикла сабпула
+	strategyLRU        = "lru"        // Номер, который дольше всех не активировался
+	strategyWeighted   = "weighted"   // Случайно с приоритетом номеров с меньшим spin
+)
+
+// Стратегия ротации пула: выбирает следующий номер в активном сабпуле
+type RotationStrategy interface {
+	Name() string
+	NextNumber(db dbExecutor, poolID int, subPool *model.SubPool) (*model.Number, error)
+}
+
+// Стратегия, которая отличается только сортировкой номеров в выборке
+type orderStrategy struct {
+	name  string
+	order string
+}
+
+func (s orderStrategy) Name() string {
+	return s.name
+}
+
+func (s orderStrategy) NextNumber(db dbExecutor, poolID int, subPool *model.SubPool) (*model.Number, error) {
+	return getNextAvailableNumber(db, poolID, subPool, s.order)
+}
+
+// Повторы внутри цикла исключает сам getNextNumberQuery() по label, поэтому random достаточно сортировки.
+// Для weighted используется экспоненциальный ключ: вероятность выбора номера обратно пропорциональна spin+1
+var rotationStrategies = map[string]RotationStrategy{
+	strategySequential: orderStrategy{name: strategySequential, order: "n.id"},
+	strategyRandom:     orderStrategy{name: strategyRandom, order: "random()"},
+	strategyLRU:        orderStrategy{name: strategyLRU, order: "n.activated_at NULLS FIRST, n.id"},
+	strategyWeighted:   orderStrategy{name: strategyWeighted, order: "-ln(1 - random()) * (COALESCE(n.spin, 0) + 1)"},
+}
+
+// Получение стратегии пула, по умолчанию sequential
+func getRotationStrategy(name *string) RotationStrategy {
+	if name != nil {
+		if strategy, ok := rotationStrategies[*name]; ok {
+			return strategy
+		}
+	}
+	return rotationStrategies[strategySequential]
+}
+
+// Проверка названия стратегии
+func checkRotationStrategy(name string) error {
+	if _, ok := rotationStrategies[name]; ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(rotationStrategies))
+	for strategyName := range rotationStrategies {
+		names = append(names, strategyName)
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("unknown rotation strategy %q, available: %s", name, strings.Join(names, ", "))
+}
@@ -1,7 +1,7 @@
 package function
 
 import (
-	"encoding/csv"
+	"database/sql"
 	"fmt"
 	"math/rand"
 	"nc/model"
@@ -21,7 +21,7 @@ func randomName(name string) string {
 	return name
 }
 
-func checkPoolExists(db *sqlx.DB, pool_name string) (exists bool, err error) {
+func checkPoolExists(db dbExecutor, pool_name string) (exists bool, err error) {
 	err = db.Get(&exists, "SELECT EXISTS (SELECT 1 FROM nc.pools WHERE name = $1)", &pool_name)
 	if err != nil {
 		return false, fmt.Errorf("failed to check pool: %w", err)
@@ -30,7 +30,7 @@ func checkPoolExists(db *sqlx.DB, pool_name string) (exists bool, err error) {
 }
 
 // Функция для создания нового пула
-func createPool(db *sqlx.DB, name string, subpool_block int, vendor_id int, team_id int, num_count int, subpool_count int) (string, int, error) {
+func createPool(db dbExecutor, name string, subpool_block int, vendor_id int, team_id int, num_count int, subpool_count int) (string, int, error) {
 	var poolID int
 	name = randomName(name)
 	count, err := checkPoolExists(db, name)
@@ -53,7 +53,7 @@ func createPool(db *sqlx.DB, name string, subpool_block int, vendor_id int, team
 }
 
 // Функция для создания сабпула
-func createSubPool(db *sqlx.DB, poolID int, index int) (int, error) {
+func createSubPool(db dbExecutor, poolID int, index int) (int, error) {
 	var subPoolID int
 	query := "INSERT INTO nc.subpools (pool_id, status, index) VALUES ($1, $2, $3) RETURNING id"
 	err := db.QueryRow(query, poolID, "inactive", index).Scan(&subPoolID)
@@ -64,24 +64,52 @@ func createSubPool(db *sqlx.DB, poolID int, index int) (int, error) {
 }
 
 // Функция для добавления номера
-func createNumber(db *sqlx.DB, value string, label bool, pool_id int, subpool_id int, vendor_id int, team_id int) (int, error) {
+func createNumber(db dbExecutor, value string, label bool, pool_id int, subpool_id int, vendor_id int, team_id int) (int, error) {
 	var numberID int
-	query := "INSERT INTO nc.numbers (value, label, used, pool_id, subpool_id, vendor_id, team_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
-	err := db.QueryRow(query, value, label, label, pool_id, subpool_id, vendor_id, team_id).Scan(&numberID)
+	// Код региона и регион нужны для подбора местного номера в /numbers/callerid
+	areaCode, region := numberGeo(value)
+	query := "INSERT INTO nc.numbers (value, label, used, pool_id, subpool_id, vendor_id, team_id, area_code, region) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	err := db.QueryRow(query, value, label, label, pool_id, subpool_id, vendor_id, team_id, areaCode, region).Scan(&numberID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create number: %w", err)
 	}
 	return numberID, nil
 }
 
+// Чтение номеров из файла формы (поля file и format)
+func readUploadFile(c *gin.Context) (string, []string, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, fmt.Errorf("file is required: %w", err)
+	}
+
+	format, err := detectImportFormat(c.PostForm("format"), file.Filename)
+	if err != nil {
+		return "", nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to open file: %w", err)
+	}
+	defer src.Close()
+
+	values, err := readImportValues(src, format)
+	if err != nil {
+		return "", nil, err
+	}
+	return format, values, nil
+}
+
 // UploadNumbers обработчик загрузки номеров
 // @Summary      Upload numbers
-// @Description  Upload a file and JSON data
+// @Description  Validate numbers file (CSV, XLSX or JSON) and return report with accepted and rejected rows. Pool is created only after confirmation
 // @Tags         Numbers
 // @Accept       multipart/form-data
 // @Produce      json
-// @Success      200  {array}  model.SwaggerDefaultResponse
-// @Param file formData file true "CSV file"
+// @Success      200  {array}  model.SwaggerNumbersImport
+// @Param file formData file true "CSV, XLSX (first column of first sheet) or JSON (array of numbers) file"
+// @Param format formData string false "File format: csv, xlsx, json (by default from file extension)"
 // @Param data formData model.PoolNewRequest true "Pool params"
 // @Router       /numbers/upload [post]
 // @Security ApiKeyAuth
@@ -123,77 +151,106 @@ func UploadNumbers(db *sqlx.DB, c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Sub pool block must be a number", "error": err.Error()})
 		return
 	}
+	if subPoolBlock <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Sub pool block must be greater than 0"})
+		return
+	}
 
 	newPool.VendorID = vendorID         // Сохранение в структуру
 	newPool.TeamID = teamID             // Сохранение в структуру
 	newPool.SubPoolBlock = subPoolBlock // Сохранение в структуру
 
-	//OutLog.Printf("1: %s, 2: %s, 3: %s", PoolName, VendorID, Team)
-
-	file, err := c.FormFile("file")
+	format, values, err := readUploadFile(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "File is required", "error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Unable to read file", "error": err.Error()})
 		return
 	}
 
-	src, err := file.Open()
+	report, err := buildImportReport(db, values)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Unable to open file", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to validate numbers", "error": err.Error()})
 		return
 	}
-	defer src.Close()
 
-	reader := csv.NewReader(src)
-	records, err := reader.ReadAll()
+	entry := model.NumbersImport{
+		PoolName:     newPool.Name,
+		TeamID:       newPool.TeamID,
+		VendorID:     newPool.VendorID,
+		SubPoolBlock: newPool.SubPoolBlock,
+		Format:       format,
+		Rows:         report,
+	}
+	if email := c.GetString("email"); email != "" {
+		entry.Email = &email
+	}
+
+	err = createNumbersImport(db, &entry)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Unable to read CSV", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to save import", "error": err.Error()})
 		return
 	}
 
-	var numbers []model.Numbers
-	for _, record := range records {
-		if len(record) > 0 {
-			numbers = append(numbers, model.Numbers{Value: record[0], Label: false, Used: false}) // Устанавливаем статус по умолчанию
-		}
-	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("File validated, confirm import %d before %s", entry.ID, entry.ExpiresAt.Format(time.RFC3339)), "data": entry})
+}
+
+// Upload report godoc
+// @Summary      Upload report
+// @Description  Get validation report of uploaded numbers file
+// @Tags         Numbers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Import ID"
+// @Success      200  {array}   model.SwaggerNumbersImport
+// @Router       /numbers/upload/{id} [get]
+// @Security ApiKeyAuth
+func UploadReport(db *sqlx.DB, c *gin.Context) {
+	// Получение ID из URL
+	id := c.Param("id")
+	CheckIDAsInt(id, c)
 
-	var subpools [][]model.Numbers
-	for i := 0; i < len(numbers); i += newPool.SubPoolBlock {
-		end := i + newPool.SubPoolBlock
-		if end > len(numbers) {
-			end = len(numbers)
+	var entry model.NumbersImport
+	err := db.Get(&entry, "SELECT * FROM nc.imports WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"status": "failed", "message": "Import not found"})
+			return
 		}
-		subpools = append(subpools, numbers[i:end])
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "message": "Failed to get import", "error": err.Error()})
+		return
 	}
 
-	poolName, poolID, err := createPool(db, newPool.Name, newPool.SubPoolBlock, newPool.VendorID, newPool.TeamID, len(records), len(subpools)) // Создаем новый пул с именем
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": entry})
+}
+
+// Confirm upload godoc
+// @Summary      Confirm upload
+// @Description  Create pool with subpools for accepted numbers of validated upload (or append them to existing pool) in one transaction
+// @Tags         Numbers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Import ID"
+// @Success      200  {array}   model.SwaggerDefaultResponse
+// @Router       /numbers/upload/{id}/confirm [post]
+// @Security ApiKeyAuth
+func ConfirmUpload(db *sqlx.DB, c *gin.Context) {
+	// Получение ID из URL
+	id := c.Param("id")
+	importID, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "ID must be a number", "error": err.Error()})
 		return
 	}
 
-	// Перебираем сабпулы
-	for i, subpool := range subpools {
-		subPoolID, err := createSubPool(db, poolID, i)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
-			return
-		}
-
-		for _, number := range subpool {
-			_, err := createNumber(db, number.Value, number.Label, poolID, subPoolID, newPool.VendorID, newPool.TeamID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
-				return
-			}
-			//OutLog.Printf("Inserted Number ID: %s into SubPool ID: %s\n", numberID, subPoolID) // Логируем вставленные номера
-		}
+	entry, err := confirmNumbersImport(db, importID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "failed", "message": "Failed to confirm import", "error": err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Numbers processed and saved in database",
 		"data": gin.H{
-			"pool_id":       poolID,
-			"pool_name":     poolName,
-			"numbers_count": len(records),
+			"pool_id":       entry.PoolID,
+			"pool_name":     entry.PoolName,
+			"numbers_count": entry.AcceptedCount,
 		}})
 }
@@ -0,0 +1,213 @@
+package function
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"nc/model"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Типы расхождений ресурса Webitel с nc.numbers
+const (
+	driftMissing  = "missing"  // В ресурсе нет номера
+	driftMismatch = "mismatch" // В ресурсе другой номер
+	driftExtra    = "extra"    // Кроме активного в ресурсе есть другие номера
+	driftError    = "error"    // Ресурс не удалось прочитать
+)
+
+// Время последнего оповещения по ресурсу, используется только из StartWebitelVerify()
+var driftAlerts = make(map[string]time.Time)
+
+// Чтение номеров, установленных в ресурсе Webitel
+func getWebitelDisplays(resourceID int) ([]string, error) {
+	url := fmt.Sprintf("%s/call_center/resources/%d/display?page=1&size=100", config.API_Webitel.URL, resourceID)
+
+	body, statusCode, err := WebitelAPIFetch(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource %v displays: %w, status code: %d", resourceID, err, statusCode)
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get resource %v displays, status code: %d", resourceID, statusCode)
+	}
+
+	var response model.WebitelDisplayList
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	displays := make([]string, 0, len(response.Items))
+	for _, item := range response.Items {
+		displays = append(displays, item.Display)
+	}
+	return displays, nil
+}
+
+// Активный номер команды и вендора по nc.numbers
+func getExpectedNumber(db *sqlx.DB, teamID int, vendorID int) (*string, error) {
+	var value string
+	query := `SELECT n.value FROM nc.numbers AS n
+		INNER JOIN nc.pools AS p ON n.pool_id = p.id
+		WHERE p.team_id = $1 AND p.vendor_id = $2 AND p.active = TRUE AND n.active = TRUE
+		ORDER BY n.activated_at DESC NULLS LAST LIMIT 1`
+	err := db.Get(&value, query, teamID, vendorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active number for team %v and vendor %v: %w", teamID, vendorID, err)
+	}
+	return &value, nil
+}
+
+// Сравнение номера в ресурсе с ожидаемым, пустой kind - расхождения нет
+func compareDisplays(expected string, displays []string) string {
+	switch {
+	case len(displays) == 0:
+		return driftMissing
+	case len(displays) > 1:
+		for _, display := range displays {
+			if display == expected {
+				return driftExtra
+			}
+		}
+	case displays[0] == expected:
+		return ""
+	}
+	return driftMismatch
+}
+
+// Сверка ресурсов бэкенда АТС команды, при repush расхождения исправляются повторной отправкой номера
+func checkTeamResources(db *sqlx.DB, teamID int, repush bool) ([]model.ResourceDrift, error) {
+	drifts := []model.ResourceDrift{}
+
+	// Во время незавершённого шага ротации ресурсы законно отличаются от БД
+	var rotating bool
+	err := db.Get(&rotating, "SELECT EXISTS (SELECT 1 FROM nc.rotation_outbox WHERE team_id=$1 AND status IN ($2, $3))", teamID, outboxPending, outboxPushed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rotation in progress: %w", err)
+	}
+	if rotating {
+		return drifts, nil
+	}
+
+	backend, err := getTeamBackend(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := getWebitelResourcesIDS(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resources {
+		expected, err := getExpectedNumber(db, teamID, resource.VendorID)
+		if err != nil {
+			return nil, err
+		}
+		if expected == nil {
+			continue // У вендора нет активного номера, сверять не с чем
+		}
+
+		for _, resourceID := range resource.Resources {
+			drift := model.ResourceDrift{
+				TeamID:     teamID,
+				VendorID:   resource.VendorID,
+				ResourceID: resourceID,
+				Expected:   *expected,
+				Actual:     []string{},
+				CheckedAt:  time.Now(),
+			}
+
+			displays, err := backend.GetCallerIDs(resourceID)
+			if err != nil {
+				drift.Kind = driftError
+				drift.Error = err.Error()
+				drifts = append(drifts, drift)
+				continue
+			}
+			drift.Actual = displays
+
+			drift.Kind = compareDisplays(*expected, displays)
+			if drift.Kind == "" {
+				continue
+			}
+
+			// Номер мог смениться ротацией, пока читали ресурс
+			current, err := getExpectedNumber(db, teamID, resource.VendorID)
+			if err != nil {
+				return nil, err
+			}
+			if current == nil || *current != *expected {
+				continue
+			}
+
+			if repush {
+				err = backend.SetCallerID(resourceID, *expected)
+				if err != nil {
+					drift.Error = err.Error()
+				} else {
+					drift.Repushed = true
+					OutLog.Printf("Resource %v of team %v re-pushed with number %s", resourceID, teamID, *expected)
+				}
+			}
+			drifts = append(drifts, drift)
+		}
+	}
+
+	return drifts, nil
+}
+
+// Сверка ресурсов Webitel всех команд
+func VerifyWebitelResources(db *sqlx.DB) error {
+	var teamIDS []int
+	err := db.Select(&teamIDS, "SELECT id FROM nc.teams WHERE webitel_res_ids IS NOT NULL ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	repeat := time.Duration(config.Verify.RepeatHours) * time.Hour
+	if repeat <= 0 {
+		repeat = time.Hour
+	}
+
+	for _, teamID := range teamIDS {
+		drifts, err := checkTeamResources(db, teamID, config.Verify.Repush)
+		if err != nil {
+			ErrLog.Printf("Failed to verify resources of team %v: %s", teamID, err)
+			continue
+		}
+
+		for _, drift := range drifts {
+			message := fmt.Sprintf("Webitel resource %v of team %v drifted (%s): expected %s, actual %v", drift.ResourceID, drift.TeamID, drift.Kind, drift.Expected, drift.Actual)
+			if drift.Error != "" {
+				message += ", error: " + drift.Error
+			}
+			ErrLog.Println(message)
+
+			// Исправленное расхождение не требует оповещения
+			if drift.Repushed {
+				continue
+			}
+
+			key := fmt.Sprintf("%d:%d:%s", drift.TeamID, drift.ResourceID, drift.Kind)
+			if last, ok := driftAlerts[key]; ok && time.Since(last) < repeat {
+				continue
+			}
+
+			err := sendNotification(config.Verify.WebhookURL, config.Verify.EMail, fmt.Sprintf("MFDC NC: resource %d drift", drift.ResourceID), message, drift)
+			if err != nil {
+				ErrLog.Printf("Failed to send drift alert: %s", err)
+				continue
+			}
+			driftAlerts[key] = time.Now()
+		}
+	}
+
+	return nil
+}
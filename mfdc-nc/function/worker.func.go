@@ -0,0 +1,272 @@
+package function
+
+import (
+	"database/sql"
+	"fmt"
+	"nc/model"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Состояния воркера ротации команды
+const (
+	workerWaiting = "waiting" // Ждёт следующего запуска
+	workerRunning = "running" // Выполняет шаг ротации
+	workerStopped = "stopped" // Расписание команды не активно или экземпляр не лидер
+)
+
+// Первая часть ключа pg_advisory_xact_lock(int, int) для блокировки ротации команды, вторая - ID команды
+const teamRotationLockClass = 0x6e63
+
+// Воркер ротации одной команды: шаги ротации выполняются строго последовательно с паузой periodic_sec
+type teamWorker struct {
+	teamID     int
+	scheduleID int
+	periodic   time.Duration
+	stopCh     chan struct{} // Сигнал остановки, передаётся в DailyRotation()
+	stopOnce   sync.Once
+	done       chan struct{} // Закрывается после выхода воркера
+
+	mu     sync.Mutex
+	status model.TeamWorker
+}
+
+func newTeamWorker(schedule model.Scheduler, restarts int) *teamWorker {
+	return &teamWorker{
+		teamID:     *schedule.TeamID,
+		scheduleID: *schedule.ID,
+		periodic:   time.Duration(*schedule.PeriodicSecond) * time.Second,
+		stopCh:     make(chan struct{}),
+		done:       make(chan struct{}),
+		status: model.TeamWorker{
+			TeamID:      *schedule.TeamID,
+			ScheduleID:  schedule.ID,
+			PeriodicSec: schedule.PeriodicSecond,
+			Restarts:    restarts,
+		},
+	}
+}
+
+// Остановка воркера, повторный вызов безопасен. Начатый шаг ротации доводится до конца
+func (w *teamWorker) stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+// Изменение состояния воркера с сохранением в nc.team_workers
+func (w *teamWorker) update(db *sqlx.DB, change func(status *model.TeamWorker)) {
+	w.mu.Lock()
+	change(&w.status)
+	status := w.status
+	w.mu.Unlock()
+
+	err := saveTeamWorker(db, status)
+	if err != nil {
+		ErrLog.Printf("Failed to save worker status for team %v: %s", w.teamID, err)
+	}
+}
+
+// Цикл воркера, prev - завершение предыдущего воркера команды, чтобы шаги ротации не пересекались
+func (w *teamWorker) run(db *sqlx.DB, prev <-chan struct{}) {
+	defer close(w.done)
+
+	if prev != nil {
+		<-prev
+	}
+
+	instance := leaderInstance()
+	startedAt := time.Now()
+	w.update(db, func(status *model.TeamWorker) {
+		status.State = workerWaiting
+		status.Instance = &instance
+		status.StartedAt = &startedAt
+		status.NextRunAt = &startedAt
+	})
+	OutLog.Printf("Rotation worker for TeamID %v started, schedule %v, periodic: %s", w.teamID, w.scheduleID, w.periodic)
+
+	for {
+		select {
+		case <-w.stopCh:
+			w.update(db, func(status *model.TeamWorker) {
+				status.State = workerStopped
+				status.NextRunAt = nil
+			})
+			OutLog.Printf("Rotation worker for TeamID %v stopped", w.teamID)
+			return
+		default:
+		}
+
+		w.update(db, func(status *model.TeamWorker) {
+			status.State = workerRunning
+		})
+
+		start := time.Now()
+		err := w.rotate(db)
+		duration := time.Since(start)
+		next := time.Now().Add(w.periodic)
+
+		if err != nil {
+			ErrLog.Printf("Error during rotation: %s", err)
+		}
+		OutLog.Printf("TeamID %v rotation lasted %s", w.teamID, duration)
+
+		w.update(db, func(status *model.TeamWorker) {
+			status.State = workerWaiting
+			status.LastRunAt = &start
+			seconds := duration.Seconds()
+			status.LastDuration = &seconds
+			status.LastError = nil
+			if err != nil {
+				message := err.Error()
+				status.LastError = &message
+			}
+			status.NextRunAt = &next
+			status.Runs++
+		})
+
+		select {
+		case <-time.After(w.periodic):
+		case <-w.stopCh:
+		}
+	}
+}
+
+// Шаг ротации, паника не должна останавливать воркер
+func (w *teamWorker) rotate(db *sqlx.DB) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rotation panic for team %v: %v", w.teamID, r)
+			// Воркер продолжает работу, поэтому это не перезапуск
+			w.mu.Lock()
+			w.status.Panics++
+			w.mu.Unlock()
+		}
+	}()
+
+	OutLog.Printf("Run rotation for TeamID %v", w.teamID)
+	return rotateTeam(db, w.teamID, w.stopCh)
+}
+
+// Выполнение fn под блокировкой ротации команды в БД: шаг воркера, ручная ротация, failover и закрепление номера
+// одной команды не выполняются одновременно, в том числе на разных экземплярах
+func withTeamRotationLock(db *sqlx.DB, teamID int, fn func() error) error {
+	// Блокировка держится до конца транзакции, откат её снимает
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin rotation lock transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock($1, $2)", teamRotationLockClass, teamID)
+	if err != nil {
+		return fmt.Errorf("failed to lock rotation for team %v: %w", teamID, err)
+	}
+
+	return fn()
+}
+
+// Шаг ротации команды под блокировкой команды
+func rotateTeam(db *sqlx.DB, teamID int, stopCh chan struct{}) error {
+	return withTeamRotationLock(db, teamID, func() error {
+		return DailyRotation(db, teamID, stopCh)
+	})
+}
+
+// Супервизор воркеров ротации, используется только из горутины StartPeriodicRotation()
+type rotationSupervisor struct {
+	workers  map[int]*teamWorker     // Запущенные воркеры по ID команды
+	done     map[int]<-chan struct{} // Завершение последнего воркера команды
+	restarts map[int]int
+}
+
+func newRotationSupervisor() *rotationSupervisor {
+	return &rotationSupervisor{
+		workers:  make(map[int]*teamWorker),
+		done:     make(map[int]<-chan struct{}),
+		restarts: make(map[int]int),
+	}
+}
+
+// Приведение воркеров к расписаниям: запуск, остановка и перезапуск после изменения расписания
+func (s *rotationSupervisor) sync(db *sqlx.DB, schedules []model.Scheduler) {
+	// На команду работает один воркер, при нескольких активных расписаниях берём первое
+	wanted := make(map[int]model.Scheduler)
+	for _, schedule := range schedules {
+		if !schedule.Running || schedule.ID == nil || schedule.TeamID == nil || schedule.PeriodicSecond == nil || *schedule.PeriodicSecond <= 0 {
+			continue
+		}
+		if _, ok := wanted[*schedule.TeamID]; !ok {
+			wanted[*schedule.TeamID] = schedule
+		}
+	}
+
+	for teamID, worker := range s.workers {
+		schedule, ok := wanted[teamID]
+		if !ok {
+			worker.stop()
+			delete(s.workers, teamID)
+			continue
+		}
+
+		// Расписание изменили: перезапускаем воркер с новыми параметрами
+		if worker.scheduleID != *schedule.ID || worker.periodic != time.Duration(*schedule.PeriodicSecond)*time.Second {
+			OutLog.Printf("Schedule of TeamID %v changed, restarting rotation worker", teamID)
+			worker.stop()
+			delete(s.workers, teamID)
+			s.restarts[teamID]++
+		}
+	}
+
+	for teamID, schedule := range wanted {
+		if _, ok := s.workers[teamID]; ok {
+			continue
+		}
+		worker := newTeamWorker(schedule, s.restarts[teamID])
+		go worker.run(db, s.done[teamID])
+		s.workers[teamID] = worker
+		s.done[teamID] = worker.done
+	}
+}
+
+// Остановка всех воркеров с ожиданием завершения начатых шагов ротации
+func (s *rotationSupervisor) stopAll() {
+	for teamID, worker := range s.workers {
+		worker.stop()
+		delete(s.workers, teamID)
+	}
+	for _, done := range s.done {
+		<-done
+	}
+}
+
+// Сохранение состояния воркера команды
+func saveTeamWorker(db *sqlx.DB, status model.TeamWorker) error {
+	now := time.Now()
+	status.UpdatedAt = &now
+
+	_, err := db.NamedExec(`INSERT INTO nc.team_workers (team_id, state, schedule_id, periodic_sec, instance, started_at, last_run_at, last_duration_sec, last_error, next_run_at, runs, restarts, panics, updated_at)
+		VALUES (:team_id, :state, :schedule_id, :periodic_sec, :instance, :started_at, :last_run_at, :last_duration_sec, :last_error, :next_run_at, :runs, :restarts, :panics, :updated_at)
+		ON CONFLICT (team_id) DO UPDATE SET state = EXCLUDED.state, schedule_id = EXCLUDED.schedule_id, periodic_sec = EXCLUDED.periodic_sec,
+			instance = EXCLUDED.instance, started_at = EXCLUDED.started_at, last_run_at = EXCLUDED.last_run_at, last_duration_sec = EXCLUDED.last_duration_sec,
+			last_error = EXCLUDED.last_error, next_run_at = EXCLUDED.next_run_at, runs = EXCLUDED.runs, restarts = EXCLUDED.restarts, panics = EXCLUDED.panics, updated_at = EXCLUDED.updated_at`, status)
+	if err != nil {
+		return fmt.Errorf("failed to save worker status: %w", err)
+	}
+	return nil
+}
+
+// Состояние воркера команды, воркер работает на лидере и может быть на другом экземпляре
+func getTeamWorker(db *sqlx.DB, teamID int) (model.TeamWorker, error) {
+	var status model.TeamWorker
+	err := db.Get(&status, "SELECT * FROM nc.team_workers WHERE team_id = $1", teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.TeamWorker{TeamID: teamID, State: workerStopped}, nil
+		}
+		return status, fmt.Errorf("failed to get worker status for team %v: %w", teamID, err)
+	}
+	return status, nil
+}
@@ -61,6 +61,14 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.UploadNumbers(db.(*sqlx.DB), c)
 		})
+		numbers.GET("/upload/:id", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.UploadReport(db.(*sqlx.DB), c)
+		})
+		numbers.POST("/upload/:id/confirm", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.ConfirmUpload(db.(*sqlx.DB), c)
+		})
 		numbers.GET("/info/:number", function.CheckUserAuth(), func(c *gin.Context) {
 			db, _ := function.CheckDB(c)
 			function.NumberInfo(db.(*sqlx.DB), c)
@@ -69,6 +77,10 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.NumberTeamInfo(db.(*sqlx.DB), c)
 		})
+		numbers.GET("/callerid/:number", func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.CallerIDLookup(db.(*sqlx.DB), c)
+		})
 		numbers.GET("/list/:pool_id", function.CheckUserAuth(), func(c *gin.Context) {
 			db, _ := function.CheckDB(c)
 			function.NumbersInPool(db.(*sqlx.DB), c)
@@ -77,6 +89,14 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.NumberExclusion(db.(*sqlx.DB), c)
 		})
+		numbers.PATCH("/state", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.NumberState(db.(*sqlx.DB), c)
+		})
+		numbers.GET("/health/:number", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.NumberHealthHistory(db.(*sqlx.DB), c)
+		})
 	}
 
 	pools := router.Group("/pools")
@@ -93,6 +113,14 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.DeactivatePoolManual(db.(*sqlx.DB), c)
 		})
+		pools.POST("/:id/append", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.AppendNumbers(db.(*sqlx.DB), c)
+		})
+		pools.PATCH("/:id/strategy", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.PoolStrategy(db.(*sqlx.DB), c)
+		})
 		pools.DELETE("/delete/:id", function.CheckUserAuth(), func(c *gin.Context) {
 			db, _ := function.CheckDB(c)
 			function.PoolDelete(db.(*sqlx.DB), c)
@@ -101,6 +129,18 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.RedistributionPools(db.(*sqlx.DB), c)
 		})
+		pools.GET("/numsmove/batches", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.NumsMoveBatches(db.(*sqlx.DB), c)
+		})
+		pools.GET("/numsmove/batches/:id", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.NumsMoveBatch(db.(*sqlx.DB), c)
+		})
+		pools.POST("/numsmove/batches/:id/rollback", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.NumsMoveRollback(db.(*sqlx.DB), c)
+		})
 	}
 
 	schedule := router.Group("/schedule")
@@ -121,6 +161,10 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.ScheduleDelete(db.(*sqlx.DB), c)
 		})
+		schedule.POST("/simulate", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.SimulateSchedule(db.(*sqlx.DB), c)
+		})
 	}
 
 	teams := router.Group("/teams")
@@ -133,6 +177,38 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.TeamNumberRotate(db.(*sqlx.DB), c)
 		})
+		teams.GET("/:id/drift", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamDrift(db.(*sqlx.DB), c)
+		})
+		teams.POST("/:id/drift/repush", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamDriftRepush(db.(*sqlx.DB), c)
+		})
+		teams.GET("/:id/worker", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamWorker(db.(*sqlx.DB), c)
+		})
+		teams.GET("/:id/control", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamControl(db.(*sqlx.DB), c)
+		})
+		teams.POST("/:id/pause", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamPause(db.(*sqlx.DB), c)
+		})
+		teams.POST("/:id/resume", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamResume(db.(*sqlx.DB), c)
+		})
+		teams.POST("/:id/pin", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamPin(db.(*sqlx.DB), c)
+		})
+		teams.POST("/:id/unpin", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.TeamUnpin(db.(*sqlx.DB), c)
+		})
 		teams.GET("/activenums", function.CheckUserAuth(), func(c *gin.Context) {
 			db, _ := function.CheckDB(c)
 			function.ActiveTeamsNumbers(db.(*sqlx.DB), c)
@@ -169,6 +245,14 @@ func main() {
 			db, _ := function.CheckDB(c)
 			function.VendorEdit(db.(*sqlx.DB), c)
 		})
+		vendors.POST("/failover", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.VendorFailover(db.(*sqlx.DB), c)
+		})
+		vendors.GET("/failover/list", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.VendorFailoverList(db.(*sqlx.DB), c)
+		})
 		vendors.DELETE("/delete/:id", function.CheckUserAuth(), func(c *gin.Context) {
 			db, _ := function.CheckDB(c)
 			function.VendorDelete(db.(*sqlx.DB), c)
@@ -190,8 +274,29 @@ func main() {
 		function.GetLogs(db.(*sqlx.DB), c)
 	})
 
+	reports := router.Group("/reports")
+	{
+		reports.GET("/exposure", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.ExposureReport(db.(*sqlx.DB), c)
+		})
+		reports.GET("/activations", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.ActivationsReport(db.(*sqlx.DB), c)
+		})
+		reports.GET("/unused", function.CheckUserAuth(), func(c *gin.Context) {
+			db, _ := function.CheckDB(c)
+			function.UnusedNumbersReport(db.(*sqlx.DB), c)
+		})
+	}
+
 	router.GET("/config/reload", function.CheckUserAuth(), function.UpdateConfig)
 
+	router.GET("/leader/status", function.CheckUserAuth(), func(c *gin.Context) {
+		db, _ := function.CheckDB(c)
+		function.LeaderStatus(db.(*sqlx.DB), c)
+	})
+
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Обработчик для несуществующих маршрутов
@@ -231,26 +336,47 @@ func main() {
 		function.ErrLog.Printf("Error creating structure tables: %v", err)
 	}
 
+	// Код региона для номеров, загруженных до настройки geo
+	geoCount, err := function.UpdateNumbersGeo(db)
+	if err != nil {
+		function.ErrLog.Printf("Error updating numbers area code: %v", err)
+	} else if geoCount > 0 {
+		function.OutLog.Printf("Area code set for %d numbers", geoCount)
+	}
+
 	// Запускаем мониторинг в отдельной горутине
 	go function.MonitorConfigReload(ctx)
 
-	// Синк с сервисом VC
-	go function.StartVCSync(ctx, db)
+	// Фоновые задачи выполняет только лидер, HTTP API обслуживают все экземпляры
+	jobs := []function.LeaderJob{
+		function.StartVCSync, // Синк с сервисом VC
+	}
 
-	if config.Rotate.EnableRotation {
-		// Запуск ротации расписаний
-		go function.StartRotationSchedule(ctx, db)
+	if config.Health.Enabled {
+		// Пересчёт оценок здоровья номеров
+		jobs = append(jobs, function.StartHealthScoring)
+	}
+
+	if config.Forecast.Enabled {
+		// Прогноз исчерпания пулов и оповещения
+		jobs = append(jobs, function.StartPoolForecast)
+	}
 
-		// Запуск ротации номеров пул > сабпул > номер
-		go function.StartPeriodicRotation(ctx, db)
+	if config.Verify.Enabled {
+		// Сверка ресурсов Webitel с активными номерами
+		jobs = append(jobs, function.StartWebitelVerify)
+	}
 
-		// Ежедневная активация сабпулов
-		go function.SubPoolActivate(ctx, db)
+	if config.Rotate.EnableRotation {
+		// Довод прерванных шагов, ротация расписаний и номеров, ежедневная активация сабпулов
+		jobs = append(jobs, function.StartRotation)
 
 		//Тестовая функция активации сабпула
-		//go function.TestStartSubPoolActivateTask(ctx, db)
+		//jobs = append(jobs, function.TestStartSubPoolActivateTask)
 	}
 
+	go function.RunLeaderJobs(ctx, db, jobs)
+
 	// Ожидание сигнала завершения
 	<-quit
 
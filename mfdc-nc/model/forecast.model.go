@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Прогноз исчерпания пула по темпу ротации из nc.logs
+type PoolForecast struct {
+	PoolID          int        `db:"pool_id" json:"-"`
+	UnusedNumbers   int        `db:"unused" json:"unused_numbers"`           // Номера, которые ещё ни разу не выдавались
+	Activations     int        `db:"activations" json:"activations"`         // Активаций номеров за окно прогноза
+	RotationsPerDay float64    `db:"-" json:"rotations_per_day"`             // Средний темп ротации в сутки
+	DaysLeft        *float64   `db:"-" json:"days_left,omitempty"`           // Сколько дней осталось до повторного использования номеров, пусто если пул не ротируется
+	Reusing         bool       `db:"reusing" json:"reusing"`                 // Все номера пула уже использованы, идёт повторный цикл
+	ReusingAt       *time.Time `db:"reusing_at" json:"reusing_at,omitempty"` // Когда пул начал повторный цикл
+}
+
+// Отправленное оповещение по пулу
+type PoolAlert struct {
+	ID       int       `db:"id" json:"id"`
+	PoolID   int       `db:"pool_id" json:"pool_id"`
+	Kind     string    `db:"kind" json:"kind"` // low_days, reuse
+	DaysLeft *float64  `db:"days_left" json:"days_left,omitempty"`
+	Message  string    `db:"message" json:"message"`
+	SentAt   time.Time `db:"sent_at" json:"sent_at"`
+}
+
+// Тело вебхука оповещения по пулу
+type PoolAlertWebhook struct {
+	Event    string        `json:"event"`
+	PoolID   int           `json:"pool_id"`
+	PoolName string        `json:"pool_name"`
+	TeamID   *int          `json:"team_id,omitempty"`
+	Message  string        `json:"message"`
+	Forecast *PoolForecast `json:"forecast"`
+	At       time.Time     `json:"at"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Строка отчёта о проверке файла с номерами
+type ImportRow struct {
+	Row    int    `json:"row"`              // Номер строки в файле (с 1)
+	Value  string `json:"value"`            // Значение из файла
+	Number string `json:"number,omitempty"` // Номер после нормализации
+	Status string `json:"status"`           // accepted, rejected
+	Reason string `json:"reason,omitempty"`
+}
+
+// Строки отчёта, хранятся в jsonb
+type ImportRows []ImportRow
+
+// Scan для работы с базой данных
+func (r *ImportRows) Scan(value interface{}) error {
+	return scanJSONB(value, r)
+}
+
+// Value для работы с базой данных
+func (r ImportRows) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+// Загрузка номеров, ожидающая подтверждения
+type NumbersImport struct {
+	ID            int        `db:"id" json:"import_id"`
+	PoolName      string     `db:"pool_name" json:"pool_name"`
+	TeamID        int        `db:"team_id" json:"team_id"`
+	VendorID      int        `db:"vendor_id" json:"vendor_id"`
+	SubPoolBlock  int        `db:"subpool_block" json:"subpool_block"`
+	Format        string     `db:"format" json:"format"`
+	Mode          string     `db:"mode" json:"mode"`     // create - новый пул, append - дозагрузка в пул pool_id
+	Status        string     `db:"status" json:"status"` // pending, confirmed
+	Total         int        `db:"total" json:"total"`
+	AcceptedCount int        `db:"accepted_count" json:"accepted"`
+	RejectedCount int        `db:"rejected_count" json:"rejected"`
+	Rows          ImportRows `db:"rows" json:"rows"`
+	Email         *string    `db:"email" json:"email,omitempty"`
+	PoolID        *int       `db:"pool_id" json:"pool_id,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt     time.Time  `db:"expires_at" json:"expires_at"`
+	ConfirmedAt   *time.Time `db:"confirmed_at" json:"confirmed_at,omitempty"`
+}
+
+type SwaggerNumbersImport struct {
+	Status string        `json:"status"`
+	Data   NumbersImport `json:"data"`
+}
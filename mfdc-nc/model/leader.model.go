@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Аренда лидерства фоновых задач в nc.leader_lease
+type LeaderLease struct {
+	Name       string    `db:"name" json:"name"`
+	Instance   string    `db:"instance" json:"instance"`
+	AcquiredAt time.Time `db:"acquired_at" json:"acquired_at"`
+	RenewedAt  time.Time `db:"renewed_at" json:"renewed_at"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// Статус лидерства для /leader/status
+type LeaderStatus struct {
+	Enabled  bool         `json:"enabled"`          // Выборы включены, иначе фоновые задачи запускает каждый экземпляр
+	Instance string       `json:"instance"`         // Текущий экземпляр nc-api
+	IsLeader bool         `json:"is_leader"`        // Текущий экземпляр выполняет фоновые задачи
+	Leader   *LeaderLease `json:"leader,omitempty"` // Последняя аренда лидера
+	Expired  bool         `json:"expired"`          // Аренда не продлевалась дольше lease_second, лидер мог упасть
+}
+
+type SwaggerLeaderStatus struct {
+	Status string       `json:"status"`
+	Data   LeaderStatus `json:"data"`
+}
@@ -32,6 +32,9 @@ type LogList struct {
 	Comment  string     `db:"comment" json:"comment,omitempty"`
 	StartAt  *time.Time `db:"start_at" json:"start_at,omitempty"`
 	EndAt    *time.Time `db:"end_at" json:"end_at,omitempty"`
+	Event    *string    `db:"event" json:"event,omitempty"` // activation, state, pause, resume, pin, unpin
+	Reason   *string    `db:"reason" json:"reason,omitempty"`
+	Email    *string    `db:"email" json:"email,omitempty"` // Пользователь, выполнивший действие
 }
 
 type LogJsonResponse struct {
@@ -33,6 +33,66 @@ type Config struct {
 		SubpoolActivateTimeMinut int    `json:"subpool_activate_time_minut"`
 		TimeZone                 string `json:"timezone"`
 	} `json:"rotate"`
+	Health struct {
+		Enabled        bool    `json:"enabled"`
+		Threshold      float64 `json:"threshold"`      // Порог оценки номера (0-100), ниже которого номер считается плохим
+		Mode           string  `json:"mode"`           // skip - исключать плохие номера из ротации, deprioritize - выдавать их в последнюю очередь
+		WindowHours    int     `json:"window_hours"`   // Окно статистики звонков из cdr.calls
+		MinCalls       int     `json:"min_calls"`      // Минимум звонков для расчёта оценки
+		ShortCallSec   int     `json:"short_call_sec"` // Разговор короче этого значения считается коротким
+		IntervalMinute int     `json:"interval_minut"` // Период пересчёта оценок
+	} `json:"health"`
+	Upload struct {
+		CountryCode    string `json:"country_code"`    // Код страны для номеров в национальном формате, например 7
+		TrunkPrefix    string `json:"trunk_prefix"`    // Префикс выхода на междугороднюю связь, заменяется на код страны, например 8
+		NationalLength int    `json:"national_length"` // Длина национального номера без кода страны, например 10
+		PlusPrefix     bool   `json:"plus_prefix"`     // Сохранять номера с + в начале
+		TTLMinute      int    `json:"ttl_minut"`       // Сколько минут загрузка ждёт подтверждения
+	} `json:"upload"`
+	Forecast struct {
+		Enabled        bool     `json:"enabled"`
+		WindowHours    int      `json:"window_hours"`   // Окно nc.logs для расчёта темпа ротации
+		ThresholdDays  float64  `json:"threshold_days"` // Оповещать, если до исчерпания пула осталось меньше дней
+		RepeatHours    int      `json:"repeat_hours"`   // Не повторять оповещение одного типа по пулу чаще
+		IntervalMinute int      `json:"interval_minut"` // Период проверки прогноза
+		WebhookURL     string   `json:"webhook_url"`    // Вебхук для оповещений (POST JSON)
+		EMail          []string `json:"email"`          // Получатели оповещений, отправка через MAIL
+	} `json:"forecast"`
+	Verify struct {
+		Enabled        bool     `json:"enabled"`
+		IntervalMinute int      `json:"interval_minut"` // Период сверки ресурсов Webitel
+		Repush         bool     `json:"repush"`         // При расхождении повторно отправлять активный номер в ресурс
+		RepeatHours    int      `json:"repeat_hours"`   // Не повторять оповещение по одному ресурсу чаще
+		WebhookURL     string   `json:"webhook_url"`
+		EMail          []string `json:"email"`
+	} `json:"verify"`
+	Lifecycle struct {
+		CooldownActivations    int `json:"cooldown_activations"`     // Номер уходит на охлаждение, если за cooldown_window_hours был активирован столько раз, 0 - не охлаждать
+		CooldownWindowHours    int `json:"cooldown_window_hours"`    // Окно nc.logs для подсчёта активаций
+		CooldownDays           int `json:"cooldown_days"`            // Длительность охлаждения
+		QuarantineDays         int `json:"quarantine_days"`          // Длительность карантина, если у вендора не задан quarantine_days
+		RetireAfterQuarantines int `json:"retire_after_quarantines"` // На этом по счёту карантине номер выводится из оборота, 0 - никогда
+		IntervalMinute         int `json:"interval_minut"`           // Период возврата номеров из охлаждения и карантина
+	} `json:"lifecycle"`
+	Leader struct {
+		Enabled     bool   `json:"enabled"`
+		Instance    string `json:"instance"`     // Имя экземпляра, по умолчанию hostname:pid
+		LockKey     int64  `json:"lock_key"`     // Ключ pg_advisory_lock, общий для всех экземпляров
+		LeaseSecond int    `json:"lease_second"` // Период продления аренды и попыток захватить лидерство
+	} `json:"leader"`
+	Dialer struct {
+		FileDir string `json:"file_dir"` // Каталог файлов с номерами для команд с backend = file
+	} `json:"dialer"`
+	Geo struct {
+		AreaCodeLength int               `json:"area_code_length"` // Длина кода региона после кода страны upload.country_code, по умолчанию 3
+		Regions        map[string]string `json:"regions"`          // Регион по префиксу номера с кодом страны, например "7495": "Москва"
+	} `json:"geo"`
+	MAIL struct {
+		ServerAddr   string `json:"smtp_server_addr"`
+		ServerPort   string `json:"smtp_server_port"`
+		AuthUser     string `json:"auth_user"`
+		AuthPassword string `json:"auth_password"`
+	} `json:"mail"`
 }
 
 type Reload struct {
@@ -37,12 +37,22 @@ type NumberInfo struct {
 	Enabled     *bool        `db:"enabled" json:"enabled,omitempty"`
 	ActivatedAt *time.Time   `db:"activated_at" json:"activated_at,omitempty"`
 	MovedAt     *time.Time   `db:"moved_at" json:"moved_at,omitempty"`
+	HealthScore *float64     `db:"health_score" json:"health_score,omitempty"`
+	State       *string      `db:"state" json:"state,omitempty"` // active, cooldown, quarantined, retired
+	StateUntil  *time.Time   `db:"state_until" json:"state_until,omitempty"`
+	StateReason *string      `db:"state_reason" json:"state_reason,omitempty"`
+	AreaCode    *string      `db:"area_code" json:"area_code,omitempty"`
+	Region      *string      `db:"region" json:"region,omitempty"`
 	Logs        []NumberLogs `json:"logs,omitempty"`
 }
 
 type NumberLogs struct {
-	StartAt *time.Time `db:"start_at" json:"start_at,omitempty"`
-	EndAt   *time.Time `db:"end_at" json:"end_at,omitempty"`
+	StartAt   *time.Time `db:"start_at" json:"start_at,omitempty"`
+	EndAt     *time.Time `db:"end_at" json:"end_at,omitempty"`
+	Event     *string    `db:"event" json:"event,omitempty"` // activation - номер был в ротации, state - смена состояния
+	StateFrom *string    `db:"state_from" json:"state_from,omitempty"`
+	StateTo   *string    `db:"state_to" json:"state_to,omitempty"`
+	Reason    *string    `db:"reason" json:"reason,omitempty"`
 }
 
 type NumberTeamInfo struct {
@@ -50,6 +60,23 @@ type NumberTeamInfo struct {
 	Team   string `db:"team" json:"routing"`
 }
 
+// Номер для звонка с учётом региона вызываемого
+type CallerIDLookup struct {
+	Callee         string  `json:"callee"`
+	CalleeAreaCode *string `json:"callee_area_code,omitempty"`
+	CalleeRegion   *string `json:"callee_region,omitempty"`
+	TeamID         int     `json:"team_id"`
+	Number         string  `json:"number"`
+	AreaCode       *string `json:"area_code,omitempty"`
+	Region         *string `json:"region,omitempty"`
+	Match          string  `json:"match"` // area - совпал код региона, default - текущий номер команды
+}
+
+type SwaggerCallerIDLookup struct {
+	Status string         `json:"status"`
+	Data   CallerIDLookup `json:"data"`
+}
+
 type NumbersInPool struct {
 	ID        *int    `db:"id" json:"id,omitempty"`
 	SubPoolID *int    `db:"subpool_id" json:"subpool_id,omitempty"`
@@ -73,3 +100,42 @@ type NumbersExclusionData struct {
 	NumberID *int  `json:"number_id,omitempty"`
 	Enabled  *bool `json:"enabled,omitempty"`
 }
+
+type NumberStateRequest struct {
+	NumberIDS []int  `json:"number_ids"`
+	State     string `json:"state" example:"quarantined"` // active, cooldown, quarantined, retired
+	Reason    string `json:"reason" example:"spam complaints"`
+	Days      *int   `json:"days,omitempty"` // Длительность охлаждения или карантина вместо настроек
+}
+
+type NumberStateResult struct {
+	NumberID int        `json:"number_id"`
+	State    string     `json:"state,omitempty"`
+	Until    *time.Time `json:"until,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+type SwaggerNumberStateResult struct {
+	Status string              `json:"status"`
+	Data   []NumberStateResult `json:"data"`
+}
+
+type NumberHealth struct {
+	NumberID       int       `db:"number_id" json:"number_id"`
+	Calls          int       `db:"calls" json:"calls"`
+	Answered       int       `db:"answered" json:"answered"`
+	ShortCalls     int       `db:"short_calls" json:"short_calls"`
+	Rejected       int       `db:"rejected" json:"rejected"` // Отбои с SIP 403/603
+	AnswerRate     float64   `db:"answer_rate" json:"answer_rate"`
+	ShortCallRatio float64   `db:"short_call_ratio" json:"short_call_ratio"`
+	RejectRatio    float64   `db:"reject_ratio" json:"reject_ratio"`
+	Score          *float64  `db:"score" json:"score"`
+	CalcAt         time.Time `db:"calc_at" json:"calc_at"`
+}
+
+type NumberHealthResponse struct {
+	Status      string         `json:"status"`
+	Number      string         `json:"number"`
+	HealthScore *float64       `json:"health_score"`
+	Data        []NumberHealth `json:"data"`
+}
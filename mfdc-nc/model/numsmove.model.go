@@ -0,0 +1,72 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// ID сабпулов, созданных при перераспределении, хранятся в jsonb
+type SubPoolIDs []int
+
+// Scan для работы с базой данных
+func (s *SubPoolIDs) Scan(value interface{}) error {
+	return scanJSONB(value, s)
+}
+
+// Value для работы с базой данных
+func (s SubPoolIDs) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+// Перераспределение номеров между пулами через /pools/numsmove
+type NumsMoveBatch struct {
+	ID              int        `db:"id" json:"id"`
+	SrcPoolID       int        `db:"src_pool_id" json:"src_pool_id"`
+	DstPoolID       int        `db:"dst_pool_id" json:"dst_pool_id"`
+	DstTeamID       *int       `db:"dst_team_id" json:"dst_team_id,omitempty"`
+	VendorID        *int       `db:"vendor_id" json:"vendor_id,omitempty"`
+	NumbersCount    int        `db:"numbers_count" json:"numbers_count"`
+	SrcSubPoolBlock *int       `db:"src_subpool_block" json:"src_subpool_block,omitempty"` // Параметры пулов до перераспределения
+	SrcSubPoolCount *int       `db:"src_subpool_count" json:"src_subpool_count,omitempty"`
+	DstSubPoolBlock *int       `db:"dst_subpool_block" json:"dst_subpool_block,omitempty"`
+	DstSubPoolCount *int       `db:"dst_subpool_count" json:"dst_subpool_count,omitempty"`
+	CreatedSubPools SubPoolIDs `db:"created_subpools" json:"created_subpools"` // Сабпулы, созданные в пуле назначения
+	Email           *string    `db:"email" json:"email,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	RolledBackAt    *time.Time `db:"rolled_back_at" json:"rolled_back_at,omitempty"`
+	RolledBackBy    *string    `db:"rolled_back_by" json:"rolled_back_by,omitempty"`
+}
+
+// Перемещённый номер и его положение до перераспределения
+type NumsMoveNumber struct {
+	BatchID        int        `db:"batch_id" json:"-"`
+	NumberID       int        `db:"number_id" json:"number_id"`
+	Value          *string    `db:"value" json:"value,omitempty"`
+	SrcPoolID      *int       `db:"src_pool_id" json:"src_pool_id,omitempty"`
+	SrcSubPoolID   *int       `db:"src_subpool_id" json:"src_subpool_id,omitempty"`
+	SrcTeamID      *int       `db:"src_team_id" json:"src_team_id,omitempty"`
+	SrcVendorID    *int       `db:"src_vendor_id" json:"src_vendor_id,omitempty"`
+	SrcLabel       *bool      `db:"src_label" json:"src_label,omitempty"`
+	SrcActivatedAt *time.Time `db:"src_activated_at" json:"src_activated_at,omitempty"`
+	SrcMovedAt     *time.Time `db:"src_moved_at" json:"src_moved_at,omitempty"`
+	DstSubPoolID   *int       `db:"dst_subpool_id" json:"dst_subpool_id,omitempty"`
+}
+
+type NumsMoveBatchInfo struct {
+	NumsMoveBatch
+	Numbers []NumsMoveNumber `json:"numbers"`
+}
+
+type SwaggerNumsMoveBatches struct {
+	Status string          `json:"status"`
+	Data   []NumsMoveBatch `json:"data"`
+}
+
+type SwaggerNumsMoveBatch struct {
+	Status string            `json:"status"`
+	Data   NumsMoveBatchInfo `json:"data"`
+}
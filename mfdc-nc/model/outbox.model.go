@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+// Запись outbox для отправки номера в Webitel в рамках шага ротации
+type RotationOutbox struct {
+	ID             int              `db:"id"`
+	TeamID         int              `db:"team_id"`
+	PoolID         int              `db:"pool_id"`
+	SubPoolID      int              `db:"subpool_id"`
+	VendorID       int              `db:"vendor_id"`
+	NumberID       int              `db:"number_id"`
+	Number         string           `db:"number"`
+	PreviousNumber *string          `db:"prev_number"`
+	ResourceIDS    pgtype.Int4Array `db:"resource_ids"`
+	Status         string           `db:"status"`
+	Error          *string          `db:"error"`
+	CreatedAt      time.Time        `db:"created_at"`
+	UpdatedAt      *time.Time       `db:"updated_at"`
+}
@@ -21,19 +21,25 @@ type PoolRedistribution struct {
 }
 
 type Pool struct {
-	ID            *int       `db:"id" json:"id,omitempty"`
-	Name          *string    `db:"name" json:"name,omitempty"`
-	Active        *bool      `db:"active" json:"active,omitempty"`
-	Rotation      *bool      `db:"rotation" json:"rotation,omitempty"`
-	Finish        *bool      `db:"finish" json:"finish,omitempty"`
-	SubActivate   *bool      `db:"sub_activate" json:"-"`
-	CreatedAt     *time.Time `db:"created_at" json:"created_at,omitempty"`
-	FinishAt      *time.Time `db:"finish_at" json:"finish_at,omitempty"`
-	SubPoolBlock  *int       `db:"subpool_block" json:"block,omitempty"`
-	VendorID      *int       `db:"vendor_id" json:"vendor_id"`
-	TeamID        *int       `db:"team_id" json:"team_id"`
-	NumbersCount  *int       `db:"num_count" json:"num_count,omitempty"`
-	SubPoolsCount *int       `db:"subpool_count" json:"subpool_count,omitempty"`
+	ID            *int          `db:"id" json:"id,omitempty"`
+	Name          *string       `db:"name" json:"name,omitempty"`
+	Active        *bool         `db:"active" json:"active,omitempty"`
+	Rotation      *bool         `db:"rotation" json:"rotation,omitempty"`
+	Finish        *bool         `db:"finish" json:"finish,omitempty"`
+	SubActivate   *bool         `db:"sub_activate" json:"-"`
+	CreatedAt     *time.Time    `db:"created_at" json:"created_at,omitempty"`
+	FinishAt      *time.Time    `db:"finish_at" json:"finish_at,omitempty"`
+	SubPoolBlock  *int          `db:"subpool_block" json:"block,omitempty"`
+	VendorID      *int          `db:"vendor_id" json:"vendor_id"`
+	TeamID        *int          `db:"team_id" json:"team_id"`
+	NumbersCount  *int          `db:"num_count" json:"num_count,omitempty"`
+	SubPoolsCount *int          `db:"subpool_count" json:"subpool_count,omitempty"`
+	Strategy      *string       `db:"strategy" json:"strategy,omitempty"` // Стратегия ротации: sequential, random, lru, weighted
+	Forecast      *PoolForecast `db:"-" json:"forecast,omitempty"`
+}
+
+type PoolStrategyRequest struct {
+	Strategy string `json:"strategy"`
 }
 
 type SrcPoolInfo struct {
@@ -0,0 +1,49 @@
+package model
+
+// Экспозиция номеров по nc.logs: сколько времени номера группы были активны в ресурсах
+type ExposureReport struct {
+	ID           *int    `db:"id" json:"id,omitempty"` // ID номера, команды, вендора или пула
+	Name         string  `db:"name" json:"name"`       // Номер, имя команды, вендора или пула
+	Activations  int     `db:"activations" json:"activations"`
+	Numbers      int     `db:"numbers" json:"numbers"`           // Разных номеров, которые были активны
+	ExposureSec  float64 `db:"exposure_sec" json:"exposure_sec"` // Суммарное время активности в периоде
+	AvgActiveSec float64 `db:"avg_active_sec" json:"avg_active_sec"`
+}
+
+// Активации номеров за день
+type ActivationsDay struct {
+	Day          string  `db:"day" json:"day"` // 2006-01-02 в часовом поясе config.Rotate.TimeZone
+	TeamID       *int    `db:"team_id" json:"team_id,omitempty"`
+	Team         *string `db:"team" json:"team,omitempty"`
+	Activations  int     `db:"activations" json:"activations"`
+	Numbers      int     `db:"numbers" json:"numbers"`
+	AvgActiveSec float64 `db:"avg_active_sec" json:"avg_active_sec"`
+}
+
+// Номер, который ни разу не был активирован
+type UnusedNumber struct {
+	ID     int     `db:"id" json:"id"`
+	Number string  `db:"number" json:"number"`
+	State  string  `db:"state" json:"state"`
+	Pool   *string `db:"pool" json:"pool,omitempty"`
+	Team   *string `db:"team" json:"team,omitempty"`
+	Vendor *string `db:"vendor" json:"vendor,omitempty"`
+}
+
+type SwaggerExposureReport struct {
+	Status string           `json:"status"`
+	Count  int              `json:"count"`
+	Data   []ExposureReport `json:"data"`
+}
+
+type SwaggerActivationsReport struct {
+	Status string           `json:"status"`
+	Count  int              `json:"count"`
+	Data   []ActivationsDay `json:"data"`
+}
+
+type SwaggerUnusedReport struct {
+	Status string         `json:"status"`
+	Count  int            `json:"count"`
+	Data   []UnusedNumber `json:"data"`
+}
@@ -58,13 +58,67 @@ func (ct TimeTZ) Value() (driver.Value, error) {
 	return ct.Time.Format("15:04:05-07"), nil
 }
 
+// Недельное окно расписания, время задаётся в часовом поясе config.Rotate.TimeZone
+type ScheduleWindow struct {
+	Days  []int  `json:"days,omitempty"` // Дни недели как в cron: 0 - воскресенье ... 6 - суббота (7 - тоже воскресенье), пусто - каждый день
+	Start string `json:"start"`          // 15:04, если start > stop, окно переходит через полночь
+	Stop  string `json:"stop"`
+}
+
+// Список окон расписания, хранится в jsonb
+type ScheduleWindows []ScheduleWindow
+
+// Scan для работы с базой данных
+func (w *ScheduleWindows) Scan(value interface{}) error {
+	return scanJSONB(value, w)
+}
+
+// Value для работы с базой данных
+func (w ScheduleWindows) Value() (driver.Value, error) {
+	if len(w) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(w)
+}
+
+// Даты исключений расписания в формате 2006-01-02, хранятся в jsonb
+type ScheduleDates []string
+
+// Scan для работы с базой данных
+func (d *ScheduleDates) Scan(value interface{}) error {
+	return scanJSONB(value, d)
+}
+
+// Value для работы с базой данных
+func (d ScheduleDates) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(d)
+}
+
+func scanJSONB(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	}
+	return fmt.Errorf("cannot scan type %T into %T", value, dest)
+}
+
 type Scheduler struct {
-	ID             *int    `db:"id" json:"id,omitempty"`
-	Name           *string `db:"name" json:"name,omitempty"`
-	StartTime      *TimeTZ `db:"start_time" json:"start_time,omitempty"`
-	StopTime       *TimeTZ `db:"stop_time" json:"stop_time,omitempty"`
-	PeriodicSecond *int    `db:"periodic_sec" json:"periodic_sec,omitempty"`
-	TeamID         *int    `db:"team_id" json:"team_id,omitempty"`
-	TeamName       *string `db:"team_name" json:"team_name,omitempty"`
-	Running        bool    `db:"running" json:"-"`
+	ID             *int            `db:"id" json:"id,omitempty"`
+	Name           *string         `db:"name" json:"name,omitempty"`
+	StartTime      *TimeTZ         `db:"start_time" json:"start_time,omitempty"`
+	StopTime       *TimeTZ         `db:"stop_time" json:"stop_time,omitempty"`
+	Cron           *string         `db:"cron" json:"cron,omitempty"`             // Cron-выражение (минута час день месяц день_недели), активны минуты, которые ему соответствуют
+	Windows        ScheduleWindows `db:"windows" json:"windows,omitempty"`       // Недельные окна
+	Exceptions     ScheduleDates   `db:"exceptions" json:"exceptions,omitempty"` // Даты, в которые ротация не запускается
+	PeriodicSecond *int            `db:"periodic_sec" json:"periodic_sec,omitempty"`
+	TeamID         *int            `db:"team_id" json:"team_id,omitempty"`
+	TeamName       *string         `db:"team_name" json:"team_name,omitempty"`
+	Running        bool            `db:"running" json:"-"`
 }
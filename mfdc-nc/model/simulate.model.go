@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+type SimulateRequest struct {
+	Hours    *int       `json:"hours,omitempty"`
+	TeamID   *int       `json:"team_id,omitempty"`
+	Schedule *Scheduler `json:"schedule,omitempty"` // Черновик расписания, который ещё не сохранён в nc.scheduler
+}
+
+type SimulateResponse struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Teams []SimulateTeam `json:"teams"`
+}
+
+type SimulateTeam struct {
+	TeamID      int                `json:"team_id"`
+	TeamName    string             `json:"team_name,omitempty"`
+	Schedule    string             `json:"schedule,omitempty"`
+	PeriodicSec int                `json:"periodic_sec"`
+	Rotations   int                `json:"rotations"`
+	Timeline    []SimulateEvent    `json:"timeline"`
+	DryPools    []SimulateDryPool  `json:"dry_pools,omitempty"`
+	Pools       []SimulatePoolStat `json:"pools,omitempty"`
+}
+
+type SimulateEvent struct {
+	At        time.Time `json:"at"`
+	Event     string    `json:"event"` // rotate, subpool_activate, subpool_cycle, pool_reuse, no_number
+	PoolID    int       `json:"pool_id"`
+	PoolName  string    `json:"pool_name,omitempty"`
+	SubPoolID int       `json:"subpool_id,omitempty"`
+	NumberID  int       `json:"number_id,omitempty"`
+	Number    string    `json:"number,omitempty"`
+}
+
+type SimulateDryPool struct {
+	PoolID   int       `json:"pool_id"`
+	PoolName string    `json:"pool_name,omitempty"`
+	At       time.Time `json:"at"`
+	Reason   string    `json:"reason"`
+}
+
+type SimulatePoolStat struct {
+	PoolID        int    `json:"pool_id"`
+	PoolName      string `json:"pool_name,omitempty"`
+	NumbersUsed   int    `json:"numbers_used"`
+	NumbersUnused int    `json:"numbers_unused"`
+}
+
+type SwaggerSimulateResponse struct {
+	Status string           `json:"status"`
+	Data   SimulateResponse `json:"data"`
+}
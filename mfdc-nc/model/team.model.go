@@ -15,6 +15,7 @@ type Teams struct {
 	Name               *string          `db:"name" json:"name,omitempty"`
 	WebitelResourceIDS *json.RawMessage `db:"webitel_res_ids" json:"webitel_res_ids,omitempty"`
 	ActualVendorID     *int             `db:"actual_vendor_id" json:"actual_vendor_id"`
+	Backend            *string          `db:"backend" json:"backend,omitempty"`
 }
 
 type SwaggerTeams struct {
@@ -22,6 +23,7 @@ type SwaggerTeams struct {
 	Name               string   `json:"name"`
 	WebitelResourceIDS Resource `json:"webitel_res_ids"`
 	ActualVendorID     int      `json:"actual_vendor_id"`
+	Backend            string   `json:"backend" example:"webitel"` // webitel, db, file, memory (только при api.debug_mode)
 }
 
 type Resource struct {
@@ -99,3 +101,62 @@ type TeamsDayRequest struct {
 	From_date *string `json:"from_date,omitempty"`
 	To_date   *string `json:"to_date,omitempty"`
 }
+
+// Состояние воркера ротации команды в nc.team_workers
+type TeamWorker struct {
+	TeamID       int        `db:"team_id" json:"team_id"`
+	State        string     `db:"state" json:"state"` // waiting, running, stopped
+	ScheduleID   *int       `db:"schedule_id" json:"schedule_id,omitempty"`
+	PeriodicSec  *int       `db:"periodic_sec" json:"periodic_sec,omitempty"`
+	Instance     *string    `db:"instance" json:"instance,omitempty"` // Экземпляр nc-api, на котором работает воркер
+	StartedAt    *time.Time `db:"started_at" json:"started_at,omitempty"`
+	LastRunAt    *time.Time `db:"last_run_at" json:"last_run_at,omitempty"`
+	LastDuration *float64   `db:"last_duration_sec" json:"last_duration_sec,omitempty"`
+	LastError    *string    `db:"last_error" json:"last_error,omitempty"`
+	NextRunAt    *time.Time `db:"next_run_at" json:"next_run_at,omitempty"`
+	Runs         int        `db:"runs" json:"runs"`
+	Restarts     int        `db:"restarts" json:"restarts"` // Перезапуски воркера после изменения расписания
+	Panics       int        `db:"panics" json:"panics"`     // Шаги ротации, завершившиеся паникой, с запуска воркера
+	UpdatedAt    *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type SwaggerTeamWorker struct {
+	Status string     `json:"status"`
+	Data   TeamWorker `json:"data"`
+}
+
+// Пауза ротации и закреплённый номер команды, пустое until - до ручного снятия
+type TeamControl struct {
+	TeamID         int        `db:"team_id" json:"team_id"`
+	PausedAt       *time.Time `db:"paused_at" json:"paused_at,omitempty"`
+	PausedUntil    *time.Time `db:"paused_until" json:"paused_until,omitempty"`
+	PausedBy       *string    `db:"paused_by" json:"paused_by,omitempty"`
+	PauseReason    *string    `db:"pause_reason" json:"pause_reason,omitempty"`
+	PinnedNumberID *int       `db:"pinned_number_id" json:"pinned_number_id,omitempty"`
+	PinnedNumber   *string    `db:"pinned_number" json:"pinned_number,omitempty"`
+	PinnedAt       *time.Time `db:"pinned_at" json:"pinned_at,omitempty"`
+	PinnedUntil    *time.Time `db:"pinned_until" json:"pinned_until,omitempty"`
+	PinnedBy       *string    `db:"pinned_by" json:"pinned_by,omitempty"`
+	PinReason      *string    `db:"pin_reason" json:"pin_reason,omitempty"`
+}
+
+type TeamPauseRequest struct {
+	Until  *time.Time `json:"until,omitempty" example:"2026-10-20T09:00:00+03:00"` // Пусто - до resume
+	Reason string     `json:"reason" example:"campaign"`
+}
+
+type TeamPinRequest struct {
+	NumberID *int       `json:"number_id,omitempty"`
+	Number   *string    `json:"number,omitempty"`                                    // Номер вместо number_id
+	Until    *time.Time `json:"until,omitempty" example:"2026-10-20T09:00:00+03:00"` // Пусто - до unpin
+	Reason   string     `json:"reason" example:"campaign"`
+}
+
+type TeamResumeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type SwaggerTeamControl struct {
+	Status string      `json:"status"`
+	Data   TeamControl `json:"data"`
+}
@@ -1,15 +1,51 @@
 package model
 
+import "time"
+
 type Vendor struct {
-	ID   *int    `db:"id" json:"id,omitempty"`
-	Name *string `db:"name" json:"name"`
+	ID             *int    `db:"id" json:"id,omitempty"`
+	Name           *string `db:"name" json:"name"`
+	QuarantineDays *int    `db:"quarantine_days" json:"quarantine_days,omitempty"` // Длительность карантина номеров вендора в днях
 }
 
 type VendorSimple struct {
-	Name *string `json:"name"`
+	Name           *string `json:"name"`
+	QuarantineDays *int    `json:"quarantine_days"`
 }
 
 type SwaggerVendorList struct {
 	Status string   `json:"status"`
 	Data   []Vendor `json:"data"`
 }
+
+// Переключение команды на другого вендора от VC
+type VendorFailoverRequest struct {
+	TeamID   *int    `json:"team_id,omitempty"`
+	Team     *string `json:"team,omitempty" example:"Sales"` // Имя команды как в VC, если не передан team_id
+	VendorID *int    `json:"vendor_id,omitempty"`
+	Vendor   *string `json:"vendor,omitempty" example:"Vendor"` // Имя вендора как в VC, если не передан vendor_id
+}
+
+// Запись о переключении вендора в nc.vendor_failovers
+type VendorFailover struct {
+	ID           int       `db:"id" json:"id"`
+	TeamID       int       `db:"team_id" json:"team_id"`
+	FromVendorID *int      `db:"from_vendor_id" json:"from_vendor_id,omitempty"`
+	ToVendorID   int       `db:"to_vendor_id" json:"to_vendor_id"`
+	PoolID       *int      `db:"pool_id" json:"pool_id,omitempty"` // Пул нового вендора, который был активирован
+	Number       *string   `db:"number" json:"number,omitempty"`   // Номер, отправленный в ресурсы нового вендора
+	Source       string    `db:"source" json:"source"`             // webhook или sync
+	Error        *string   `db:"error" json:"error,omitempty"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+type SwaggerVendorFailover struct {
+	Status  string         `json:"status"`
+	Message string         `json:"message"`
+	Data    VendorFailover `json:"data"`
+}
+
+type SwaggerVendorFailoverList struct {
+	Status string           `json:"status"`
+	Data   []VendorFailover `json:"data"`
+}
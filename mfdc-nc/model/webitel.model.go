@@ -1,8 +1,38 @@
 package model
 
+import "time"
+
 type WebitelError struct {
 	ID     *string `json:"id,omitempty"`
 	Code   *int    `json:"code,omitempty"`
 	Detail *string `json:"detail,omitempty"`
 	Status *string `json:"status,omitempty"`
 }
+
+// Номер, установленный в ресурсе Webitel
+type WebitelDisplay struct {
+	Display string `json:"display"`
+}
+
+type WebitelDisplayList struct {
+	Items []WebitelDisplay `json:"items"`
+	Next  bool             `json:"next"`
+}
+
+// Расхождение между активным номером в nc.numbers и номером в ресурсе Webitel
+type ResourceDrift struct {
+	TeamID     int       `json:"team_id"`
+	VendorID   int       `json:"vendor_id"`
+	ResourceID int       `json:"resource_id"`
+	Kind       string    `json:"kind"` // missing - в ресурсе нет номера, mismatch - другой номер, extra - лишние номера, error - ресурс не прочитан
+	Expected   string    `json:"expected"`
+	Actual     []string  `json:"actual"`
+	Error      string    `json:"error,omitempty"`
+	Repushed   bool      `json:"repushed"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+type SwaggerResourceDrift struct {
+	Status string          `json:"status"`
+	Data   []ResourceDrift `json:"data"`
+}